@@ -2,6 +2,8 @@
 package v1alpha1
 
 import (
+	"sort"
+	"strconv"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -10,7 +12,8 @@ import (
 // BaoPolicySpec defines the desired state of BaoPolicy
 type BaoPolicySpec struct {
 	// PolicyName is the name of the policy in OpenBao
-	// If not specified, the BaoPolicy name will be used
+	// If not specified, the BaoPolicy name will be used.
+	// For a BaoPolicy the name is prefixed with the namespace: <namespace>_<policyName>
 	// +optional
 	PolicyName string `json:"policyName,omitempty"`
 
@@ -22,11 +25,25 @@ type BaoPolicySpec struct {
 	// OpenBaoRef references the OpenBao connection to use
 	// +optional
 	OpenBaoRef *OpenBaoReference `json:"openbaoRef,omitempty"`
+
+	// RefreshInterval is the interval at which the policy in OpenBao is compared with the rules;
+	// a policy modified outside kubebao is restored
+	// +kubebuilder:default="5m"
+	// +optional
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+
+	// DeletionPolicy defines what happens to the OpenBao policy when the BaoPolicy is deleted.
+	// A policy not created by this BaoPolicy is never deleted
+	// +kubebuilder:default=Delete
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
 }
 
 // PolicyRule defines a single policy rule
 type PolicyRule struct {
-	// Path is the path pattern for this rule (supports wildcards)
+	// Path is the path pattern for this rule: "*" is supported at the end, "+" as a whole segment
+	// and {{identity.entity.*}} / {{identity.groups.*}} templates anywhere
 	// +kubebuilder:validation:Required
 	Path string `json:"path"`
 
@@ -39,10 +56,15 @@ type PolicyRule struct {
 	// +optional
 	AllowedParameters map[string][]string `json:"allowedParameters,omitempty"`
 
-	// DeniedParameters specifies keys that cannot be set
+	// DeniedParameters specifies keys that cannot be set to any value
 	// +optional
 	DeniedParameters []string `json:"deniedParameters,omitempty"`
 
+	// DeniedParameterValues specifies values that cannot be set per key; an empty list denies
+	// every value. A key listed in deniedParameters denies every value regardless of this field
+	// +optional
+	DeniedParameterValues map[string][]string `json:"deniedParameterValues,omitempty"`
+
 	// RequiredParameters specifies keys that must be set
 	// +optional
 	RequiredParameters []string `json:"requiredParameters,omitempty"`
@@ -54,10 +76,52 @@ type PolicyRule struct {
 	// MaxWrappingTTL specifies maximum wrapping TTL
 	// +optional
 	MaxWrappingTTL string `json:"maxWrappingTTL,omitempty"`
+
+	// SubscribeEventTypes are the event types (globs allowed) a token may receive for this path
+	// when subscribed through sys/events/subscribe
+	// +optional
+	SubscribeEventTypes []string `json:"subscribeEventTypes,omitempty"`
+
+	// ControlGroup requires approvals before a request to the path is allowed.
+	// Only enforced by servers that support control groups
+	// +optional
+	ControlGroup *ControlGroup `json:"controlGroup,omitempty"`
+}
+
+// ControlGroup defines the approvals required for a request
+type ControlGroup struct {
+	// TTL is how long a request waits for approval
+	// +optional
+	TTL string `json:"ttl,omitempty"`
+
+	// Factors are the approvals required; every factor must be satisfied
+	// +kubebuilder:validation:MinItems=1
+	Factors []ControlGroupFactor `json:"factors"`
+}
+
+// ControlGroupFactor defines one set of approvers of a control group
+type ControlGroupFactor struct {
+	// Name identifies the factor
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// GroupNames are the identity groups whose members may approve
+	// +kubebuilder:validation:MinItems=1
+	GroupNames []string `json:"groupNames"`
+
+	// Approvals is the number of approvals required
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Approvals int32 `json:"approvals,omitempty"`
+
+	// ControlledCapabilities limits the factor to these capabilities; all capabilities if not specified
+	// +optional
+	ControlledCapabilities []Capability `json:"controlledCapabilities,omitempty"`
 }
 
 // Capability represents an operation capability
-// +kubebuilder:validation:Enum=create;read;update;delete;list;sudo;deny;patch
+// +kubebuilder:validation:Enum=create;read;update;delete;list;sudo;deny;patch;subscribe
 type Capability string
 
 const (
@@ -69,6 +133,8 @@ const (
 	CapabilitySudo   Capability = "sudo"
 	CapabilityDeny   Capability = "deny"
 	CapabilityPatch  Capability = "patch"
+	// CapabilitySubscribe allows subscribing to events on sys/events/subscribe/<eventType>
+	CapabilitySubscribe Capability = "subscribe"
 )
 
 // BaoPolicyStatus defines the observed state of BaoPolicy
@@ -92,6 +158,10 @@ type BaoPolicyStatus struct {
 	// ObservedGeneration is the last observed generation
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConsecutiveFailures is the number of failed syncs since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -124,78 +194,104 @@ func init() {
 }
 
 // ToHCL converts the policy rules to HCL format for OpenBao.
-// All string values are escaped to prevent HCL injection.
 func (p *BaoPolicy) ToHCL() string {
+	return p.Spec.ToHCL()
+}
+
+// ToHCL converts the policy rules to HCL format for OpenBao.
+// All string values are escaped to prevent HCL injection; map keys are sorted so the same
+// rules always produce the same HCL.
+func (s *BaoPolicySpec) ToHCL() string {
 	var b strings.Builder
 
-	for _, rule := range p.Spec.Rules {
-		b.WriteString("path \"")
-		b.WriteString(escapeHCLString(rule.Path))
-		b.WriteString("\" {\n")
-		b.WriteString("  capabilities = [")
+	for _, rule := range s.Rules {
+		b.WriteString("path ")
+		b.WriteString(quoteHCLString(rule.Path))
+		b.WriteString(" {\n")
 
-		for i, cap := range rule.Capabilities {
-			if i > 0 {
-				b.WriteString(", ")
-			}
-			b.WriteString("\"")
-			b.WriteString(escapeHCLString(string(cap)))
-			b.WriteString("\"")
+		capabilities := make([]string, 0, len(rule.Capabilities))
+		for _, c := range rule.Capabilities {
+			capabilities = append(capabilities, string(c))
 		}
-		b.WriteString("]\n")
+		b.WriteString("  capabilities = ")
+		b.WriteString(hclList(capabilities))
+		b.WriteString("\n")
 
 		if len(rule.AllowedParameters) > 0 {
-			b.WriteString("  allowed_parameters = {\n")
-			for key, values := range rule.AllowedParameters {
-				b.WriteString("    \"")
-				b.WriteString(escapeHCLString(key))
-				b.WriteString("\" = [")
-				for i, v := range values {
-					if i > 0 {
-						b.WriteString(", ")
-					}
-					b.WriteString("\"")
-					b.WriteString(escapeHCLString(v))
-					b.WriteString("\"")
-				}
-				b.WriteString("]\n")
-			}
-			b.WriteString("  }\n")
+			writeHCLParameters(&b, "allowed_parameters", rule.AllowedParameters)
 		}
 
-		if len(rule.DeniedParameters) > 0 {
-			b.WriteString("  denied_parameters = {\n")
+		// deniedParameters запрещает любое значение ключа и перекрывает deniedParameterValues
+		if len(rule.DeniedParameters) > 0 || len(rule.DeniedParameterValues) > 0 {
+			denied := make(map[string][]string, len(rule.DeniedParameters)+len(rule.DeniedParameterValues))
+			for key, values := range rule.DeniedParameterValues {
+				denied[key] = values
+			}
 			for _, key := range rule.DeniedParameters {
-				b.WriteString("    \"")
-				b.WriteString(escapeHCLString(key))
-				b.WriteString("\" = []\n")
+				denied[key] = nil
 			}
-			b.WriteString("  }\n")
+			writeHCLParameters(&b, "denied_parameters", denied)
 		}
 
 		if len(rule.RequiredParameters) > 0 {
-			b.WriteString("  required_parameters = [")
-			for i, key := range rule.RequiredParameters {
-				if i > 0 {
-					b.WriteString(", ")
-				}
-				b.WriteString("\"")
-				b.WriteString(escapeHCLString(key))
-				b.WriteString("\"")
-			}
-			b.WriteString("]\n")
+			b.WriteString("  required_parameters = ")
+			b.WriteString(hclList(rule.RequiredParameters))
+			b.WriteString("\n")
 		}
 
 		if rule.MinWrappingTTL != "" {
-			b.WriteString("  min_wrapping_ttl = \"")
-			b.WriteString(escapeHCLString(rule.MinWrappingTTL))
-			b.WriteString("\"\n")
+			b.WriteString("  min_wrapping_ttl = ")
+			b.WriteString(quoteHCLString(rule.MinWrappingTTL))
+			b.WriteString("\n")
 		}
 
 		if rule.MaxWrappingTTL != "" {
-			b.WriteString("  max_wrapping_ttl = \"")
-			b.WriteString(escapeHCLString(rule.MaxWrappingTTL))
-			b.WriteString("\"\n")
+			b.WriteString("  max_wrapping_ttl = ")
+			b.WriteString(quoteHCLString(rule.MaxWrappingTTL))
+			b.WriteString("\n")
+		}
+
+		if len(rule.SubscribeEventTypes) > 0 {
+			b.WriteString("  subscribe_event_types = ")
+			b.WriteString(hclList(rule.SubscribeEventTypes))
+			b.WriteString("\n")
+		}
+
+		if cg := rule.ControlGroup; cg != nil {
+			b.WriteString("  control_group = {\n")
+			if cg.TTL != "" {
+				b.WriteString("    ttl = ")
+				b.WriteString(quoteHCLString(cg.TTL))
+				b.WriteString("\n")
+			}
+			for _, factor := range cg.Factors {
+				b.WriteString("    factor ")
+				b.WriteString(quoteHCLString(factor.Name))
+				b.WriteString(" {\n")
+				b.WriteString("      identity {\n")
+				b.WriteString("        group_names = ")
+				b.WriteString(hclList(factor.GroupNames))
+				b.WriteString("\n")
+				approvals := factor.Approvals
+				if approvals < 1 {
+					approvals = 1
+				}
+				b.WriteString("        approvals = ")
+				b.WriteString(strconv.Itoa(int(approvals)))
+				b.WriteString("\n")
+				b.WriteString("      }\n")
+				if len(factor.ControlledCapabilities) > 0 {
+					controlled := make([]string, 0, len(factor.ControlledCapabilities))
+					for _, c := range factor.ControlledCapabilities {
+						controlled = append(controlled, string(c))
+					}
+					b.WriteString("      controlled_capabilities = ")
+					b.WriteString(hclList(controlled))
+					b.WriteString("\n")
+				}
+				b.WriteString("    }\n")
+			}
+			b.WriteString("  }\n")
 		}
 
 		b.WriteString("}\n\n")
@@ -204,6 +300,41 @@ func (p *BaoPolicy) ToHCL() string {
 	return b.String()
 }
 
+// writeHCLParameters пишет allowed_parameters или denied_parameters с ключами по алфавиту.
+func writeHCLParameters(b *strings.Builder, name string, parameters map[string][]string) {
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	b.WriteString("  ")
+	b.WriteString(name)
+	b.WriteString(" = {\n")
+	for _, key := range keys {
+		b.WriteString("    ")
+		b.WriteString(quoteHCLString(key))
+		b.WriteString(" = ")
+		b.WriteString(hclList(parameters[key]))
+		b.WriteString("\n")
+	}
+	b.WriteString("  }\n")
+}
+
+// hclList — список HCL-строк: ["a", "b"].
+func hclList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, quoteHCLString(v))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// quoteHCLString — экранированная строка HCL в кавычках.
+func quoteHCLString(s string) string {
+	return "\"" + escapeHCLString(s) + "\""
+}
+
 // escapeHCLString экранирует спецсимволы для безопасной вставки в HCL-строку.
 func escapeHCLString(s string) string {
 	s = strings.ReplaceAll(s, "\\", "\\\\")
@@ -214,10 +345,34 @@ func escapeHCLString(s string) string {
 	return s
 }
 
-// GetPolicyName returns the policy name to use in OpenBao
+// PolicyOwnerPrefix starts the comment line that marks the BaoPolicy owning an OpenBao policy
+const PolicyOwnerPrefix = "# kubebao.io/owner: "
+
+// NamespaceSeparator separates the namespace prefix from the name of a BaoPolicy policy or a
+// BaoRole role in OpenBao. Namespace names cannot contain it, so "a-b" + "c" and "a" + "b-c"
+// never produce the same name.
+const NamespaceSeparator = "_"
+
+// GetPolicyName returns the policy name to use in OpenBao: the unscoped name prefixed with
+// the namespace, so BaoPolicies in different namespaces never share a policy
 func (p *BaoPolicy) GetPolicyName() string {
+	return p.Namespace + NamespaceSeparator + p.GetUnscopedPolicyName()
+}
+
+// GetUnscopedPolicyName returns spec.policyName or the BaoPolicy name without the namespace prefix
+func (p *BaoPolicy) GetUnscopedPolicyName() string {
 	if p.Spec.PolicyName != "" {
 		return p.Spec.PolicyName
 	}
 	return p.Name
 }
+
+// GetPolicySpec returns the policy spec shared with ClusterBaoPolicy
+func (p *BaoPolicy) GetPolicySpec() *BaoPolicySpec {
+	return &p.Spec
+}
+
+// GetPolicyStatus returns the policy status shared with ClusterBaoPolicy
+func (p *BaoPolicy) GetPolicyStatus() *BaoPolicyStatus {
+	return &p.Status
+}
@@ -0,0 +1,58 @@
+// API types для BaoPolicyConstraint — ограничений правил BaoPolicy по namespace.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespacePlaceholder in AllowedPaths is replaced with the namespace of the BaoPolicy
+const NamespacePlaceholder = "{{namespace}}"
+
+// BaoPolicyConstraintSpec defines the rules a BaoPolicy in the selected namespaces may grant.
+// A BaoPolicy must satisfy every constraint selecting its namespace
+type BaoPolicyConstraintSpec struct {
+	// NamespaceSelector selects the namespaces the constraint applies to.
+	// If not specified, the constraint applies to all namespaces
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// AllowedPaths are the path patterns a rule path must fall under, e.g. "secret/data/{{namespace}}/*".
+	// A pattern ending with "*" allows every path with that prefix, any other pattern allows the exact path.
+	// If not specified, any path is allowed
+	// +optional
+	AllowedPaths []string `json:"allowedPaths,omitempty"`
+
+	// ForbiddenCapabilities are the capabilities a rule must not grant
+	// +optional
+	ForbiddenCapabilities []Capability `json:"forbiddenCapabilities,omitempty"`
+
+	// MaxWrappingTTL is the upper bound for minWrappingTTL and maxWrappingTTL of every rule;
+	// rules must set maxWrappingTTL when it is specified
+	// +optional
+	MaxWrappingTTL string `json:"maxWrappingTTL,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BaoPolicyConstraint is the Schema for the baopolicyconstraints API
+type BaoPolicyConstraint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BaoPolicyConstraintSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BaoPolicyConstraintList contains a list of BaoPolicyConstraint
+type BaoPolicyConstraintList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BaoPolicyConstraint `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BaoPolicyConstraint{}, &BaoPolicyConstraintList{})
+}
@@ -0,0 +1,172 @@
+// API types для BaoRole — CRD ролей методов аутентификации OpenBao (kubernetes, jwt).
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Auth methods of BaoRole
+const (
+	// AuthMethodKubernetes binds ServiceAccounts through the kubernetes auth method
+	AuthMethodKubernetes = "kubernetes"
+	// AuthMethodJWT binds JWT subjects and audiences through the jwt auth method
+	AuthMethodJWT = "jwt"
+)
+
+// BaoRoleSpec defines the desired state of BaoRole
+type BaoRoleSpec struct {
+	// RoleName is the name of the role in OpenBao, prefixed with the namespace: <namespace>_<roleName>.
+	// If not specified, the BaoRole name will be used
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+
+	// AuthMethod is the auth method the role belongs to
+	// +kubebuilder:default=kubernetes
+	// +kubebuilder:validation:Enum=kubernetes;jwt
+	// +optional
+	AuthMethod string `json:"authMethod,omitempty"`
+
+	// AuthMountPath is the mount path of the auth method.
+	// If not specified, the auth method name is used
+	// +optional
+	AuthMountPath string `json:"authMountPath,omitempty"`
+
+	// Policies are the names of BaoPolicy objects in the BaoRole namespace
+	// whose OpenBao policies are attached to tokens issued by the role
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Policies []string `json:"policies"`
+
+	// BoundServiceAccountNames are the ServiceAccounts allowed to log in (kubernetes)
+	// +optional
+	BoundServiceAccountNames []string `json:"boundServiceAccountNames,omitempty"`
+
+	// BoundServiceAccountNamespaces are the namespaces of the bound ServiceAccounts (kubernetes).
+	// If not specified, the BaoRole namespace is used
+	// +optional
+	BoundServiceAccountNamespaces []string `json:"boundServiceAccountNamespaces,omitempty"`
+
+	// Audience is the audience the ServiceAccount token must have (kubernetes)
+	// +optional
+	Audience string `json:"audience,omitempty"`
+
+	// BoundAudiences are the audiences the JWT must have (jwt)
+	// +optional
+	BoundAudiences []string `json:"boundAudiences,omitempty"`
+
+	// BoundSubject is the subject the JWT must have (jwt)
+	// +optional
+	BoundSubject string `json:"boundSubject,omitempty"`
+
+	// UserClaim is the JWT claim used as the entity alias (jwt)
+	// +kubebuilder:default=sub
+	// +optional
+	UserClaim string `json:"userClaim,omitempty"`
+
+	// TokenTTL is the TTL of issued tokens, e.g. "1h"
+	// +optional
+	TokenTTL string `json:"tokenTTL,omitempty"`
+
+	// TokenMaxTTL is the maximum TTL of issued tokens, e.g. "24h"
+	// +optional
+	TokenMaxTTL string `json:"tokenMaxTTL,omitempty"`
+
+	// RefreshInterval is the interval at which the role in OpenBao is compared with the spec;
+	// a role modified outside kubebao is restored
+	// +kubebuilder:default="5m"
+	// +optional
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+
+	// DeletionPolicy defines what happens to the OpenBao role when the BaoRole is deleted.
+	// A role not created by this BaoRole is never deleted
+	// +kubebuilder:default=Delete
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+}
+
+// BaoRoleStatus defines the observed state of BaoRole
+type BaoRoleStatus struct {
+	// Conditions represent the latest available observations of the BaoRole's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastSyncTime is the last time the role was synced to OpenBao
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// RoleVersion is a hash of the role parameters
+	// +optional
+	RoleVersion string `json:"roleVersion,omitempty"`
+
+	// AppliedRoleName is the name of the role as it appears in OpenBao
+	// +optional
+	AppliedRoleName string `json:"appliedRoleName,omitempty"`
+
+	// AppliedAuthMountPath is the auth mount path the role was written to
+	// +optional
+	AppliedAuthMountPath string `json:"appliedAuthMountPath,omitempty"`
+
+	// Policies are the OpenBao policy names attached to the role
+	// +optional
+	Policies []string `json:"policies,omitempty"`
+
+	// ObservedGeneration is the last observed generation
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConsecutiveFailures is the number of failed syncs since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Role Name",type=string,JSONPath=`.status.appliedRoleName`
+// +kubebuilder:printcolumn:name="Auth",type=string,JSONPath=`.spec.authMethod`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BaoRole is the Schema for the baoroles API
+type BaoRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BaoRoleSpec   `json:"spec,omitempty"`
+	Status BaoRoleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BaoRoleList contains a list of BaoRole
+type BaoRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BaoRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BaoRole{}, &BaoRoleList{})
+}
+
+// GetRoleName returns the role name to use in OpenBao: spec.roleName or the BaoRole name
+// prefixed with the namespace
+func (r *BaoRole) GetRoleName() string {
+	name := r.Spec.RoleName
+	if name == "" {
+		name = r.Name
+	}
+	return r.Namespace + NamespaceSeparator + name
+}
+
+// GetAuthMountPath returns the auth mount path: spec.authMountPath or the auth method name
+func (r *BaoRole) GetAuthMountPath() string {
+	if r.Spec.AuthMountPath != "" {
+		return r.Spec.AuthMountPath
+	}
+	if r.Spec.AuthMethod != "" {
+		return r.Spec.AuthMethod
+	}
+	return AuthMethodKubernetes
+}
@@ -7,9 +7,10 @@ import (
 
 // BaoSecretSpec defines the desired state of BaoSecret
 type BaoSecretSpec struct {
-	// SecretPath is the path in OpenBao where the secret is stored
-	// +kubebuilder:validation:Required
-	SecretPath string `json:"secretPath"`
+	// SecretPath is the path in OpenBao where the secret is stored.
+	// Either SecretPath or DataFrom must be set
+	// +optional
+	SecretPath string `json:"secretPath,omitempty"`
 
 	// SecretKey is the specific key to extract from the secret (optional)
 	// If not specified, all keys will be synced
@@ -49,6 +50,90 @@ type BaoSecretSpec struct {
 	// SuspendSync suspends the synchronization of the secret
 	// +optional
 	SuspendSync bool `json:"suspendSync,omitempty"`
+
+	// DataFrom lists additional KV v2 paths merged into the target Secret after SecretPath,
+	// in order. Not supported together with a dynamic SecretEngine or Wrapping
+	// +optional
+	DataFrom []SecretSource `json:"dataFrom,omitempty"`
+
+	// ConflictPolicy defines what happens when several sources produce the same key
+	// +kubebuilder:default=Error
+	// +kubebuilder:validation:Enum=Error;Overwrite;KeepFirst
+	// +optional
+	ConflictPolicy string `json:"conflictPolicy,omitempty"`
+
+	// Wrapping delivers a single-use response-wrapping token instead of the secret data.
+	// The workload unwraps the token itself, so the secret never reaches etcd.
+	// SecretKey and Template are ignored when wrapping is enabled.
+	// +optional
+	Wrapping *SecretWrapping `json:"wrapping,omitempty"`
+
+	// Rollout restarts workloads consuming the target Secret when its data changes
+	// by patching the kubebao.io/version annotation of their pod template
+	// +optional
+	Rollout *SecretRollout `json:"rollout,omitempty"`
+}
+
+// SecretRollout selects workloads restarted after the target Secret changes.
+// Workloads are looked up in the namespace of the target Secret
+type SecretRollout struct {
+	// Targets lists workloads by kind and name
+	// +optional
+	Targets []RolloutTarget `json:"targets,omitempty"`
+
+	// Selector discovers Deployments, StatefulSets and DaemonSets by label
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// MinInterval is the minimum time between two rollouts of this BaoSecret;
+	// changes within the interval are rolled out together when it elapses
+	// +kubebuilder:default="1m"
+	// +optional
+	MinInterval string `json:"minInterval,omitempty"`
+}
+
+// RolloutTarget references a workload restarted after the target Secret changes
+type RolloutTarget struct {
+	// Kind is the workload kind
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+
+	// Name is the workload name
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// SecretSource is one KV v2 path merged into the target Secret
+type SecretSource struct {
+	// Path is the path in OpenBao KV v2 (without the "data/" prefix)
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// Keys selects keys from the source; all keys are used if empty
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+
+	// Rename maps source keys to target keys
+	// +optional
+	Rename map[string]string `json:"rename,omitempty"`
+
+	// Prefix is prepended to every target key of this source (after Rename)
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// SecretWrapping configures secret-zero delivery through OpenBao response wrapping
+type SecretWrapping struct {
+	// TTL is the lifetime of the wrapping token; an unused token is reissued after it expires
+	// +kubebuilder:default="5m"
+	// +optional
+	TTL string `json:"ttl,omitempty"`
+
+	// TokenKey is the key in the target Secret that holds the wrapping token
+	// +kubebuilder:default=token
+	// +optional
+	TokenKey string `json:"tokenKey,omitempty"`
 }
 
 // SecretTarget defines where to sync the secret
@@ -75,9 +160,12 @@ type SecretTarget struct {
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
 
-	// CreationPolicy defines what to do when the target doesn't exist
+	// CreationPolicy defines how the target Secret is created and owned:
+	// Owner creates it with an owner reference, Orphan creates it without one,
+	// Merge only writes the synced keys into an existing Secret, None does not write it at all.
+	// Owner and Orphan refuse to take over an existing Secret not managed by kubebao
 	// +kubebuilder:default=Owner
-	// +kubebuilder:validation:Enum=Owner;Orphan;Merge
+	// +kubebuilder:validation:Enum=Owner;Orphan;Merge;None
 	// +optional
 	CreationPolicy string `json:"creationPolicy,omitempty"`
 }
@@ -120,7 +208,10 @@ type ServiceAccountReference struct {
 type SecretTemplate struct {
 	// Data is a map of template strings
 	// Keys are the target secret data keys
-	// Values are Go templates that can reference source data with {{ .Data.key }}
+	// Values are Go text/templates that can reference source data with {{ .Data.key }}
+	// and metadata with {{ .Metadata.version }}. Available functions: b64enc, b64dec,
+	// toJson, fromJson, toYaml, fromYaml, pkcs12, pkcs12Pass, pemCertificates, default,
+	// upper, lower
 	// +optional
 	Data map[string]string `json:"data,omitempty"`
 
@@ -154,13 +245,88 @@ type BaoSecretStatus struct {
 	// ObservedGeneration is the last observed generation
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// WrappingTokenExpiry is the expiry time of the delivered wrapping token
+	// +optional
+	WrappingTokenExpiry *metav1.Time `json:"wrappingTokenExpiry,omitempty"`
+
+	// LeaseID is the lease of the current dynamic credentials
+	// +optional
+	LeaseID string `json:"leaseID,omitempty"`
+
+	// LeaseDuration is the TTL the current dynamic credentials were issued with
+	// +optional
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+
+	// LeaseExpiryTime is the time the current dynamic credentials expire
+	// +optional
+	LeaseExpiryTime *metav1.Time `json:"leaseExpiryTime,omitempty"`
+
+	// RolloutVersion is the SecretVersion the rollout workloads were last restarted with
+	// +optional
+	RolloutVersion string `json:"rolloutVersion,omitempty"`
+
+	// LastRolloutTime is the last time workloads were restarted
+	// +optional
+	LastRolloutTime *metav1.Time `json:"lastRolloutTime,omitempty"`
+
+	// RestartedWorkloads lists the workloads restarted by the last rollout as Kind/name
+	// +optional
+	RestartedWorkloads []string `json:"restartedWorkloads,omitempty"`
+
+	// SourceVersion is the OpenBao KV metadata version of the synced data; with several
+	// sources the versions of SecretPath and DataFrom are listed in order, comma-separated
+	// +optional
+	SourceVersion string `json:"sourceVersion,omitempty"`
+
+	// SourceCreatedTime is the time the synced version of the first KV source was written
+	// +optional
+	SourceCreatedTime *metav1.Time `json:"sourceCreatedTime,omitempty"`
+
+	// ConsecutiveFailures is the number of failed syncs since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// LastError is the error of the last failed sync; cleared after a successful sync
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// SyncHistory lists the most recent sync outcomes, newest first.
+	// Repeated identical outcomes update the time of the newest entry
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	SyncHistory []SyncHistoryEntry `json:"syncHistory,omitempty"`
+}
+
+// SyncHistoryEntry is one sync outcome of a BaoSecret
+type SyncHistoryEntry struct {
+	// Time is the last time the sync ended with this outcome
+	Time metav1.Time `json:"time"`
+
+	// Result is Success or Failed
+	Result string `json:"result"`
+
+	// SourceVersion is the OpenBao KV version that was synced
+	// +optional
+	SourceVersion string `json:"sourceVersion,omitempty"`
+
+	// SecretVersion is the hash of the target Secret data that was written
+	// +optional
+	SecretVersion string `json:"secretVersion,omitempty"`
+
+	// Message is the error of a failed sync
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="Secret Path",type=string,JSONPath=`.spec.secretPath`
-// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target.name`
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Source Version",type=string,JSONPath=`.status.sourceVersion`
 // +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Secret Path",type=string,JSONPath=`.spec.secretPath`,priority=1
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target.name`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // BaoSecret is the Schema for the baosecrets API
@@ -195,6 +361,31 @@ const (
 
 	// ConditionTypeAuthenticated indicates authentication to OpenBao was successful
 	ConditionTypeAuthenticated = "Authenticated"
+
+	// ConditionTypeDrifted indicates the target Secret was recently modified outside kubebao and restored
+	ConditionTypeDrifted = "Drifted"
+)
+
+// Creation policies for the target Secret
+const (
+	// CreationPolicyOwner creates the target with an owner reference to the BaoSecret
+	CreationPolicyOwner = "Owner"
+	// CreationPolicyOrphan creates the target without an owner reference
+	CreationPolicyOrphan = "Orphan"
+	// CreationPolicyMerge writes the synced keys into an existing target and keeps other keys
+	CreationPolicyMerge = "Merge"
+	// CreationPolicyNone reads and renders the secret without writing the target
+	CreationPolicyNone = "None"
+)
+
+// Conflict policies for keys produced by several sources
+const (
+	// ConflictPolicyError fails the sync on a duplicate key
+	ConflictPolicyError = "Error"
+	// ConflictPolicyOverwrite lets the later source win
+	ConflictPolicyOverwrite = "Overwrite"
+	// ConflictPolicyKeepFirst keeps the value from the earlier source
+	ConflictPolicyKeepFirst = "KeepFirst"
 )
 
 // Condition reasons
@@ -204,4 +395,25 @@ const (
 	ReasonAuthenticationFailed = "AuthenticationFailed"
 	ReasonSecretNotFound     = "SecretNotFound"
 	ReasonSyncSuspended      = "SyncSuspended"
+	ReasonTemplateError      = "TemplateError"
+	ReasonDriftRestored      = "DriftRestored"
+	ReasonInSync             = "InSync"
+	ReasonUnmanagedTarget    = "UnmanagedTarget"
+	ReasonPermissionDenied   = "PermissionDenied"
+	ReasonOpenBaoUnavailable = "OpenBaoUnavailable"
+	ReasonConstraintViolation = "ConstraintViolation"
+	ReasonInvalidSpec = "InvalidSpec"
+)
+
+// Sync history results
+const (
+	SyncResultSuccess = "Success"
+	SyncResultFailed  = "Failed"
+)
+
+// Workload kinds restarted by Rollout
+const (
+	RolloutKindDeployment  = "Deployment"
+	RolloutKindStatefulSet = "StatefulSet"
+	RolloutKindDaemonSet   = "DaemonSet"
 )
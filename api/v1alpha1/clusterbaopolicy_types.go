@@ -0,0 +1,60 @@
+// API types для ClusterBaoPolicy — кластерной политики OpenBao для платформенных команд.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Policy Name",type=string,JSONPath=`.status.appliedPolicyName`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterBaoPolicy is the Schema for the clusterbaopolicies API.
+// Unlike BaoPolicy, the policy name is not prefixed with a namespace
+type ClusterBaoPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BaoPolicySpec   `json:"spec,omitempty"`
+	Status BaoPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterBaoPolicyList contains a list of ClusterBaoPolicy
+type ClusterBaoPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterBaoPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterBaoPolicy{}, &ClusterBaoPolicyList{})
+}
+
+// ToHCL converts the policy rules to HCL format for OpenBao.
+func (p *ClusterBaoPolicy) ToHCL() string {
+	return p.Spec.ToHCL()
+}
+
+// GetPolicyName returns the policy name to use in OpenBao
+func (p *ClusterBaoPolicy) GetPolicyName() string {
+	if p.Spec.PolicyName != "" {
+		return p.Spec.PolicyName
+	}
+	return p.Name
+}
+
+// GetPolicySpec returns the policy spec shared with BaoPolicy
+func (p *ClusterBaoPolicy) GetPolicySpec() *BaoPolicySpec {
+	return &p.Spec
+}
+
+// GetPolicyStatus returns the policy status shared with BaoPolicy
+func (p *ClusterBaoPolicy) GetPolicyStatus() *BaoPolicyStatus {
+	return &p.Status
+}
@@ -0,0 +1,9 @@
+// v1alpha1 — hub-версия конвертации: хранится в etcd и используется контроллерами, остальные
+// версии конвертируются через неё.
+package v1alpha1
+
+// Hub помечает BaoSecret как hub-версию.
+func (*BaoSecret) Hub() {}
+
+// Hub помечает BaoPushSecret как hub-версию.
+func (*BaoPushSecret) Hub() {}
@@ -1,4 +1,4 @@
-// Пакет v1alpha1 — API-схемы kubebao v1alpha1 (BaoSecret, BaoPolicy)
+// Пакет v1alpha1 — API-схемы kubebao v1alpha1 (BaoSecret, BaoPushSecret, BaoPolicy)
 // +kubebuilder:object:generate=true
 // +groupName=kubebao.io
 package v1alpha1
@@ -0,0 +1,49 @@
+// Общие типы v1beta1: подключение к OpenBao и метод аутентификации ресурса.
+package v1beta1
+
+// OpenBaoAuth selects the OpenBao connection and the identity used for a resource.
+// Without it the operator's own connection and identity are used
+// +kubebuilder:validation:XValidation:rule="!(has(self.kubernetes) && has(self.jwt))",message="only one of kubernetes and jwt may be set"
+type OpenBaoAuth struct {
+	// Address is the address of the OpenBao server; the operator's address if not set
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Namespace is the OpenBao namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Kubernetes logs in with the kubernetes auth method using a ServiceAccount token.
+	// This is the default when neither kubernetes nor jwt is set
+	// +optional
+	Kubernetes *ServiceAccountAuth `json:"kubernetes,omitempty"`
+
+	// JWT logs in with the jwt auth method using a ServiceAccount token
+	// +optional
+	JWT *ServiceAccountAuth `json:"jwt,omitempty"`
+}
+
+// ServiceAccountAuth is a login with a ServiceAccount token of the resource namespace
+type ServiceAccountAuth struct {
+	// Role is the OpenBao role; the operator's role if not set
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// MountPath is the mount path of the auth method; kubernetes or jwt if not set
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+
+	// ServiceAccountRef references the ServiceAccount whose token is used; "default" if not set
+	// +optional
+	ServiceAccountRef *ServiceAccountReference `json:"serviceAccountRef,omitempty"`
+}
+
+// ServiceAccountReference references a ServiceAccount
+type ServiceAccountReference struct {
+	// Name is the name of the ServiceAccount
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ServiceAccount
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
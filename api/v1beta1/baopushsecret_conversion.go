@@ -0,0 +1,49 @@
+// Конвертация BaoPushSecret v1beta1 ⇄ v1alpha1 (hub, версия хранения).
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// ConvertTo converts this BaoPushSecret to the hub version (v1alpha1)
+func (src *BaoPushSecret) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.BaoPushSecret)
+	in := src.DeepCopy()
+	fields := readPreserved(in.ObjectMeta)
+
+	dst.ObjectMeta = objectMeta(in.ObjectMeta, nil)
+	dst.Spec = v1alpha1.BaoPushSecretSpec{
+		SecretRef:       v1alpha1.LocalSecretReference(in.Spec.SecretRef),
+		Path:            in.Spec.Path,
+		DeletionPolicy:  string(in.Spec.DeletionPolicy),
+		RefreshInterval: durationToString(in.Spec.RefreshInterval, "refreshInterval", fields),
+	}
+	dst.Spec.OpenBaoRef, dst.Spec.RoleName = authToV1alpha1(in.Spec.Auth, fields)
+	for _, k := range in.Spec.Data {
+		dst.Spec.Data = append(dst.Spec.Data, v1alpha1.PushSecretKey(k))
+	}
+	dst.Status = v1alpha1.BaoPushSecretStatus(in.Status)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version
+func (dst *BaoPushSecret) ConvertFrom(srcRaw conversion.Hub) error {
+	in := srcRaw.(*v1alpha1.BaoPushSecret).DeepCopy()
+	fields := preservedFields{}
+
+	dst.Spec = BaoPushSecretSpec{
+		SecretRef:       LocalSecretReference(in.Spec.SecretRef),
+		Path:            in.Spec.Path,
+		DeletionPolicy:  DeletionPolicy(in.Spec.DeletionPolicy),
+		RefreshInterval: durationFromString(in.Spec.RefreshInterval, "refreshInterval", fields),
+		Auth:            authFromV1alpha1(in.Spec.OpenBaoRef, in.Spec.RoleName, fields),
+	}
+	for _, k := range in.Spec.Data {
+		dst.Spec.Data = append(dst.Spec.Data, PushSecretKey(k))
+	}
+	dst.ObjectMeta = objectMeta(in.ObjectMeta, fields)
+	dst.Status = BaoPushSecretStatus(in.Status)
+	return nil
+}
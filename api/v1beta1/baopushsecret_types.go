@@ -0,0 +1,123 @@
+// API types для BaoPushSecret v1beta1 — CRD обратной синхронизации Kubernetes Secret → OpenBao KV.
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeletionPolicy defines what happens to the KV secret when the BaoPushSecret is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicy string
+
+// Deletion policies for pushed secrets
+const (
+	// DeletionPolicyRetain keeps the KV secret when the BaoPushSecret is deleted
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+	// DeletionPolicyDelete deletes the KV secret with all its versions
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+)
+
+// BaoPushSecretSpec defines the desired state of BaoPushSecret
+type BaoPushSecretSpec struct {
+	// SecretRef is the Kubernetes Secret to push, in the namespace of the BaoPushSecret
+	// +kubebuilder:validation:Required
+	SecretRef LocalSecretReference `json:"secretRef"`
+
+	// Path is the path in OpenBao KV v2 (without the "data/" prefix)
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// Data selects Secret keys and their names in OpenBao; all keys are pushed if empty
+	// +optional
+	Data []PushSecretKey `json:"data,omitempty"`
+
+	// DeletionPolicy defines what happens to the KV secret when the BaoPushSecret is deleted
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// RefreshInterval is the interval at which ownership and the remote version are rechecked
+	// +kubebuilder:default="1h"
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// Auth selects the OpenBao connection and identity; the operator's if not set
+	// +optional
+	Auth *OpenBaoAuth `json:"auth,omitempty"`
+}
+
+// LocalSecretReference references a Secret in the same namespace
+type LocalSecretReference struct {
+	// Name is the name of the Secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// PushSecretKey maps a Secret key to a key in OpenBao
+type PushSecretKey struct {
+	// SecretKey is the key in the Kubernetes Secret
+	// +kubebuilder:validation:Required
+	SecretKey string `json:"secretKey"`
+
+	// RemoteKey is the key in OpenBao; defaults to SecretKey
+	// +optional
+	RemoteKey string `json:"remoteKey,omitempty"`
+}
+
+// BaoPushSecretStatus defines the observed state of BaoPushSecret
+type BaoPushSecretStatus struct {
+	// Conditions represent the latest available observations of the BaoPushSecret's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastSyncTime is the last time the secret was pushed
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// SecretVersion is a hash of the pushed data
+	// +optional
+	SecretVersion string `json:"secretVersion,omitempty"`
+
+	// RemoteVersion is the KV version written by the last push
+	// +optional
+	RemoteVersion int `json:"remoteVersion,omitempty"`
+
+	// ObservedGeneration is the last observed generation
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConsecutiveFailures is the number of failed pushes since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.spec.secretRef.name`
+// +kubebuilder:printcolumn:name="Path",type=string,JSONPath=`.spec.path`
+// +kubebuilder:printcolumn:name="Version",type=integer,JSONPath=`.status.remoteVersion`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BaoPushSecret is the Schema for the baopushsecrets API
+type BaoPushSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BaoPushSecretSpec   `json:"spec,omitempty"`
+	Status BaoPushSecretStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BaoPushSecretList contains a list of BaoPushSecret
+type BaoPushSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BaoPushSecret `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BaoPushSecret{}, &BaoPushSecretList{})
+}
@@ -0,0 +1,162 @@
+// Конвертация BaoSecret v1beta1 ⇄ v1alpha1 (hub, версия хранения).
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// ConvertTo converts this BaoSecret to the hub version (v1alpha1)
+func (src *BaoSecret) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.BaoSecret)
+	in := src.DeepCopy()
+	fields := readPreserved(in.ObjectMeta)
+
+	dst.ObjectMeta = objectMeta(in.ObjectMeta, nil)
+	dst.Spec = v1alpha1.BaoSecretSpec{
+		SecretPath:      in.Spec.SecretPath,
+		SecretKey:       in.Spec.SecretKey,
+		SecretEngine:    string(in.Spec.SecretEngine),
+		Target:          secretTargetToV1alpha1(in.Spec.Target),
+		RefreshInterval: durationToString(in.Spec.RefreshInterval, "refreshInterval", fields),
+		SecretArgs:      in.Spec.SecretArgs,
+		SuspendSync:     in.Spec.SuspendSync,
+		ConflictPolicy:  string(in.Spec.ConflictPolicy),
+	}
+	dst.Spec.OpenBaoRef, dst.Spec.RoleName = authToV1alpha1(in.Spec.Auth, fields)
+	if t := in.Spec.Template; t != nil {
+		dst.Spec.Template = &v1alpha1.SecretTemplate{Data: t.Data, StringData: t.StringData}
+	}
+	for _, s := range in.Spec.DataFrom {
+		dst.Spec.DataFrom = append(dst.Spec.DataFrom, v1alpha1.SecretSource(s))
+	}
+	if w := in.Spec.Wrapping; w != nil {
+		dst.Spec.Wrapping = &v1alpha1.SecretWrapping{
+			TTL:      durationToString(w.TTL, "wrapping.ttl", fields),
+			TokenKey: w.TokenKey,
+		}
+	}
+	if r := in.Spec.Rollout; r != nil {
+		dst.Spec.Rollout = &v1alpha1.SecretRollout{
+			Selector:    r.Selector,
+			MinInterval: durationToString(r.MinInterval, "rollout.minInterval", fields),
+		}
+		for _, t := range r.Targets {
+			dst.Spec.Rollout.Targets = append(dst.Spec.Rollout.Targets, v1alpha1.RolloutTarget{Kind: string(t.Kind), Name: t.Name})
+		}
+	}
+
+	s := in.Status
+	dst.Status = v1alpha1.BaoSecretStatus{
+		Conditions:            s.Conditions,
+		LastSyncTime:          s.LastSyncTime,
+		SecretVersion:         s.SecretVersion,
+		SyncedSecretName:      s.SyncedSecretName,
+		SyncedSecretNamespace: s.SyncedSecretNamespace,
+		ObservedGeneration:    s.ObservedGeneration,
+		WrappingTokenExpiry:   s.WrappingTokenExpiry,
+		LeaseID:               s.LeaseID,
+		LeaseDuration:         s.LeaseDuration,
+		LeaseExpiryTime:       s.LeaseExpiryTime,
+		RolloutVersion:        s.RolloutVersion,
+		LastRolloutTime:       s.LastRolloutTime,
+		RestartedWorkloads:    s.RestartedWorkloads,
+		SourceVersion:         s.SourceVersion,
+		SourceCreatedTime:     s.SourceCreatedTime,
+		ConsecutiveFailures:   s.ConsecutiveFailures,
+		LastError:             s.LastError,
+	}
+	for _, e := range s.SyncHistory {
+		dst.Status.SyncHistory = append(dst.Status.SyncHistory, v1alpha1.SyncHistoryEntry(e))
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha1) to this version
+func (dst *BaoSecret) ConvertFrom(srcRaw conversion.Hub) error {
+	in := srcRaw.(*v1alpha1.BaoSecret).DeepCopy()
+	fields := preservedFields{}
+
+	dst.Spec = BaoSecretSpec{
+		SecretPath:      in.Spec.SecretPath,
+		SecretKey:       in.Spec.SecretKey,
+		SecretEngine:    SecretEngine(in.Spec.SecretEngine),
+		Target:          secretTargetFromV1alpha1(in.Spec.Target),
+		RefreshInterval: durationFromString(in.Spec.RefreshInterval, "refreshInterval", fields),
+		Auth:            authFromV1alpha1(in.Spec.OpenBaoRef, in.Spec.RoleName, fields),
+		SecretArgs:      in.Spec.SecretArgs,
+		SuspendSync:     in.Spec.SuspendSync,
+		ConflictPolicy:  ConflictPolicy(in.Spec.ConflictPolicy),
+	}
+	if t := in.Spec.Template; t != nil {
+		dst.Spec.Template = &SecretTemplate{Data: t.Data, StringData: t.StringData}
+	}
+	for _, s := range in.Spec.DataFrom {
+		dst.Spec.DataFrom = append(dst.Spec.DataFrom, SecretSource(s))
+	}
+	if w := in.Spec.Wrapping; w != nil {
+		dst.Spec.Wrapping = &SecretWrapping{
+			TTL:      durationFromString(w.TTL, "wrapping.ttl", fields),
+			TokenKey: w.TokenKey,
+		}
+	}
+	if r := in.Spec.Rollout; r != nil {
+		dst.Spec.Rollout = &SecretRollout{
+			Selector:    r.Selector,
+			MinInterval: durationFromString(r.MinInterval, "rollout.minInterval", fields),
+		}
+		for _, t := range r.Targets {
+			dst.Spec.Rollout.Targets = append(dst.Spec.Rollout.Targets, RolloutTarget{Kind: RolloutKind(t.Kind), Name: t.Name})
+		}
+	}
+	dst.ObjectMeta = objectMeta(in.ObjectMeta, fields)
+
+	s := in.Status
+	dst.Status = BaoSecretStatus{
+		Conditions:            s.Conditions,
+		LastSyncTime:          s.LastSyncTime,
+		SecretVersion:         s.SecretVersion,
+		SyncedSecretName:      s.SyncedSecretName,
+		SyncedSecretNamespace: s.SyncedSecretNamespace,
+		ObservedGeneration:    s.ObservedGeneration,
+		WrappingTokenExpiry:   s.WrappingTokenExpiry,
+		LeaseID:               s.LeaseID,
+		LeaseDuration:         s.LeaseDuration,
+		LeaseExpiryTime:       s.LeaseExpiryTime,
+		RolloutVersion:        s.RolloutVersion,
+		LastRolloutTime:       s.LastRolloutTime,
+		RestartedWorkloads:    s.RestartedWorkloads,
+		SourceVersion:         s.SourceVersion,
+		SourceCreatedTime:     s.SourceCreatedTime,
+		ConsecutiveFailures:   s.ConsecutiveFailures,
+		LastError:             s.LastError,
+	}
+	for _, e := range s.SyncHistory {
+		dst.Status.SyncHistory = append(dst.Status.SyncHistory, SyncHistoryEntry(e))
+	}
+	return nil
+}
+
+func secretTargetToV1alpha1(t SecretTarget) v1alpha1.SecretTarget {
+	return v1alpha1.SecretTarget{
+		Name:           t.Name,
+		Namespace:      t.Namespace,
+		Type:           string(t.Type),
+		Labels:         t.Labels,
+		Annotations:    t.Annotations,
+		CreationPolicy: string(t.CreationPolicy),
+	}
+}
+
+func secretTargetFromV1alpha1(t v1alpha1.SecretTarget) SecretTarget {
+	return SecretTarget{
+		Name:           t.Name,
+		Namespace:      t.Namespace,
+		Type:           corev1.SecretType(t.Type),
+		Labels:         t.Labels,
+		Annotations:    t.Annotations,
+		CreationPolicy: CreationPolicy(t.CreationPolicy),
+	}
+}
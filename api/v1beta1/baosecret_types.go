@@ -0,0 +1,374 @@
+// API types для BaoSecret v1beta1 — CRD синхронизации секретов из OpenBao.
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretEngine is the type of the OpenBao secrets engine the secret is read from
+// +kubebuilder:validation:Enum=kv;database;pki;ssh;aws;azure;gcp;kubernetes;ldap;rabbitmq;consul;nomad;totp
+type SecretEngine string
+
+// Secrets engines. kv is read, pki and ssh are written to, the others are read for credentials
+const (
+	SecretEngineKV         SecretEngine = "kv"
+	SecretEngineDatabase   SecretEngine = "database"
+	SecretEnginePKI        SecretEngine = "pki"
+	SecretEngineSSH        SecretEngine = "ssh"
+	SecretEngineAWS        SecretEngine = "aws"
+	SecretEngineAzure      SecretEngine = "azure"
+	SecretEngineGCP        SecretEngine = "gcp"
+	SecretEngineKubernetes SecretEngine = "kubernetes"
+	SecretEngineLDAP       SecretEngine = "ldap"
+	SecretEngineRabbitMQ   SecretEngine = "rabbitmq"
+	SecretEngineConsul     SecretEngine = "consul"
+	SecretEngineNomad      SecretEngine = "nomad"
+	SecretEngineTOTP       SecretEngine = "totp"
+)
+
+// CreationPolicy defines how the target Secret is created and owned
+// +kubebuilder:validation:Enum=Owner;Orphan;Merge;None
+type CreationPolicy string
+
+// Creation policies for the target Secret
+const (
+	// CreationPolicyOwner creates the target with an owner reference to the BaoSecret
+	CreationPolicyOwner CreationPolicy = "Owner"
+	// CreationPolicyOrphan creates the target without an owner reference
+	CreationPolicyOrphan CreationPolicy = "Orphan"
+	// CreationPolicyMerge writes the synced keys into an existing target and keeps other keys
+	CreationPolicyMerge CreationPolicy = "Merge"
+	// CreationPolicyNone reads and renders the secret without writing the target
+	CreationPolicyNone CreationPolicy = "None"
+)
+
+// ConflictPolicy defines what happens when several sources produce the same key
+// +kubebuilder:validation:Enum=Error;Overwrite;KeepFirst
+type ConflictPolicy string
+
+// Conflict policies for keys produced by several sources
+const (
+	// ConflictPolicyError fails the sync on a duplicate key
+	ConflictPolicyError ConflictPolicy = "Error"
+	// ConflictPolicyOverwrite lets the later source win
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+	// ConflictPolicyKeepFirst keeps the value from the earlier source
+	ConflictPolicyKeepFirst ConflictPolicy = "KeepFirst"
+)
+
+// RolloutKind is the kind of a workload restarted by Rollout
+// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+type RolloutKind string
+
+// Workload kinds restarted by Rollout
+const (
+	RolloutKindDeployment  RolloutKind = "Deployment"
+	RolloutKindStatefulSet RolloutKind = "StatefulSet"
+	RolloutKindDaemonSet   RolloutKind = "DaemonSet"
+)
+
+// BaoSecretSpec defines the desired state of BaoSecret
+type BaoSecretSpec struct {
+	// SecretPath is the path in OpenBao where the secret is stored.
+	// Either SecretPath or DataFrom must be set
+	// +optional
+	SecretPath string `json:"secretPath,omitempty"`
+
+	// SecretKey is the specific key to extract from the secret.
+	// If not specified, all keys will be synced
+	// +optional
+	SecretKey string `json:"secretKey,omitempty"`
+
+	// SecretEngine is the type of secrets engine
+	// +kubebuilder:default=kv
+	// +optional
+	SecretEngine SecretEngine `json:"secretEngine,omitempty"`
+
+	// Target defines where to sync the secret
+	// +kubebuilder:validation:Required
+	Target SecretTarget `json:"target"`
+
+	// RefreshInterval is the interval at which to refresh the secret; at least 1m
+	// +kubebuilder:default="1h"
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// Auth selects the OpenBao connection and identity; the operator's if not set
+	// +optional
+	Auth *OpenBaoAuth `json:"auth,omitempty"`
+
+	// SecretArgs are additional arguments for dynamic secrets (database, pki)
+	// +optional
+	SecretArgs map[string]string `json:"secretArgs,omitempty"`
+
+	// Template allows transforming the secret data before syncing
+	// +optional
+	Template *SecretTemplate `json:"template,omitempty"`
+
+	// SuspendSync suspends the synchronization of the secret
+	// +optional
+	SuspendSync bool `json:"suspendSync,omitempty"`
+
+	// DataFrom lists additional KV v2 paths merged into the target Secret after SecretPath,
+	// in order. Not supported together with a dynamic SecretEngine or Wrapping
+	// +optional
+	DataFrom []SecretSource `json:"dataFrom,omitempty"`
+
+	// ConflictPolicy defines what happens when several sources produce the same key
+	// +kubebuilder:default=Error
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// Wrapping delivers a single-use response-wrapping token instead of the secret data.
+	// The workload unwraps the token itself, so the secret never reaches etcd.
+	// SecretKey and Template are ignored when wrapping is enabled.
+	// +optional
+	Wrapping *SecretWrapping `json:"wrapping,omitempty"`
+
+	// Rollout restarts workloads consuming the target Secret when its data changes
+	// by patching the kubebao.io/version annotation of their pod template
+	// +optional
+	Rollout *SecretRollout `json:"rollout,omitempty"`
+}
+
+// SecretRollout selects workloads restarted after the target Secret changes.
+// Workloads are looked up in the namespace of the target Secret
+type SecretRollout struct {
+	// Targets lists workloads by kind and name
+	// +optional
+	Targets []RolloutTarget `json:"targets,omitempty"`
+
+	// Selector discovers Deployments, StatefulSets and DaemonSets by label
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// MinInterval is the minimum time between two rollouts of this BaoSecret;
+	// changes within the interval are rolled out together when it elapses
+	// +kubebuilder:default="1m"
+	// +optional
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+}
+
+// RolloutTarget references a workload restarted after the target Secret changes
+type RolloutTarget struct {
+	// Kind is the workload kind
+	// +kubebuilder:validation:Required
+	Kind RolloutKind `json:"kind"`
+
+	// Name is the workload name
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// SecretSource is one KV v2 path merged into the target Secret
+type SecretSource struct {
+	// Path is the path in OpenBao KV v2 (without the "data/" prefix)
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// Keys selects keys from the source; all keys are used if empty
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+
+	// Rename maps source keys to target keys
+	// +optional
+	Rename map[string]string `json:"rename,omitempty"`
+
+	// Prefix is prepended to every target key of this source (after Rename)
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// SecretWrapping configures secret-zero delivery through OpenBao response wrapping
+type SecretWrapping struct {
+	// TTL is the lifetime of the wrapping token; an unused token is reissued after it expires
+	// +kubebuilder:default="5m"
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// TokenKey is the key in the target Secret that holds the wrapping token
+	// +kubebuilder:default=token
+	// +optional
+	TokenKey string `json:"tokenKey,omitempty"`
+}
+
+// SecretTarget defines where to sync the secret
+type SecretTarget struct {
+	// Name is the name of the target Kubernetes Secret
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the target Secret
+	// If not specified, uses the same namespace as the BaoSecret
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Type is the type of the Kubernetes Secret
+	// +kubebuilder:default=Opaque
+	// +optional
+	Type corev1.SecretType `json:"type,omitempty"`
+
+	// Labels to add to the target Secret
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to add to the target Secret
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// CreationPolicy defines how the target Secret is created and owned:
+	// Owner creates it with an owner reference, Orphan creates it without one,
+	// Merge only writes the synced keys into an existing Secret, None does not write it at all.
+	// Owner and Orphan refuse to take over an existing Secret not managed by kubebao
+	// +kubebuilder:default=Owner
+	// +optional
+	CreationPolicy CreationPolicy `json:"creationPolicy,omitempty"`
+}
+
+// SecretTemplate allows transforming secret data
+type SecretTemplate struct {
+	// Data is a map of template strings
+	// Keys are the target secret data keys
+	// Values are Go text/templates that can reference source data with {{ .Data.key }}
+	// and metadata with {{ .Metadata.version }}. Available functions: b64enc, b64dec,
+	// toJson, fromJson, toYaml, fromYaml, pkcs12, pkcs12Pass, pemCertificates, default,
+	// upper, lower
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+
+	// StringData is a map of template strings for string data
+	// +optional
+	StringData map[string]string `json:"stringData,omitempty"`
+}
+
+// BaoSecretStatus defines the observed state of BaoSecret
+type BaoSecretStatus struct {
+	// Conditions represent the latest available observations of the BaoSecret's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastSyncTime is the last time the secret was synced
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// SecretVersion is the version of the secret in OpenBao
+	// +optional
+	SecretVersion string `json:"secretVersion,omitempty"`
+
+	// SyncedSecretName is the name of the synced Kubernetes Secret
+	// +optional
+	SyncedSecretName string `json:"syncedSecretName,omitempty"`
+
+	// SyncedSecretNamespace is the namespace of the synced Kubernetes Secret
+	// +optional
+	SyncedSecretNamespace string `json:"syncedSecretNamespace,omitempty"`
+
+	// ObservedGeneration is the last observed generation
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// WrappingTokenExpiry is the expiry time of the delivered wrapping token
+	// +optional
+	WrappingTokenExpiry *metav1.Time `json:"wrappingTokenExpiry,omitempty"`
+
+	// LeaseID is the lease of the current dynamic credentials
+	// +optional
+	LeaseID string `json:"leaseID,omitempty"`
+
+	// LeaseDuration is the TTL the current dynamic credentials were issued with
+	// +optional
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+
+	// LeaseExpiryTime is the time the current dynamic credentials expire
+	// +optional
+	LeaseExpiryTime *metav1.Time `json:"leaseExpiryTime,omitempty"`
+
+	// RolloutVersion is the SecretVersion the rollout workloads were last restarted with
+	// +optional
+	RolloutVersion string `json:"rolloutVersion,omitempty"`
+
+	// LastRolloutTime is the last time workloads were restarted
+	// +optional
+	LastRolloutTime *metav1.Time `json:"lastRolloutTime,omitempty"`
+
+	// RestartedWorkloads lists the workloads restarted by the last rollout as Kind/name
+	// +optional
+	RestartedWorkloads []string `json:"restartedWorkloads,omitempty"`
+
+	// SourceVersion is the OpenBao KV metadata version of the synced data; with several
+	// sources the versions of SecretPath and DataFrom are listed in order, comma-separated
+	// +optional
+	SourceVersion string `json:"sourceVersion,omitempty"`
+
+	// SourceCreatedTime is the time the synced version of the first KV source was written
+	// +optional
+	SourceCreatedTime *metav1.Time `json:"sourceCreatedTime,omitempty"`
+
+	// ConsecutiveFailures is the number of failed syncs since the last successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// LastError is the error of the last failed sync; cleared after a successful sync
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// SyncHistory lists the most recent sync outcomes, newest first.
+	// Repeated identical outcomes update the time of the newest entry
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	SyncHistory []SyncHistoryEntry `json:"syncHistory,omitempty"`
+}
+
+// SyncHistoryEntry is one sync outcome of a BaoSecret
+type SyncHistoryEntry struct {
+	// Time is the last time the sync ended with this outcome
+	Time metav1.Time `json:"time"`
+
+	// Result is Success or Failed
+	Result string `json:"result"`
+
+	// SourceVersion is the OpenBao KV version that was synced
+	// +optional
+	SourceVersion string `json:"sourceVersion,omitempty"`
+
+	// SecretVersion is the hash of the target Secret data that was written
+	// +optional
+	SecretVersion string `json:"secretVersion,omitempty"`
+
+	// Message is the error of a failed sync
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Source Version",type=string,JSONPath=`.status.sourceVersion`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Secret Path",type=string,JSONPath=`.spec.secretPath`,priority=1
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target.name`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BaoSecret is the Schema for the baosecrets API
+type BaoSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BaoSecretSpec   `json:"spec,omitempty"`
+	Status BaoSecretStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BaoSecretList contains a list of BaoSecret
+type BaoSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BaoSecret `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BaoSecret{}, &BaoSecretList{})
+}
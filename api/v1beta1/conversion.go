@@ -0,0 +1,145 @@
+// Общая часть конвертации v1beta1 ⇄ v1alpha1: интервалы, блок auth и значения v1alpha1, которые
+// v1beta1 не выражает.
+package v1beta1
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// PreservedFieldsAnnotation хранит значения полей v1alpha1, которые v1beta1 не выражает
+// (неразбираемый интервал, неизвестный authMethod). Обратная конвертация восстанавливает их,
+// поэтому чтение и запись объекта через v1beta1 ничего не теряют.
+const PreservedFieldsAnnotation = "conversion.kubebao.io/v1alpha1-fields"
+
+// preservedFields — значения из PreservedFieldsAnnotation по пути поля v1alpha1.
+type preservedFields map[string]string
+
+// readPreserved разбирает аннотацию; повреждённая аннотация считается пустой.
+func readPreserved(meta metav1.ObjectMeta) preservedFields {
+	fields := preservedFields{}
+	if raw, ok := meta.Annotations[PreservedFieldsAnnotation]; ok {
+		_ = json.Unmarshal([]byte(raw), &fields)
+	}
+	return fields
+}
+
+// objectMeta — копия метаданных без PreservedFieldsAnnotation; при непустом fields аннотация
+// записывается заново.
+func objectMeta(meta metav1.ObjectMeta, fields preservedFields) metav1.ObjectMeta {
+	out := *meta.DeepCopy()
+	delete(out.Annotations, PreservedFieldsAnnotation)
+	if len(fields) > 0 {
+		raw, _ := json.Marshal(fields)
+		if out.Annotations == nil {
+			out.Annotations = map[string]string{}
+		}
+		out.Annotations[PreservedFieldsAnnotation] = string(raw)
+	}
+	if len(out.Annotations) == 0 {
+		out.Annotations = nil
+	}
+	return out
+}
+
+// durationFromString — интервал v1alpha1 в metav1.Duration; неразбираемое значение сохраняется
+// в fields под именем поля.
+func durationFromString(value, fieldPath string, fields preservedFields) *metav1.Duration {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fields[fieldPath] = value
+		return nil
+	}
+	return &metav1.Duration{Duration: d}
+}
+
+// durationToString — интервал v1beta1 строкой v1alpha1 без нулевых хвостов ("1h", а не
+// "1h0m0s"); без значения — сохранённое в fields.
+func durationToString(d *metav1.Duration, fieldPath string, fields preservedFields) string {
+	if d == nil {
+		return fields[fieldPath]
+	}
+	s := d.Duration.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// authFromV1alpha1 — блок auth из openbaoRef и roleName. Неизвестный authMethod сохраняется в
+// fields, а вход описывается блоком kubernetes.
+func authFromV1alpha1(ref *v1alpha1.OpenBaoReference, role string, fields preservedFields) *OpenBaoAuth {
+	if ref == nil && role == "" {
+		return nil
+	}
+
+	auth := &OpenBaoAuth{}
+	login := &ServiceAccountAuth{Role: role}
+	method := ""
+	if ref != nil {
+		auth.Address = ref.Address
+		auth.Namespace = ref.Namespace
+		login.MountPath = ref.AuthMountPath
+		if sa := ref.ServiceAccountRef; sa != nil {
+			login.ServiceAccountRef = &ServiceAccountReference{Name: sa.Name, Namespace: sa.Namespace}
+		}
+		method = ref.AuthMethod
+	}
+
+	switch method {
+	case "", v1alpha1.AuthMethodKubernetes:
+		auth.Kubernetes = login
+	case v1alpha1.AuthMethodJWT:
+		auth.JWT = login
+	default:
+		fields["openbaoRef.authMethod"] = method
+		auth.Kubernetes = login
+	}
+	return auth
+}
+
+// authToV1alpha1 — openbaoRef и roleName из блока auth. Блок только с ролью kubernetes
+// становится roleName без openbaoRef: так его и записывают в v1alpha1.
+func authToV1alpha1(auth *OpenBaoAuth, fields preservedFields) (*v1alpha1.OpenBaoReference, string) {
+	if auth == nil {
+		return nil, ""
+	}
+
+	login, method := auth.Kubernetes, v1alpha1.AuthMethodKubernetes
+	if auth.JWT != nil {
+		login, method = auth.JWT, v1alpha1.AuthMethodJWT
+	}
+	if login == nil {
+		login = &ServiceAccountAuth{}
+	}
+	if preserved, ok := fields["openbaoRef.authMethod"]; ok && method == v1alpha1.AuthMethodKubernetes {
+		method = preserved
+	}
+
+	if method == v1alpha1.AuthMethodKubernetes && login.Role != "" && auth.Address == "" && auth.Namespace == "" &&
+		login.MountPath == "" && login.ServiceAccountRef == nil {
+		return nil, login.Role
+	}
+
+	ref := &v1alpha1.OpenBaoReference{
+		Address:       auth.Address,
+		Namespace:     auth.Namespace,
+		AuthMethod:    method,
+		AuthMountPath: login.MountPath,
+	}
+	if sa := login.ServiceAccountRef; sa != nil {
+		ref.ServiceAccountRef = &v1alpha1.ServiceAccountReference{Name: sa.Name, Namespace: sa.Namespace}
+	}
+	return ref, login.Role
+}
@@ -0,0 +1,145 @@
+// Тесты конвертации v1beta1 ⇄ v1alpha1.
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	"github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestConvertible(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	require.NoError(t, AddToScheme(scheme))
+
+	for _, obj := range []runtime.Object{&v1alpha1.BaoSecret{}, &v1alpha1.BaoPushSecret{}} {
+		ok, err := conversion.IsConvertible(scheme, obj)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestBaoSecretRoundTrip(t *testing.T) {
+	hub := &v1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps", Annotations: map[string]string{"team": "a"}},
+		Spec: v1alpha1.BaoSecretSpec{
+			SecretPath:      "apps/db",
+			SecretEngine:    "kv",
+			Target:          v1alpha1.SecretTarget{Name: "db", Type: "kubernetes.io/tls", CreationPolicy: "Merge"},
+			RefreshInterval: "1h30m",
+			OpenBaoRef: &v1alpha1.OpenBaoReference{
+				Address:           "https://bao:8200",
+				AuthMethod:        "jwt",
+				AuthMountPath:     "jwt-apps",
+				ServiceAccountRef: &v1alpha1.ServiceAccountReference{Name: "app"},
+			},
+			RoleName:       "apps",
+			DataFrom:       []v1alpha1.SecretSource{{Path: "apps/common", Keys: []string{"a"}}},
+			ConflictPolicy: "KeepFirst",
+			Wrapping:       &v1alpha1.SecretWrapping{TTL: "5m", TokenKey: "token"},
+			Rollout: &v1alpha1.SecretRollout{
+				Targets:     []v1alpha1.RolloutTarget{{Kind: "Deployment", Name: "api"}},
+				MinInterval: "1m",
+			},
+		},
+		Status: v1alpha1.BaoSecretStatus{
+			SourceVersion: "3",
+			SyncHistory:   []v1alpha1.SyncHistoryEntry{{Result: "Success", SourceVersion: "3"}},
+		},
+	}
+
+	beta := &BaoSecret{}
+	require.NoError(t, beta.ConvertFrom(hub))
+	assert.Equal(t, 90*time.Minute, beta.Spec.RefreshInterval.Duration)
+	assert.Equal(t, corev1.SecretTypeTLS, beta.Spec.Target.Type)
+	assert.Equal(t, CreationPolicyMerge, beta.Spec.Target.CreationPolicy)
+	assert.Equal(t, 5*time.Minute, beta.Spec.Wrapping.TTL.Duration)
+	require.NotNil(t, beta.Spec.Auth)
+	assert.Nil(t, beta.Spec.Auth.Kubernetes)
+	assert.Equal(t, &ServiceAccountAuth{
+		Role:              "apps",
+		MountPath:         "jwt-apps",
+		ServiceAccountRef: &ServiceAccountReference{Name: "app"},
+	}, beta.Spec.Auth.JWT)
+	assert.NotContains(t, beta.Annotations, PreservedFieldsAnnotation)
+
+	back := &v1alpha1.BaoSecret{}
+	require.NoError(t, beta.ConvertTo(back))
+	assert.Equal(t, hub, back)
+}
+
+func TestBaoSecretRoleOnly(t *testing.T) {
+	hub := &v1alpha1.BaoSecret{Spec: v1alpha1.BaoSecretSpec{RoleName: "apps"}}
+
+	beta := &BaoSecret{}
+	require.NoError(t, beta.ConvertFrom(hub))
+	assert.Equal(t, &OpenBaoAuth{Kubernetes: &ServiceAccountAuth{Role: "apps"}}, beta.Spec.Auth)
+
+	back := &v1alpha1.BaoSecret{}
+	require.NoError(t, beta.ConvertTo(back))
+	assert.Nil(t, back.Spec.OpenBaoRef)
+	assert.Equal(t, "apps", back.Spec.RoleName)
+
+	// Пустой блок kubernetes — вход со своим ServiceAccount, а не через оператора
+	beta.Spec.Auth = &OpenBaoAuth{Kubernetes: &ServiceAccountAuth{}}
+	require.NoError(t, beta.ConvertTo(back))
+	assert.Equal(t, &v1alpha1.OpenBaoReference{AuthMethod: "kubernetes"}, back.Spec.OpenBaoRef)
+}
+
+func TestBaoSecretPreservesUnconvertibleFields(t *testing.T) {
+	hub := &v1alpha1.BaoSecret{
+		Spec: v1alpha1.BaoSecretSpec{
+			RefreshInterval: "1d",
+			OpenBaoRef:      &v1alpha1.OpenBaoReference{AuthMethod: "approle"},
+		},
+	}
+
+	beta := &BaoSecret{}
+	require.NoError(t, beta.ConvertFrom(hub))
+	assert.Nil(t, beta.Spec.RefreshInterval)
+	assert.Contains(t, beta.Annotations, PreservedFieldsAnnotation)
+
+	back := &v1alpha1.BaoSecret{}
+	require.NoError(t, beta.ConvertTo(back))
+	assert.Equal(t, hub, back)
+
+	// Значение, заданное через v1beta1, важнее сохранённого
+	beta.Spec.RefreshInterval = &metav1.Duration{Duration: 2 * time.Hour}
+	require.NoError(t, beta.ConvertTo(back))
+	assert.Equal(t, "2h", back.Spec.RefreshInterval)
+	assert.Empty(t, back.Annotations)
+}
+
+func TestBaoPushSecretRoundTrip(t *testing.T) {
+	hub := &v1alpha1.BaoPushSecret{
+		ObjectMeta: metav1.ObjectMeta{Name: "push", Namespace: "apps"},
+		Spec: v1alpha1.BaoPushSecretSpec{
+			SecretRef:       v1alpha1.LocalSecretReference{Name: "src"},
+			Path:            "apps/pushed",
+			Data:            []v1alpha1.PushSecretKey{{SecretKey: "a", RemoteKey: "b"}},
+			DeletionPolicy:  "Delete",
+			RefreshInterval: "10m",
+			OpenBaoRef:      &v1alpha1.OpenBaoReference{Namespace: "team-a", AuthMethod: "kubernetes"},
+		},
+		Status: v1alpha1.BaoPushSecretStatus{RemoteVersion: 4},
+	}
+
+	beta := &BaoPushSecret{}
+	require.NoError(t, beta.ConvertFrom(hub))
+	assert.Equal(t, DeletionPolicyDelete, beta.Spec.DeletionPolicy)
+	assert.Equal(t, 10*time.Minute, beta.Spec.RefreshInterval.Duration)
+	assert.Equal(t, "team-a", beta.Spec.Auth.Namespace)
+	assert.NotNil(t, beta.Spec.Auth.Kubernetes)
+
+	back := &v1alpha1.BaoPushSecret{}
+	require.NoError(t, beta.ConvertTo(back))
+	assert.Equal(t, hub, back)
+}
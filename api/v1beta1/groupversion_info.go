@@ -0,0 +1,22 @@
+// Пакет v1beta1 — API-схемы kubebao v1beta1 (BaoSecret, BaoPushSecret): перечисления вместо
+// строк, metav1.Duration для интервалов и структурированный блок auth. Хранится v1alpha1,
+// v1beta1 конвертируется conversion webhook оператора.
+// +kubebuilder:object:generate=true
+// +groupName=kubebao.io
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "kubebao.io", Version: "v1beta1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
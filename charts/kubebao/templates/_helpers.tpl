@@ -107,6 +107,29 @@ Operator selector labels
 app.kubernetes.io/component: operator
 {{- end }}
 
+{{/*
+Conversion webhook of the CRDs with a v1beta1 version. The caBundle is injected by the
+operator; an existing one is kept on upgrade
+*/}}
+{{- define "kubebao.crdConversion" -}}
+{{- $crd := lookup "apiextensions.k8s.io/v1" "CustomResourceDefinition" "" .crdName -}}
+{{- $caBundle := "" -}}
+{{- if $crd -}}
+{{- $caBundle = dig "spec" "conversion" "webhook" "clientConfig" "caBundle" "" $crd -}}
+{{- end -}}
+strategy: Webhook
+webhook:
+  conversionReviewVersions: ["v1"]
+  clientConfig:
+    service:
+      name: {{ printf "%s-webhook" .root.Values.operator.name }}
+      namespace: {{ .root.Release.Namespace }}
+      path: /convert
+    {{- with $caBundle }}
+    caBundle: {{ . }}
+    {{- end }}
+{{- end }}
+
 {{/*
 Get the image for a component
 */}}
@@ -0,0 +1,232 @@
+// Команда export-policies: ACL-политики OpenBao в манифесты BaoPolicy или ClusterBaoPolicy для
+// перехода на декларативное управление политиками.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/acl"
+	"github.com/kubebao/kubebao/internal/controller"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+// Встроенные политики OpenBao: root не читается, default экспортируется только по имени в --policy
+var builtinPolicies = []string{"default", "root"}
+
+// invalidNameChars — символы имени политики, недопустимые в имени объекта Kubernetes.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// policyManifest — манифест без status и пустых полей metadata.
+type policyManifest struct {
+	APIVersion string                          `json:"apiVersion"`
+	Kind       string                          `json:"kind"`
+	Metadata   policyManifestMetadata          `json:"metadata"`
+	Spec       kubebaoiov1alpha1.BaoPolicySpec `json:"spec"`
+}
+
+type policyManifestMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// exportOptions — флаги export-policies.
+type exportOptions struct {
+	namespace         string
+	policies          []string
+	legacyPolicyNames bool
+	adopt             bool
+	clusterName       string
+}
+
+// runExportPolicies выполняет `kubebao-operator export-policies` и возвращает код выхода.
+// Манифесты пишутся в stdout, ошибки — в stderr; политика, которую правила не выражают,
+// не экспортируется, а код выхода становится 1.
+func runExportPolicies(args []string) int {
+	fs := flag.NewFlagSet("export-policies", flag.ContinueOnError)
+	var (
+		configFile string
+		policies   string
+		logLevel   string
+		opts       exportOptions
+	)
+	fs.StringVar(&configFile, "config", "", "Path to OpenBao configuration file; environment variables are used if not set")
+	fs.StringVar(&opts.namespace, "namespace", "",
+		"Export BaoPolicy objects in this namespace; only policies named <namespace>_<name> are exported. ClusterBaoPolicy objects if not set")
+	fs.StringVar(&policies, "policy", "", "Comma-separated policy names to export; all policies except default and root if not set")
+	fs.BoolVar(&opts.legacyPolicyNames, "legacy-policy-names", false,
+		"Export BaoPolicy objects for an operator running with --legacy-policy-names: policy names are not prefixed with the namespace")
+	fs.BoolVar(&opts.adopt, "adopt", false,
+		"Add the ownership marker of the exported object to each exported policy in OpenBao, so the operator takes the policy over instead of reporting OwnershipConflict")
+	fs.StringVar(&opts.clusterName, "cluster-name", "default", "Cluster name of the operator, used in the ownership marker with --adopt")
+	fs.StringVar(&logLevel, "log-level", "error", "Log level of the OpenBao client (debug, info, warn, error)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if policies != "" {
+		opts.policies = strings.Split(policies, ",")
+	}
+
+	var cfg *openbao.Config
+	if configFile != "" {
+		var err error
+		if cfg, err = openbao.LoadConfig(configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load OpenBao configuration: %v\n", err)
+			return 1
+		}
+	} else {
+		cfg = openbao.LoadConfigFromEnv()
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{Name: "openbao", Level: hclog.LevelFromString(logLevel), Output: os.Stderr})
+	baoClient, err := openbao.NewClient(cfg, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create OpenBao client: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := exportPolicies(ctx, baoClient, opts, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// exportPolicies пишет манифесты в out; о пропущенных политиках сообщает в errOut. Ошибка —
+// если хотя бы одна выбранная политика не экспортирована.
+func exportPolicies(ctx context.Context, baoClient *openbao.Client, opts exportOptions, out, errOut io.Writer) error {
+	names := opts.policies
+	if len(names) == 0 {
+		all, err := baoClient.ListACLPolicies(ctx)
+		if err != nil {
+			return err
+		}
+		for _, name := range all {
+			if !slices.Contains(builtinPolicies, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+
+	failed := 0
+	objectNames := make(map[string]string, len(names))
+	for _, policyName := range names {
+		manifest, current, err := exportPolicy(ctx, baoClient, opts, policyName)
+		if err == nil {
+			if other, ok := objectNames[manifest.Metadata.Name]; ok {
+				err = fmt.Errorf("object name %s is already used by policy %s", manifest.Metadata.Name, other)
+			}
+		}
+		if err == nil && opts.adopt {
+			err = adoptPolicy(ctx, baoClient, opts, policyName, current, manifest)
+		}
+		if err != nil {
+			fmt.Fprintf(errOut, "policy %s: %v\n", policyName, err)
+			failed++
+			continue
+		}
+		objectNames[manifest.Metadata.Name] = policyName
+
+		data, err := yaml.Marshal(manifest)
+		if err != nil {
+			return fmt.Errorf("failed to marshal policy %s: %w", policyName, err)
+		}
+		fmt.Fprintf(out, "---\n# Exported from OpenBao policy %q\n%s", policyName, data)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d policies were not exported", failed, len(names))
+	}
+	return nil
+}
+
+// exportPolicy — манифест одной политики. Правила проверяются так же, как webhook: манифест,
+// который оператор отклонит, не выводится.
+func exportPolicy(ctx context.Context, baoClient *openbao.Client, opts exportOptions, policyName string) (*policyManifest, string, error) {
+	if policyName == "root" {
+		return nil, "", errors.New("the root policy cannot be exported")
+	}
+
+	current, err := baoClient.ReadACLPolicy(ctx, policyName)
+	if err != nil {
+		return nil, "", err
+	}
+	firstLine, _, _ := strings.Cut(current, "\n")
+	if strings.HasPrefix(strings.TrimSpace(firstLine), kubebaoiov1alpha1.PolicyOwnerPrefix) {
+		return nil, "", fmt.Errorf("already managed by kubebao (%s)", strings.TrimSpace(firstLine))
+	}
+
+	rules, err := acl.Parse(current)
+	if err != nil {
+		return nil, "", err
+	}
+	if errs := acl.ValidateRules(rules, field.NewPath("spec", "rules")); len(errs) > 0 {
+		return nil, "", errs.ToAggregate()
+	}
+
+	manifest := &policyManifest{
+		APIVersion: kubebaoiov1alpha1.GroupVersion.String(),
+		Kind:       "ClusterBaoPolicy",
+		Spec: kubebaoiov1alpha1.BaoPolicySpec{
+			PolicyName: policyName,
+			Rules:      rules,
+		},
+	}
+
+	// Имя BaoPolicy в OpenBao — <namespace>_<policyName>: префикс убирается из spec.policyName
+	if opts.namespace != "" {
+		manifest.Kind = "BaoPolicy"
+		manifest.Metadata.Namespace = opts.namespace
+		if !opts.legacyPolicyNames {
+			unscoped, ok := strings.CutPrefix(policyName, opts.namespace+kubebaoiov1alpha1.NamespaceSeparator)
+			if !ok || unscoped == "" {
+				return nil, "", fmt.Errorf("policy name is not prefixed with %s_; export it as a ClusterBaoPolicy or use --legacy-policy-names", opts.namespace)
+			}
+			manifest.Spec.PolicyName = unscoped
+		}
+	}
+
+	manifest.Metadata.Name, err = objectName(manifest.Spec.PolicyName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return manifest, current, nil
+}
+
+// adoptPolicy добавляет первой строкой политики маркер владельца экспортированного объекта.
+func adoptPolicy(ctx context.Context, baoClient *openbao.Client, opts exportOptions, policyName, current string, manifest *policyManifest) error {
+	marker := controller.PolicyOwnerMarker(opts.clusterName, manifest.Metadata.Namespace, manifest.Metadata.Name)
+	if err := baoClient.WriteACLPolicy(ctx, policyName, marker+"\n"+current); err != nil {
+		return fmt.Errorf("failed to add the ownership marker: %w", err)
+	}
+	return nil
+}
+
+// objectName — имя объекта Kubernetes из имени политики: строчные буквы, прочие символы — "-".
+func objectName(policyName string) (string, error) {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(policyName), "-")
+	name = strings.Trim(name, "-.")
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("cannot derive an object name from %q: %s", policyName, strings.Join(errs, "; "))
+	}
+	return name, nil
+}
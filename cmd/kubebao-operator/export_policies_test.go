@@ -0,0 +1,225 @@
+// Тесты команды export-policies.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+const readAppPolicy = `path "secret/data/app/*" {
+  capabilities = ["read"]
+}
+`
+
+// policyOpenBao — OpenBao с ACL-политиками sys/policies/acl.
+type policyOpenBao struct {
+	mu       sync.Mutex
+	policies map[string]string
+}
+
+func (b *policyOpenBao) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	name := strings.TrimPrefix(r.URL.Path, "/v1/sys/policies/acl")
+	name = strings.TrimPrefix(name, "/")
+	switch {
+	case name == "" && (r.Method == "LIST" || r.URL.Query().Get("list") == "true"):
+		keys := make([]string, 0, len(b.policies))
+		for key := range b.policies {
+			keys = append(keys, key)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+	case r.Method == http.MethodGet:
+		policy, ok := b.policies[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"name": name, "policy": policy}})
+	case r.Method == http.MethodPut || r.Method == http.MethodPost:
+		var body struct {
+			Policy string `json:"policy"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		b.policies[name] = body.Policy
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// newPolicyOpenBao — policyOpenBao с политиками policies и клиент к нему.
+func newPolicyOpenBao(t *testing.T, policies map[string]string) (*policyOpenBao, *openbao.Client, string) {
+	t.Helper()
+
+	bao := &policyOpenBao{policies: policies}
+	server := httptest.NewServer(http.HandlerFunc(bao.serveHTTP))
+	t.Cleanup(server.Close)
+	baoClient, err := openbao.NewClient(&openbao.Config{Address: server.URL, Token: "test-token", MaxRetries: 1}, hclog.NewNullLogger())
+	require.NoError(t, err)
+	return bao, baoClient, server.URL
+}
+
+// exportManifests — манифесты из вывода export-policies.
+func exportManifests(t *testing.T, out string) []policyManifest {
+	t.Helper()
+
+	var manifests []policyManifest
+	for _, doc := range strings.Split(out, "---\n") {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		var manifest policyManifest
+		require.NoError(t, yaml.Unmarshal([]byte(doc), &manifest))
+		manifests = append(manifests, manifest)
+	}
+	return manifests
+}
+
+func TestExportPoliciesCluster(t *testing.T) {
+	_, baoClient, _ := newPolicyOpenBao(t, map[string]string{
+		"app-reader": readAppPolicy,
+		"default":    readAppPolicy,
+		"root":       "",
+	})
+
+	var out, errOut bytes.Buffer
+	require.NoError(t, exportPolicies(context.Background(), baoClient, exportOptions{}, &out, &errOut))
+	assert.Empty(t, errOut.String())
+
+	// Встроенные default и root не экспортируются
+	manifests := exportManifests(t, out.String())
+	require.Len(t, manifests, 1)
+	assert.Equal(t, "ClusterBaoPolicy", manifests[0].Kind)
+	assert.Equal(t, policyManifestMetadata{Name: "app-reader"}, manifests[0].Metadata)
+	assert.Equal(t, "app-reader", manifests[0].Spec.PolicyName)
+	assert.Equal(t, []kubebaoiov1alpha1.PolicyRule{{
+		Path:         "secret/data/app/*",
+		Capabilities: []kubebaoiov1alpha1.Capability{kubebaoiov1alpha1.CapabilityRead},
+	}}, manifests[0].Spec.Rules)
+	assert.Contains(t, out.String(), "# Exported from OpenBao policy \"app-reader\"")
+}
+
+func TestExportPoliciesNamespacePrefix(t *testing.T) {
+	_, baoClient, _ := newPolicyOpenBao(t, map[string]string{
+		"apps_reader": readAppPolicy,
+		"billing":     readAppPolicy,
+		"apps_":       readAppPolicy,
+	})
+	ctx := context.Background()
+
+	var out, errOut bytes.Buffer
+	err := exportPolicies(ctx, baoClient, exportOptions{namespace: "apps", policies: []string{"apps_reader", "billing", "apps_"}}, &out, &errOut)
+	assert.EqualError(t, err, "2 of 3 policies were not exported")
+
+	manifests := exportManifests(t, out.String())
+	require.Len(t, manifests, 1)
+	assert.Equal(t, "BaoPolicy", manifests[0].Kind)
+	assert.Equal(t, policyManifestMetadata{Name: "reader", Namespace: "apps"}, manifests[0].Metadata)
+	assert.Equal(t, "reader", manifests[0].Spec.PolicyName)
+	assert.Contains(t, errOut.String(), "policy billing: policy name is not prefixed with apps_")
+	assert.Contains(t, errOut.String(), "policy apps_: policy name is not prefixed with apps_")
+
+	// С --legacy-policy-names имя политики не содержит namespace
+	out.Reset()
+	errOut.Reset()
+	require.NoError(t, exportPolicies(ctx, baoClient, exportOptions{namespace: "apps", legacyPolicyNames: true, policies: []string{"billing"}}, &out, &errOut))
+	manifests = exportManifests(t, out.String())
+	require.Len(t, manifests, 1)
+	assert.Equal(t, policyManifestMetadata{Name: "billing", Namespace: "apps"}, manifests[0].Metadata)
+	assert.Equal(t, "billing", manifests[0].Spec.PolicyName)
+}
+
+func TestExportPoliciesObjectNameCollision(t *testing.T) {
+	_, baoClient, _ := newPolicyOpenBao(t, map[string]string{
+		"App_Reader": readAppPolicy,
+		"app-reader": readAppPolicy,
+		"__":         readAppPolicy,
+	})
+
+	var out, errOut bytes.Buffer
+	err := exportPolicies(context.Background(), baoClient, exportOptions{}, &out, &errOut)
+	assert.EqualError(t, err, "2 of 3 policies were not exported")
+
+	// Имена сортируются: App_Reader занимает имя объекта app-reader первым
+	manifests := exportManifests(t, out.String())
+	require.Len(t, manifests, 1)
+	assert.Equal(t, "App_Reader", manifests[0].Spec.PolicyName)
+	assert.Contains(t, errOut.String(), "policy app-reader: object name app-reader is already used by policy App_Reader")
+	assert.Contains(t, errOut.String(), `policy __: cannot derive an object name from "__"`)
+}
+
+func TestExportPoliciesRejects(t *testing.T) {
+	managed := kubebaoiov1alpha1.PolicyOwnerPrefix + "default/apps/reader\n" + readAppPolicy
+	_, baoClient, _ := newPolicyOpenBao(t, map[string]string{
+		"managed":     managed,
+		"unsupported": readAppPolicy + "path \"secret/*\" {\n  capabilities = [\"read\"]\n  unknown = true\n}\n",
+		"invalid":     "path \"secret/*/app\" {\n  capabilities = [\"read\"]\n}\n",
+	})
+
+	var out, errOut bytes.Buffer
+	err := exportPolicies(context.Background(), baoClient,
+		exportOptions{policies: []string{"managed", "unsupported", "invalid", "root", "missing"}}, &out, &errOut)
+	assert.EqualError(t, err, "5 of 5 policies were not exported")
+	assert.Empty(t, out.String())
+
+	assert.Contains(t, errOut.String(), "policy managed: already managed by kubebao ("+strings.TrimSpace(kubebaoiov1alpha1.PolicyOwnerPrefix)+" default/apps/reader)")
+	assert.Contains(t, errOut.String(), `policy unsupported: line 6: path "secret/*": unsupported key "unknown"`)
+	assert.Contains(t, errOut.String(), "policy invalid: spec.rules[0].path")
+	assert.Contains(t, errOut.String(), "policy root: the root policy cannot be exported")
+	assert.Contains(t, errOut.String(), "policy missing: ")
+}
+
+func TestExportPoliciesAdopt(t *testing.T) {
+	bao, baoClient, _ := newPolicyOpenBao(t, map[string]string{
+		"apps_reader": readAppPolicy,
+		"apps_broken": "path \"secret/*\" {\n  unknown = true\n}\n",
+	})
+
+	var out, errOut bytes.Buffer
+	err := exportPolicies(context.Background(), baoClient,
+		exportOptions{namespace: "apps", adopt: true, clusterName: "prod"}, &out, &errOut)
+	assert.EqualError(t, err, "1 of 2 policies were not exported")
+
+	// Маркер — первая строка, остальное HCL без изменений
+	assert.Equal(t, kubebaoiov1alpha1.PolicyOwnerPrefix+"prod/apps/reader\n"+readAppPolicy, bao.policies["apps_reader"])
+	// Неэкспортированная политика не помечается
+	assert.Equal(t, "path \"secret/*\" {\n  unknown = true\n}\n", bao.policies["apps_broken"])
+
+	// Повторный экспорт помеченной политики отклоняется
+	out.Reset()
+	errOut.Reset()
+	err = exportPolicies(context.Background(), baoClient, exportOptions{namespace: "apps", policies: []string{"apps_reader"}}, &out, &errOut)
+	assert.Error(t, err)
+	assert.Contains(t, errOut.String(), "already managed by kubebao")
+}
+
+func TestRunExportPoliciesExitCode(t *testing.T) {
+	_, _, address := newPolicyOpenBao(t, map[string]string{
+		"reader":      readAppPolicy,
+		"unsupported": "path \"secret/*\" {\n  capabilities = [\"read\"]\n  unknown = true\n}\n",
+	})
+	t.Setenv("OPENBAO_ADDR", address)
+	t.Setenv("OPENBAO_TOKEN", "test-token")
+
+	assert.Equal(t, 0, runExportPolicies([]string{"--policy", "reader"}))
+	assert.Equal(t, 1, runExportPolicies([]string{"--policy", "reader,unsupported"}))
+	assert.Equal(t, 2, runExportPolicies([]string{"--unknown-flag"}))
+}
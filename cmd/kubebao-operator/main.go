@@ -2,8 +2,11 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
@@ -13,10 +16,14 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	kubebaoiov1beta1 "github.com/kubebao/kubebao/api/v1beta1"
 	"github.com/kubebao/kubebao/internal/controller"
 	"github.com/kubebao/kubebao/internal/openbao"
+	"github.com/kubebao/kubebao/internal/webhook/certs"
+	webhookv1alpha1 "github.com/kubebao/kubebao/internal/webhook/v1alpha1"
 
 	"github.com/hashicorp/go-hclog"
 )
@@ -32,15 +39,36 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(kubebaoiov1alpha1.AddToScheme(scheme))
+	utilruntime.Must(kubebaoiov1beta1.AddToScheme(scheme))
 }
 
 func main() {
+	// Подкоманда export-policies: манифесты из существующих политик OpenBao, менеджер не запускается
+	if len(os.Args) > 1 && os.Args[1] == "export-policies" {
+		os.Exit(runExportPolicies(os.Args[2:]))
+	}
+
 	var (
 		metricsAddr          string
 		enableLeaderElection bool
 		probeAddr            string
 		logLevel             string
 		configFile           string
+		clusterName          string
+		enableEvents         bool
+		eventResyncInterval  time.Duration
+		enableWebhooks       bool
+		webhookPort          int
+		webhookCertDir       string
+		webhookCertBootstrap bool
+		webhookService       string
+		webhookNamespace     string
+		webhookCertSecret    string
+		webhookConfiguration string
+		legacyPolicyNames    bool
+		pushOperatorIdentity bool
+		tenantAudience       string
+		allowedAddresses     string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -50,6 +78,34 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
 	flag.StringVar(&configFile, "config", "", "Path to OpenBao configuration file")
+	flag.StringVar(&clusterName, "cluster-name", "default",
+		"Cluster name in the ownership marker of secrets pushed by BaoPushSecret and policies written by BaoPolicy")
+	flag.BoolVar(&enableEvents, "openbao-events", false,
+		"Subscribe to OpenBao kv-v2/data-write events and resync affected BaoSecrets immediately.")
+	flag.DurationVar(&eventResyncInterval, "event-resync-interval", controller.DefaultEventResyncInterval,
+		"KV polling interval for BaoSecrets while the OpenBao event subscription is active.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve validating and defaulting admission webhooks and the v1beta1 conversion webhook for kubebao resources.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server binds to.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs",
+		"Directory with tls.crt and tls.key of the webhook server.")
+	flag.BoolVar(&webhookCertBootstrap, "webhook-cert-bootstrap", false,
+		"Issue the webhook serving certificate, keep it in --webhook-cert-secret, write it to --webhook-cert-dir and inject the CA into the webhook configurations.")
+	flag.StringVar(&webhookService, "webhook-service-name", "kubebao-operator-webhook",
+		"Service of the webhook server, used in the certificate DNS names with --webhook-cert-bootstrap.")
+	flag.StringVar(&webhookNamespace, "webhook-namespace", os.Getenv("POD_NAMESPACE"),
+		"Namespace of the webhook Service and certificate Secret (defaults to $POD_NAMESPACE).")
+	flag.StringVar(&webhookCertSecret, "webhook-cert-secret", "kubebao-operator-webhook-tls",
+		"Secret holding the webhook CA and serving certificate with --webhook-cert-bootstrap.")
+	flag.StringVar(&webhookConfiguration, "webhook-configuration-name", "kubebao-operator-webhook",
+		"Name of the ValidatingWebhookConfiguration and MutatingWebhookConfiguration that receive the CA bundle.")
+	flag.BoolVar(&legacyPolicyNames, "legacy-policy-names", false,
+		"Write BaoPolicy policies without the <namespace>_ prefix, as earlier versions did. Names may collide across namespaces.")
+	flag.BoolVar(&pushOperatorIdentity, "push-secret-operator-identity", false,
+		"Let BaoPushSecrets without openbaoRef or roleName write to OpenBao with the operator token. Anyone who can create a BaoPushSecret can then write to every KV path the operator can.")
+	flag.StringVar(&tenantAudience, "tenant-token-audience", controller.DefaultTenantAudience,
+		"Audience of the ServiceAccount tokens the operator requests for openbaoRef and roleName logins. Tenant OpenBao roles must require it (BaoRole audience or boundAudiences).")
+	flag.StringVar(&allowedAddresses, "openbao-allowed-addresses", "",
+		"Comma-separated OpenBao addresses allowed in openbaoRef.address in addition to the operator's own. Tenant ServiceAccount tokens are sent only to these servers.")
 	flag.Parse()
 
 	// Setup logger
@@ -94,12 +150,12 @@ func main() {
 	})
 
 	var baoClient *openbao.Client
-	if baoConfig.Address != "" {
+	if len(baoConfig.AllAddresses()) > 0 {
 		baoClient, err = openbao.NewClient(baoConfig, hcLogger)
 		if err != nil {
 			setupLog.Error(err, "Ошибка создания клиента OpenBao (контроллеры будут работать без подключения)")
 		} else {
-			setupLog.Info("Успешное подключение к OpenBao", "address", baoConfig.Address)
+			setupLog.Info("Успешное подключение к OpenBao", "address", baoClient.Address())
 		}
 	} else {
 		setupLog.Info("Адрес OpenBao не задан, инициализация клиента пропущена")
@@ -111,6 +167,10 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "kubebao-operator.kubebao.io",
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		}),
 	})
 	if err != nil {
 		setupLog.Error(err, "Ошибка создания менеджера контроллеров")
@@ -118,12 +178,56 @@ func main() {
 	}
 	setupLog.Info("Менеджер контроллеров создан")
 
+	// Клиенты OpenBao для BaoSecret с собственным openbaoRef/roleName — вход под ServiceAccount tenant'а
+	var extraAddresses []string
+	for _, address := range strings.Split(allowedAddresses, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			extraAddresses = append(extraAddresses, address)
+		}
+	}
+	openBaoClients := controller.NewOpenBaoClients(mgr.GetClient(), baoConfig, controller.OpenBaoClientsOptions{
+		Audience:         tenantAudience,
+		AllowedAddresses: extraAddresses,
+	}, hcLogger)
+
+	// Менеджер аренд динамических секретов: продление и события истечения. Аренды tenant'ов
+	// продлеваются их же клиентами, поэтому общий клиент оператора не обязателен.
+	var leaseClient openbao.LeaseClient
+	if baoClient != nil {
+		leaseClient = baoClient
+	}
+	leaseManager := openbao.NewLeaseManager(leaseClient, hcLogger.Named("leases"))
+	if err := mgr.Add(leaseManager); err != nil {
+		setupLog.Error(err, "Ошибка регистрации менеджера аренд")
+		os.Exit(1)
+	}
+
+	// Подписка на записи в KV: BaoSecret синхронизируется сразу после записи, а не по таймеру.
+	// При разрыве подписки контроллер возвращается к опросу по refreshInterval.
+	var eventWatcher *openbao.EventWatcher
+	if enableEvents {
+		if baoClient != nil {
+			eventWatcher = openbao.NewEventWatcher(baoClient, openbao.KVDataWriteEvent, hcLogger.Named("events"))
+			if err := mgr.Add(eventWatcher); err != nil {
+				setupLog.Error(err, "Ошибка регистрации подписки на события OpenBao")
+				os.Exit(1)
+			}
+		} else {
+			setupLog.Info("Подписка на события OpenBao недоступна без клиента OpenBao, используется опрос")
+		}
+	}
+
 	// Регистрация контроллера BaoSecret
 	if err := (&controller.BaoSecretReconciler{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		Log:           ctrl.Log.WithName("controllers").WithName("BaoSecret"),
-		OpenBaoClient: baoClient,
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("BaoSecret"),
+		OpenBaoClient:       baoClient,
+		OpenBaoClients:      openBaoClients,
+		LeaseManager:        leaseManager,
+		EventWatcher:        eventWatcher,
+		EventResyncInterval: eventResyncInterval,
+		Recorder:            mgr.GetEventRecorderFor("kubebao-operator"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Ошибка регистрации контроллера BaoSecret")
 		os.Exit(1)
@@ -132,15 +236,112 @@ func main() {
 
 	// Регистрация контроллера BaoPolicy
 	if err := (&controller.BaoPolicyReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Log:               ctrl.Log.WithName("controllers").WithName("BaoPolicy"),
+		OpenBaoClient:     baoClient,
+		Recorder:          mgr.GetEventRecorderFor("kubebao-operator"),
+		ClusterName:       clusterName,
+		LegacyPolicyNames: legacyPolicyNames,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Ошибка регистрации контроллера BaoPolicy")
+		os.Exit(1)
+	}
+	setupLog.Info("Контроллер BaoPolicy зарегистрирован")
+
+	// Регистрация контроллера ClusterBaoPolicy
+	if err := (&controller.ClusterBaoPolicyReconciler{
+		BaoPolicyReconciler: controller.BaoPolicyReconciler{
+			Client:        mgr.GetClient(),
+			Scheme:        mgr.GetScheme(),
+			Log:           ctrl.Log.WithName("controllers").WithName("ClusterBaoPolicy"),
+			OpenBaoClient: baoClient,
+			Recorder:      mgr.GetEventRecorderFor("kubebao-operator"),
+			ClusterName:   clusterName,
+		},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Ошибка регистрации контроллера ClusterBaoPolicy")
+		os.Exit(1)
+	}
+	setupLog.Info("Контроллер ClusterBaoPolicy зарегистрирован")
+
+	// Регистрация контроллера BaoRole
+	if err := (&controller.BaoRoleReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
-		Log:           ctrl.Log.WithName("controllers").WithName("BaoPolicy"),
+		Log:           ctrl.Log.WithName("controllers").WithName("BaoRole"),
 		OpenBaoClient: baoClient,
+		Recorder:      mgr.GetEventRecorderFor("kubebao-operator"),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "Ошибка регистрации контроллера BaoPolicy")
+		setupLog.Error(err, "Ошибка регистрации контроллера BaoRole")
 		os.Exit(1)
 	}
-	setupLog.Info("Контроллер BaoPolicy зарегистрирован")
+	setupLog.Info("Контроллер BaoRole зарегистрирован")
+
+	// Регистрация контроллера BaoPushSecret
+	if err := (&controller.BaoPushSecretReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("BaoPushSecret"),
+		OpenBaoClient:         baoClient,
+		OpenBaoClients:        openBaoClients,
+		ClusterName:           clusterName,
+		Recorder:              mgr.GetEventRecorderFor("kubebao-operator"),
+		AllowOperatorIdentity: pushOperatorIdentity,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "Ошибка регистрации контроллера BaoPushSecret")
+		os.Exit(1)
+	}
+	setupLog.Info("Контроллер BaoPushSecret зарегистрирован")
+
+	// Admission webhooks: значения по умолчанию, а недопустимые spec и занятые имена политик отклоняются при apply, а не в reconcile;
+	// conversion webhook обслуживает v1beta1
+	if enableWebhooks {
+		// Сертификат выпускается до запуска менеджера: webhook-сервер не стартует без tls.crt и tls.key
+		if webhookCertBootstrap {
+			if webhookNamespace == "" {
+				setupLog.Error(nil, "Для --webhook-cert-bootstrap нужен --webhook-namespace или POD_NAMESPACE")
+				os.Exit(1)
+			}
+			bootstrapper := certs.NewBootstrapper(mgr.GetClient(), mgr.GetAPIReader(), certs.Options{
+				SecretName:        webhookCertSecret,
+				Namespace:         webhookNamespace,
+				ServiceName:       webhookService,
+				CertDir:           webhookCertDir,
+				ConfigurationName: webhookConfiguration,
+				CRDNames:          []string{"baosecrets.kubebao.io", "baopushsecrets.kubebao.io"},
+			}, ctrl.Log.WithName("webhook-certs"))
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			err := bootstrapper.Ensure(ctx)
+			cancel()
+			if err != nil {
+				setupLog.Error(err, "Ошибка выпуска сертификата webhook")
+				os.Exit(1)
+			}
+			if err := mgr.Add(bootstrapper); err != nil {
+				setupLog.Error(err, "Ошибка регистрации проверки сертификата webhook")
+				os.Exit(1)
+			}
+			setupLog.Info("Сертификат webhook готов", "secret", webhookCertSecret, "service", webhookService)
+		}
+
+		if err := webhookv1alpha1.SetupBaoSecretWebhookWithManager(mgr, openBaoClients.AllowedAddresses()); err != nil {
+			setupLog.Error(err, "Ошибка регистрации webhook BaoSecret")
+			os.Exit(1)
+		}
+		setupLog.Info("Webhook BaoSecret зарегистрирован")
+		if err := webhookv1alpha1.SetupBaoPolicyWebhookWithManager(mgr, legacyPolicyNames); err != nil {
+			setupLog.Error(err, "Ошибка регистрации webhook BaoPolicy")
+			os.Exit(1)
+		}
+		setupLog.Info("Webhook BaoPolicy и ClusterBaoPolicy зарегистрирован")
+		// BaoSecret и BaoPushSecret v1beta1 хранятся как v1alpha1: /convert регистрируется вместе с их webhooks
+		if err := webhookv1alpha1.SetupBaoPushSecretWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Ошибка регистрации conversion webhook BaoPushSecret")
+			os.Exit(1)
+		}
+		setupLog.Info("Conversion webhook BaoSecret и BaoPushSecret зарегистрирован")
+	}
 
 	// Настройка проверок здоровья
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,364 @@
+// Разбор HCL (или JSON) ACL-политики OpenBao в правила BaoPolicy — обратное к BaoPolicySpec.ToHCL.
+package acl
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// UnsupportedError — конструкция политики, которую PolicyRule не выражает: импорт такой политики
+// потерял бы права или ограничения, поэтому она не импортируется.
+type UnsupportedError struct {
+	// Line — строка конструкции в HCL
+	Line int
+	// Path — путь правила; "" для конструкций вне правил
+	Path string
+	// Construct — описание конструкции
+	Construct string
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d: unsupported %s", e.Line, e.Construct)
+	}
+	return fmt.Sprintf("line %d: path %q: unsupported %s", e.Line, e.Path, e.Construct)
+}
+
+// legacyCapabilities — capabilities устаревшего поля policy = "read" | "write" | "deny" | "sudo".
+var legacyCapabilities = map[string][]kubebaoiov1alpha1.Capability{
+	"deny": {kubebaoiov1alpha1.CapabilityDeny},
+	"read": {kubebaoiov1alpha1.CapabilityRead, kubebaoiov1alpha1.CapabilityList},
+	"write": {kubebaoiov1alpha1.CapabilityCreate, kubebaoiov1alpha1.CapabilityRead, kubebaoiov1alpha1.CapabilityUpdate,
+		kubebaoiov1alpha1.CapabilityDelete, kubebaoiov1alpha1.CapabilityList},
+	"sudo": {kubebaoiov1alpha1.CapabilityCreate, kubebaoiov1alpha1.CapabilityRead, kubebaoiov1alpha1.CapabilityUpdate,
+		kubebaoiov1alpha1.CapabilityDelete, kubebaoiov1alpha1.CapabilityList, kubebaoiov1alpha1.CapabilitySudo},
+}
+
+// Parse разбирает политику OpenBao в правила в порядке блоков path. Комментарии (в том числе
+// маркер владельца kubebao) пропускаются; конструкции, которые ToHCL не может записать,
+// возвращаются как *UnsupportedError.
+func Parse(policy string) ([]kubebaoiov1alpha1.PolicyRule, error) {
+	file, err := hcl.ParseString(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	root, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse policy: unexpected root node %T", file.Node)
+	}
+
+	var rules []kubebaoiov1alpha1.PolicyRule
+	for _, item := range root.Items {
+		switch key := itemKey(item); key {
+		case "name":
+			// Имя политики в теле игнорируется OpenBao
+		case "path":
+			blocks, err := labeledBlocks(item, "", "path")
+			if err != nil {
+				return nil, err
+			}
+			for _, block := range blocks {
+				rule, err := parseRule(block.label, block.body)
+				if err != nil {
+					return nil, err
+				}
+				rules = append(rules, rule)
+			}
+		default:
+			return nil, &UnsupportedError{Line: item.Pos().Line, Construct: fmt.Sprintf("top-level key %q", key)}
+		}
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("policy has no path rules")
+	}
+	return rules, nil
+}
+
+// labeledBlock — блок вида `name "label" { ... }` или `name = { "label" = { ... } }`.
+type labeledBlock struct {
+	label string
+	body  *ast.ObjectList
+}
+
+// labeledBlocks — блоки элемента item с меткой: в HCL метка — второй ключ, в JSON — ключ
+// вложенного объекта.
+func labeledBlocks(item *ast.ObjectItem, rulePath, what string) ([]labeledBlock, error) {
+	unsupported := &UnsupportedError{Line: item.Pos().Line, Path: rulePath, Construct: fmt.Sprintf("%s block syntax", what)}
+
+	switch len(item.Keys) {
+	case 2:
+		body, ok := item.Val.(*ast.ObjectType)
+		if !ok {
+			return nil, unsupported
+		}
+		return []labeledBlock{{label: keyText(item.Keys[1]), body: body.List}}, nil
+	case 1:
+		outer, ok := item.Val.(*ast.ObjectType)
+		if !ok {
+			return nil, unsupported
+		}
+		blocks := make([]labeledBlock, 0, len(outer.List.Items))
+		for _, inner := range outer.List.Items {
+			body, ok := inner.Val.(*ast.ObjectType)
+			if len(inner.Keys) != 1 || !ok {
+				return nil, unsupported
+			}
+			blocks = append(blocks, labeledBlock{label: keyText(inner.Keys[0]), body: body.List})
+		}
+		return blocks, nil
+	}
+	return nil, unsupported
+}
+
+// parseRule — PolicyRule из тела блока path.
+func parseRule(path string, body *ast.ObjectList) (kubebaoiov1alpha1.PolicyRule, error) {
+	rule := kubebaoiov1alpha1.PolicyRule{Path: path}
+	seen := make(map[string]bool, len(body.Items))
+
+	for _, item := range body.Items {
+		key := itemKey(item)
+		line := item.Pos().Line
+		if len(item.Keys) != 1 {
+			return rule, &UnsupportedError{Line: line, Path: path, Construct: fmt.Sprintf("block %q", key)}
+		}
+		if seen[key] {
+			return rule, &UnsupportedError{Line: line, Path: path, Construct: fmt.Sprintf("duplicate key %q", key)}
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "capabilities":
+			var values []string
+			if values, err = stringList(item.Val, path, key); err == nil {
+				for _, v := range values {
+					rule.Capabilities = appendCapability(rule.Capabilities, kubebaoiov1alpha1.Capability(v))
+				}
+			}
+		case "policy":
+			var value string
+			if value, err = stringValue(item.Val, path, key); err == nil {
+				capabilities, ok := legacyCapabilities[value]
+				if !ok {
+					return rule, &UnsupportedError{Line: line, Path: path, Construct: fmt.Sprintf("policy = %q", value)}
+				}
+				for _, c := range capabilities {
+					rule.Capabilities = appendCapability(rule.Capabilities, c)
+				}
+			}
+		case "allowed_parameters":
+			rule.AllowedParameters, err = parameterMap(item.Val, path, key)
+		case "denied_parameters":
+			var denied map[string][]string
+			if denied, err = parameterMap(item.Val, path, key); err == nil {
+				rule.DeniedParameters, rule.DeniedParameterValues = splitDenied(denied)
+			}
+		case "required_parameters":
+			rule.RequiredParameters, err = stringList(item.Val, path, key)
+		case "min_wrapping_ttl":
+			rule.MinWrappingTTL, err = stringValue(item.Val, path, key)
+		case "max_wrapping_ttl":
+			rule.MaxWrappingTTL, err = stringValue(item.Val, path, key)
+		case "subscribe_event_types":
+			rule.SubscribeEventTypes, err = stringList(item.Val, path, key)
+		case "control_group":
+			rule.ControlGroup, err = parseControlGroup(item, path)
+		default:
+			return rule, &UnsupportedError{Line: line, Path: path, Construct: fmt.Sprintf("key %q", key)}
+		}
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	for _, capability := range rule.Capabilities {
+		if !knownCapabilities[capability] {
+			return rule, &UnsupportedError{Line: body.Pos().Line, Path: path, Construct: fmt.Sprintf("capability %q", capability)}
+		}
+	}
+	if len(rule.Capabilities) == 0 {
+		return rule, fmt.Errorf("path %q has no capabilities", path)
+	}
+	return rule, nil
+}
+
+// parseControlGroup — control_group = { ttl = "..." factor "name" { identity { ... } } }.
+func parseControlGroup(item *ast.ObjectItem, path string) (*kubebaoiov1alpha1.ControlGroup, error) {
+	body, ok := item.Val.(*ast.ObjectType)
+	if !ok || len(item.Keys) != 1 {
+		return nil, &UnsupportedError{Line: item.Pos().Line, Path: path, Construct: "control_group syntax"}
+	}
+
+	cg := &kubebaoiov1alpha1.ControlGroup{}
+	for _, inner := range body.List.Items {
+		key := itemKey(inner)
+		switch key {
+		case "ttl":
+			ttl, err := stringValue(inner.Val, path, "control_group.ttl")
+			if err != nil {
+				return nil, err
+			}
+			cg.TTL = ttl
+		case "factor":
+			blocks, err := labeledBlocks(inner, path, "factor")
+			if err != nil {
+				return nil, err
+			}
+			for _, block := range blocks {
+				factor, err := parseFactor(block, path)
+				if err != nil {
+					return nil, err
+				}
+				cg.Factors = append(cg.Factors, factor)
+			}
+		default:
+			return nil, &UnsupportedError{Line: inner.Pos().Line, Path: path, Construct: fmt.Sprintf("control_group key %q", key)}
+		}
+	}
+	return cg, nil
+}
+
+// parseFactor — фактор control group; поддерживаются только одобрения по именам групп.
+func parseFactor(block labeledBlock, path string) (kubebaoiov1alpha1.ControlGroupFactor, error) {
+	factor := kubebaoiov1alpha1.ControlGroupFactor{Name: block.label, Approvals: 1}
+
+	for _, item := range block.body.Items {
+		key := itemKey(item)
+		switch key {
+		case "controlled_capabilities":
+			values, err := stringList(item.Val, path, key)
+			if err != nil {
+				return factor, err
+			}
+			for _, v := range values {
+				factor.ControlledCapabilities = append(factor.ControlledCapabilities, kubebaoiov1alpha1.Capability(v))
+			}
+		case "identity":
+			identity, ok := item.Val.(*ast.ObjectType)
+			if !ok {
+				return factor, &UnsupportedError{Line: item.Pos().Line, Path: path, Construct: "factor identity syntax"}
+			}
+			for _, field := range identity.List.Items {
+				var err error
+				switch name := itemKey(field); name {
+				case "group_names":
+					factor.GroupNames, err = stringList(field.Val, path, name)
+				case "approvals":
+					var approvals string
+					if approvals, err = stringValue(field.Val, path, name); err == nil {
+						var n int
+						if n, err = strconv.Atoi(approvals); err != nil {
+							err = &UnsupportedError{Line: field.Pos().Line, Path: path, Construct: fmt.Sprintf("approvals = %q", approvals)}
+						}
+						factor.Approvals = int32(n)
+					}
+				default:
+					err = &UnsupportedError{Line: field.Pos().Line, Path: path, Construct: fmt.Sprintf("factor identity key %q", name)}
+				}
+				if err != nil {
+					return factor, err
+				}
+			}
+		default:
+			return factor, &UnsupportedError{Line: item.Pos().Line, Path: path, Construct: fmt.Sprintf("factor key %q", key)}
+		}
+	}
+	return factor, nil
+}
+
+// parameterMap — allowed_parameters или denied_parameters: ключ → список строковых значений.
+// Значения других типов ToHCL записал бы строками, что меняет их сравнение в OpenBao.
+func parameterMap(node ast.Node, path, name string) (map[string][]string, error) {
+	object, ok := node.(*ast.ObjectType)
+	if !ok {
+		return nil, &UnsupportedError{Line: node.Pos().Line, Path: path, Construct: fmt.Sprintf("%s value", name)}
+	}
+	parameters := make(map[string][]string, len(object.List.Items))
+	for _, item := range object.List.Items {
+		key := itemKey(item)
+		values, err := stringList(item.Val, path, name+"."+key)
+		if err != nil {
+			return nil, err
+		}
+		parameters[key] = values
+	}
+	return parameters, nil
+}
+
+// splitDenied — ключи с пустым списком запрещают любое значение и попадают в deniedParameters.
+func splitDenied(denied map[string][]string) ([]string, map[string][]string) {
+	var keys []string
+	var values map[string][]string
+	for key, v := range denied {
+		if len(v) == 0 {
+			keys = append(keys, key)
+			continue
+		}
+		if values == nil {
+			values = make(map[string][]string)
+		}
+		values[key] = v
+	}
+	slices.Sort(keys)
+	return keys, values
+}
+
+// stringList — список строк; пустой список возвращается как []string{}, не nil.
+func stringList(node ast.Node, path, name string) ([]string, error) {
+	list, ok := node.(*ast.ListType)
+	if !ok {
+		return nil, &UnsupportedError{Line: node.Pos().Line, Path: path, Construct: fmt.Sprintf("%s value, expected a list", name)}
+	}
+	values := make([]string, 0, len(list.List))
+	for _, element := range list.List {
+		literal, ok := element.(*ast.LiteralType)
+		if !ok || (literal.Token.Type != token.STRING && literal.Token.Type != token.HEREDOC) {
+			return nil, &UnsupportedError{Line: element.Pos().Line, Path: path, Construct: fmt.Sprintf("non-string value in %s", name)}
+		}
+		values = append(values, literal.Token.Value().(string))
+	}
+	return values, nil
+}
+
+// stringValue — строка или целое число (TTL в секундах записываются числом).
+func stringValue(node ast.Node, path, name string) (string, error) {
+	literal, ok := node.(*ast.LiteralType)
+	if ok {
+		switch literal.Token.Type {
+		case token.STRING, token.HEREDOC:
+			return literal.Token.Value().(string), nil
+		case token.NUMBER:
+			return literal.Token.Text, nil
+		}
+	}
+	return "", &UnsupportedError{Line: node.Pos().Line, Path: path, Construct: fmt.Sprintf("%s value", name)}
+}
+
+func appendCapability(capabilities []kubebaoiov1alpha1.Capability, c kubebaoiov1alpha1.Capability) []kubebaoiov1alpha1.Capability {
+	if slices.Contains(capabilities, c) {
+		return capabilities
+	}
+	return append(capabilities, c)
+}
+
+func itemKey(item *ast.ObjectItem) string {
+	if len(item.Keys) == 0 {
+		return ""
+	}
+	return keyText(item.Keys[0])
+}
+
+// keyText — ключ без кавычек.
+func keyText(key *ast.ObjectKey) string {
+	if v, ok := key.Token.Value().(string); ok {
+		return v
+	}
+	return key.Token.Text
+}
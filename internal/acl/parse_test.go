@@ -0,0 +1,121 @@
+// Тесты разбора ACL-политик OpenBao.
+package acl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	spec := kubebaoiov1alpha1.BaoPolicySpec{
+		Rules: []kubebaoiov1alpha1.PolicyRule{
+			{
+				Path:         "secret/data/{{identity.entity.name}}/*",
+				Capabilities: []kubebaoiov1alpha1.Capability{"create", "read", "update"},
+				AllowedParameters: map[string][]string{
+					"*":    {},
+					"role": {"reader", "writer"},
+				},
+				DeniedParameters:      []string{"admin"},
+				DeniedParameterValues: map[string][]string{"env": {"prod"}},
+				RequiredParameters:    []string{"owner"},
+				MinWrappingTTL:        "1m",
+				MaxWrappingTTL:        "1h",
+			},
+			{
+				Path:                "sys/events/subscribe/kv*",
+				Capabilities:        []kubebaoiov1alpha1.Capability{"read", "subscribe"},
+				SubscribeEventTypes: []string{"kv*"},
+			},
+			{
+				Path:         "secret/data/prod/+/config",
+				Capabilities: []kubebaoiov1alpha1.Capability{"read"},
+				ControlGroup: &kubebaoiov1alpha1.ControlGroup{
+					TTL: "4h",
+					Factors: []kubebaoiov1alpha1.ControlGroupFactor{
+						{Name: "managers", GroupNames: []string{"managers"}, Approvals: 2},
+						{Name: "security", GroupNames: []string{"security"}, Approvals: 1,
+							ControlledCapabilities: []kubebaoiov1alpha1.Capability{"read"}},
+					},
+				},
+			},
+			{
+				Path:         "secret/data/quote\"d",
+				Capabilities: []kubebaoiov1alpha1.Capability{"deny"},
+			},
+		},
+	}
+
+	rules, err := Parse(spec.ToHCL())
+	require.NoError(t, err)
+	assert.Equal(t, spec.Rules, rules)
+
+	// Повторная запись разобранных правил даёт тот же HCL
+	parsed := kubebaoiov1alpha1.BaoPolicySpec{Rules: rules}
+	assert.Equal(t, spec.ToHCL(), parsed.ToHCL())
+}
+
+func TestParseSkipsOwnerMarker(t *testing.T) {
+	policy := kubebaoiov1alpha1.PolicyOwnerPrefix + "cluster/default/app\n" +
+		"path \"secret/*\" {\n  capabilities = [\"read\"]\n}\n"
+
+	rules, err := Parse(policy)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "secret/*", rules[0].Path)
+}
+
+func TestParseLegacyPolicyAndNumericTTL(t *testing.T) {
+	rules, err := Parse(`
+path "secret/*" {
+  policy = "read"
+  capabilities = ["read", "update"]
+  max_wrapping_ttl = 300
+}`)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, []kubebaoiov1alpha1.Capability{"read", "list", "update"}, rules[0].Capabilities)
+	assert.Equal(t, "300", rules[0].MaxWrappingTTL)
+}
+
+func TestParseJSON(t *testing.T) {
+	rules, err := Parse(`{"path": {"secret/*": {"capabilities": ["read"]}, "auth/token/lookup-self": {"capabilities": ["read"]}}}`)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "secret/*", rules[0].Path)
+	assert.Equal(t, "auth/token/lookup-self", rules[1].Path)
+}
+
+func TestParseUnsupported(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":        `path "a" { capabilities = ["read"] mfa_methods = ["totp"] }`,
+		"unknown capability": `path "a" { capabilities = ["scan"] }`,
+		"non-string value":   `path "a" { capabilities = ["read"] allowed_parameters = { "ttl" = [300] } }`,
+		"group ids":          `path "a" { capabilities = ["read"] control_group = { factor "f" { identity { group_ids = ["x"] } } } }`,
+		"top-level key":      `path "a" { capabilities = ["read"] } mount "x" {}`,
+		"legacy policy":      `path "a" { policy = "admin" }`,
+	}
+	for name, policy := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(policy)
+			var unsupported *UnsupportedError
+			assert.True(t, errors.As(err, &unsupported), "expected UnsupportedError, got %v", err)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse(`path "a" {`)
+	assert.Error(t, err)
+
+	_, err = Parse(`# empty`)
+	assert.Error(t, err)
+
+	_, err = Parse(`path "a" { required_parameters = ["x"] }`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+// Package acl — грамматика ACL-политик OpenBao: проверка правил BaoPolicy (glob-пути, шаблоны
+// identity, параметры, control groups) и разбор HCL существующих политик в PolicyRule.
+package acl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// Шаблоны identity для путей политики: OpenBao подставляет значения сущности запрашивающего токена.
+const (
+	// IdentityEntityID — ID сущности токена
+	IdentityEntityID = "{{identity.entity.id}}"
+	// IdentityEntityName — имя сущности токена
+	IdentityEntityName = "{{identity.entity.name}}"
+)
+
+// IdentityEntityMetadata — шаблон значения метаданных сущности по ключу.
+func IdentityEntityMetadata(key string) string {
+	return "{{identity.entity.metadata." + key + "}}"
+}
+
+// IdentityAliasName — шаблон имени алиаса сущности в auth-методе с данным accessor
+// (например, имя service account для kubernetes auth).
+func IdentityAliasName(mountAccessor string) string {
+	return "{{identity.entity.aliases." + mountAccessor + ".name}}"
+}
+
+// identityTemplate — выражения внутри {{...}}, которые OpenBao подставляет в путь ACL-политики.
+var identityTemplate = regexp.MustCompile(`^identity\.(` +
+	`entity\.(id|name|metadata\.[^{}\s]+|aliases\.[^.{}\s]+\.(id|name|metadata\.[^{}\s]+|custom_metadata\.[^{}\s]+))` +
+	`|groups\.(ids\.[^.{}\s]+\.(name|metadata\.[^{}\s]+)|names\.[^.{}\s]+\.(id|metadata\.[^{}\s]+))` +
+	`)$`)
+
+// knownCapabilities — capabilities, допустимые в PolicyRule.
+var knownCapabilities = map[kubebaoiov1alpha1.Capability]bool{
+	kubebaoiov1alpha1.CapabilityCreate:    true,
+	kubebaoiov1alpha1.CapabilityRead:      true,
+	kubebaoiov1alpha1.CapabilityUpdate:    true,
+	kubebaoiov1alpha1.CapabilityDelete:    true,
+	kubebaoiov1alpha1.CapabilityList:      true,
+	kubebaoiov1alpha1.CapabilitySudo:      true,
+	kubebaoiov1alpha1.CapabilityDeny:      true,
+	kubebaoiov1alpha1.CapabilityPatch:     true,
+	kubebaoiov1alpha1.CapabilitySubscribe: true,
+}
+
+// ValidateRules — ошибки правил, которые OpenBao отклонил бы или понял бы не так, как задумано.
+func ValidateRules(rules []kubebaoiov1alpha1.PolicyRule, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, rule := range rules {
+		errs = append(errs, validateRule(rule, fldPath.Index(i))...)
+	}
+	return errs
+}
+
+func validateRule(rule kubebaoiov1alpha1.PolicyRule, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if err := ValidatePath(rule.Path); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("path"), rule.Path, err.Error()))
+	}
+
+	for i, capability := range rule.Capabilities {
+		if !knownCapabilities[capability] {
+			errs = append(errs, field.NotSupported(fldPath.Child("capabilities").Index(i), capability, capabilityNames()))
+		}
+	}
+
+	for key := range rule.AllowedParameters {
+		if key == "" {
+			errs = append(errs, field.Invalid(fldPath.Child("allowedParameters"), key, "parameter name must not be empty"))
+		}
+	}
+	for i, key := range rule.DeniedParameters {
+		if key == "" {
+			errs = append(errs, field.Invalid(fldPath.Child("deniedParameters").Index(i), key, "parameter name must not be empty"))
+		}
+	}
+	for key := range rule.DeniedParameterValues {
+		if key == "" {
+			errs = append(errs, field.Invalid(fldPath.Child("deniedParameterValues"), key, "parameter name must not be empty"))
+		}
+	}
+	for i, key := range rule.RequiredParameters {
+		if key == "" {
+			errs = append(errs, field.Invalid(fldPath.Child("requiredParameters").Index(i), key, "parameter name must not be empty"))
+		}
+	}
+
+	var minTTL, maxTTL time.Duration
+	if rule.MinWrappingTTL != "" {
+		d, err := ParseTTL(rule.MinWrappingTTL)
+		if err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("minWrappingTTL"), rule.MinWrappingTTL, err.Error()))
+		}
+		minTTL = d
+	}
+	if rule.MaxWrappingTTL != "" {
+		d, err := ParseTTL(rule.MaxWrappingTTL)
+		if err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("maxWrappingTTL"), rule.MaxWrappingTTL, err.Error()))
+		}
+		maxTTL = d
+	}
+	if minTTL > 0 && maxTTL > 0 && minTTL > maxTTL {
+		errs = append(errs, field.Invalid(fldPath.Child("minWrappingTTL"), rule.MinWrappingTTL,
+			fmt.Sprintf("must not exceed maxWrappingTTL %s", rule.MaxWrappingTTL)))
+	}
+
+	for i, eventType := range rule.SubscribeEventTypes {
+		if eventType == "" {
+			errs = append(errs, field.Invalid(fldPath.Child("subscribeEventTypes").Index(i), eventType, "event type must not be empty"))
+		}
+	}
+
+	if rule.ControlGroup != nil {
+		errs = append(errs, validateControlGroup(rule.ControlGroup, fldPath.Child("controlGroup"))...)
+	}
+	return errs
+}
+
+func validateControlGroup(cg *kubebaoiov1alpha1.ControlGroup, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if cg.TTL != "" {
+		if _, err := ParseTTL(cg.TTL); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("ttl"), cg.TTL, err.Error()))
+		}
+	}
+	if len(cg.Factors) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("factors"), "at least one factor is required"))
+	}
+
+	names := make(map[string]bool, len(cg.Factors))
+	for i, factor := range cg.Factors {
+		factorPath := fldPath.Child("factors").Index(i)
+		switch {
+		case factor.Name == "":
+			errs = append(errs, field.Required(factorPath.Child("name"), ""))
+		case names[factor.Name]:
+			errs = append(errs, field.Duplicate(factorPath.Child("name"), factor.Name))
+		}
+		names[factor.Name] = true
+
+		if len(factor.GroupNames) == 0 {
+			errs = append(errs, field.Required(factorPath.Child("groupNames"), "at least one group is required"))
+		}
+		if factor.Approvals < 0 {
+			errs = append(errs, field.Invalid(factorPath.Child("approvals"), factor.Approvals, "must be at least 1"))
+		}
+		for j, capability := range factor.ControlledCapabilities {
+			if !knownCapabilities[capability] {
+				errs = append(errs, field.NotSupported(factorPath.Child("controlledCapabilities").Index(j), capability, capabilityNames()))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidatePath проверяет путь правила: "*" допустим только в конце пути, "+" — только как целый
+// сегмент ("secret/+/config"), шаблоны {{...}} — только известные OpenBao выражения identity.
+func ValidatePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("path must not be empty")
+	}
+
+	// Шаблоны заменяются нейтральным сегментом: glob внутри них не проверяется
+	plain, err := stripTemplates(path)
+	if err != nil {
+		return err
+	}
+
+	if i := strings.Index(plain, "*"); i >= 0 && i != len(plain)-1 {
+		return fmt.Errorf("glob \"*\" is only supported at the end of the path")
+	}
+	for _, segment := range strings.Split(plain, "/") {
+		if strings.Contains(segment, "+") && segment != "+" {
+			return fmt.Errorf("wildcard \"+\" must be a whole path segment, got %q", segment)
+		}
+	}
+	return nil
+}
+
+// Templates — выражения шаблонов {{...}} пути без скобок.
+func Templates(path string) []string {
+	var templates []string
+	for rest := path; ; {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			return templates
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return templates
+		}
+		templates = append(templates, strings.TrimSpace(rest[start+2:start+end]))
+		rest = rest[start+end+2:]
+	}
+}
+
+// stripTemplates — путь с шаблонами, заменёнными на "_"; ошибка для незакрытых скобок и
+// неизвестных выражений.
+func stripTemplates(path string) (string, error) {
+	var b strings.Builder
+	rest := path
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated template in %q", path)
+		}
+		expression := strings.TrimSpace(rest[start+2 : start+end])
+		if !identityTemplate.MatchString(expression) {
+			return "", fmt.Errorf("unsupported template {{%s}}, expected identity.entity.* or identity.groups.*", expression)
+		}
+		b.WriteString(rest[:start])
+		b.WriteString("_")
+		rest = rest[start+end+2:]
+	}
+	if strings.Contains(rest, "}}") {
+		return "", fmt.Errorf("unbalanced template braces in %q", path)
+	}
+	b.WriteString(rest)
+	return b.String(), nil
+}
+
+// ParseTTL — TTL в формате OpenBao: длительность Go ("30m") или число секунд ("1800").
+func ParseTTL(ttl string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(ttl); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TTL %q: %w", ttl, err)
+	}
+	return d, nil
+}
+
+func capabilityNames() []string {
+	return []string{"create", "read", "update", "delete", "list", "sudo", "deny", "patch", "subscribe"}
+}
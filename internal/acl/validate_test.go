@@ -0,0 +1,102 @@
+// Тесты проверки правил ACL-политик.
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestValidatePath(t *testing.T) {
+	valid := []string{
+		"secret/data/app",
+		"secret/data/app/*",
+		"secret/data/app*",
+		"secret/+/config",
+		"+/data/+",
+		"secret/data/" + IdentityEntityName + "/*",
+		"secret/data/" + IdentityEntityMetadata("team") + "/*",
+		"secret/data/" + IdentityAliasName("auth_kubernetes_1234") + "/*",
+		"secret/data/{{ identity.entity.id }}",
+		"secret/data/{{identity.groups.names.ops.id}}/*",
+		"secret/data/{{identity.groups.ids.abc.metadata.env}}",
+		"secret/data/{{identity.entity.aliases.auth_jwt_1.custom_metadata.team}}",
+	}
+	for _, path := range valid {
+		assert.NoError(t, ValidatePath(path), path)
+	}
+
+	invalid := []string{
+		"",
+		"secret/*/config",
+		"secret/data/a+b",
+		"secret/+x/config",
+		"secret/data/{{namespace}}/*",
+		"secret/data/{{identity.entity.email}}",
+		"secret/data/{{identity.entity.id",
+		"secret/data/identity.entity.id}}",
+	}
+	for _, path := range invalid {
+		assert.Error(t, ValidatePath(path), path)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	assert.Equal(t, []string{"identity.entity.name", "identity.entity.id"},
+		Templates("secret/{{identity.entity.name}}/{{ identity.entity.id }}/*"))
+	assert.Empty(t, Templates("secret/data/app"))
+}
+
+func TestValidateRules(t *testing.T) {
+	rules := []kubebaoiov1alpha1.PolicyRule{
+		{
+			Path:           "secret/data/app/*",
+			Capabilities:   []kubebaoiov1alpha1.Capability{"read", "subscribe"},
+			MinWrappingTTL: "1m",
+			MaxWrappingTTL: "3600",
+		},
+		{
+			Path:           "secret/*/bad",
+			Capabilities:   []kubebaoiov1alpha1.Capability{"read", "scan"},
+			MinWrappingTTL: "2h",
+			MaxWrappingTTL: "1h",
+			ControlGroup: &kubebaoiov1alpha1.ControlGroup{
+				TTL: "forever",
+				Factors: []kubebaoiov1alpha1.ControlGroupFactor{
+					{Name: "ops", GroupNames: []string{"ops"}},
+					{Name: "ops"},
+				},
+			},
+		},
+	}
+
+	errs := ValidateRules(rules, field.NewPath("spec", "rules"))
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"spec.rules[1].path",
+		"spec.rules[1].capabilities[1]",
+		"spec.rules[1].minWrappingTTL",
+		"spec.rules[1].controlGroup.ttl",
+		"spec.rules[1].controlGroup.factors[1].name",
+		"spec.rules[1].controlGroup.factors[1].groupNames",
+	}, fields)
+}
+
+func TestParseTTL(t *testing.T) {
+	d, err := ParseTTL("90")
+	assert.NoError(t, err)
+	assert.Equal(t, "1m30s", d.String())
+
+	d, err = ParseTTL("2h")
+	assert.NoError(t, err)
+	assert.Equal(t, "2h0m0s", d.String())
+
+	_, err = ParseTTL("two hours")
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+// Ограничения BaoPolicy: BaoPolicyConstraint задаёт пути, capabilities и TTL обёртки, которые
+// может выдавать BaoPolicy в выбранных namespace. Проверяются в webhook и перед записью политики.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/acl"
+)
+
+// +kubebuilder:rbac:groups=kubebao.io,resources=baopolicyconstraints,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// constraintViolationError — BaoPolicy нарушает BaoPolicyConstraint; политика не записывается.
+type constraintViolationError struct {
+	errs field.ErrorList
+}
+
+func (e *constraintViolationError) Error() string {
+	return "policy violates BaoPolicyConstraint: " + e.errs.ToAggregate().Error()
+}
+
+// PolicyConstraintViolations — нарушения BaoPolicyConstraint, выбирающих namespace BaoPolicy.
+// ClusterBaoPolicy ограничениям не подлежит.
+func PolicyConstraintViolations(ctx context.Context, c client.Reader, baoPolicy *kubebaoiov1alpha1.BaoPolicy) (field.ErrorList, error) {
+	constraints := &kubebaoiov1alpha1.BaoPolicyConstraintList{}
+	if err := c.List(ctx, constraints); err != nil {
+		return nil, fmt.Errorf("failed to list BaoPolicyConstraints: %w", err)
+	}
+	if len(constraints.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: baoPolicy.Namespace}, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %w", baoPolicy.Namespace, err)
+	}
+
+	var errs field.ErrorList
+	for i := range constraints.Items {
+		constraint := &constraints.Items[i]
+		if selector := constraint.Spec.NamespaceSelector; selector != nil {
+			s, err := metav1.LabelSelectorAsSelector(selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespaceSelector of BaoPolicyConstraint %s: %w", constraint.Name, err)
+			}
+			if !s.Matches(labels.Set(namespace.Labels)) {
+				continue
+			}
+		}
+		errs = append(errs, ruleViolations(constraint, baoPolicy.Namespace, baoPolicy.Spec.Rules)...)
+	}
+	return errs, nil
+}
+
+// ruleViolations — правила, выходящие за пути, capabilities и TTL обёртки одного ограничения.
+func ruleViolations(constraint *kubebaoiov1alpha1.BaoPolicyConstraint, namespace string, rules []kubebaoiov1alpha1.PolicyRule) field.ErrorList {
+	var errs field.ErrorList
+	rulesPath := field.NewPath("spec", "rules")
+
+	allowedPaths := make([]string, 0, len(constraint.Spec.AllowedPaths))
+	for _, pattern := range constraint.Spec.AllowedPaths {
+		allowedPaths = append(allowedPaths, strings.ReplaceAll(pattern, kubebaoiov1alpha1.NamespacePlaceholder, namespace))
+	}
+
+	var maxTTL time.Duration
+	if constraint.Spec.MaxWrappingTTL != "" {
+		d, err := acl.ParseTTL(constraint.Spec.MaxWrappingTTL)
+		if err != nil {
+			// Неверное ограничение не должно открывать доступ: все правила считаются нарушением
+			return append(errs, field.Forbidden(rulesPath,
+				fmt.Sprintf("BaoPolicyConstraint %s has an invalid maxWrappingTTL %q", constraint.Name, constraint.Spec.MaxWrappingTTL)))
+		}
+		maxTTL = d
+	}
+
+	for i, rule := range rules {
+		rulePath := rulesPath.Index(i)
+
+		if len(allowedPaths) > 0 && !pathAllowed(rule.Path, allowedPaths) {
+			errs = append(errs, field.Forbidden(rulePath.Child("path"),
+				fmt.Sprintf("path %s is not allowed by BaoPolicyConstraint %s, allowed: %s",
+					rule.Path, constraint.Name, strings.Join(allowedPaths, ", "))))
+		}
+
+		for _, capability := range rule.Capabilities {
+			if slices.Contains(constraint.Spec.ForbiddenCapabilities, capability) {
+				errs = append(errs, field.Forbidden(rulePath.Child("capabilities"),
+					fmt.Sprintf("capability %s is forbidden by BaoPolicyConstraint %s", capability, constraint.Name)))
+			}
+		}
+
+		if maxTTL == 0 {
+			continue
+		}
+		if rule.MaxWrappingTTL == "" {
+			errs = append(errs, field.Required(rulePath.Child("maxWrappingTTL"),
+				fmt.Sprintf("required by BaoPolicyConstraint %s, at most %s", constraint.Name, constraint.Spec.MaxWrappingTTL)))
+		}
+		ttls := []struct{ name, value string }{
+			{"minWrappingTTL", rule.MinWrappingTTL},
+			{"maxWrappingTTL", rule.MaxWrappingTTL},
+		}
+		for _, t := range ttls {
+			if t.value == "" {
+				continue
+			}
+			ttl, err := acl.ParseTTL(t.value)
+			switch {
+			case err != nil:
+				errs = append(errs, field.Invalid(rulePath.Child(t.name), t.value, err.Error()))
+			case ttl > maxTTL:
+				errs = append(errs, field.Forbidden(rulePath.Child(t.name),
+					fmt.Sprintf("%s exceeds maxWrappingTTL %s of BaoPolicyConstraint %s", t.value, constraint.Spec.MaxWrappingTTL, constraint.Name)))
+			}
+		}
+	}
+	return errs
+}
+
+// pathAllowed — путь правила внутри одного из шаблонов: шаблон с "*" в конце разрешает все пути
+// с этим префиксом, остальные — только точное совпадение. Glob и "+" в пути правила не расширяют
+// разрешённое: "secret/data/team*" не входит в "secret/data/team/*".
+func pathAllowed(path string, allowedPaths []string) bool {
+	for _, pattern := range allowedPaths {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPolicyConstraints — ошибка constraintViolationError для BaoPolicy, нарушающего ограничения.
+func (r *BaoPolicyReconciler) checkPolicyConstraints(ctx context.Context, policy PolicyObject) error {
+	baoPolicy, ok := policy.(*kubebaoiov1alpha1.BaoPolicy)
+	if !ok {
+		return nil
+	}
+	errs, err := PolicyConstraintViolations(ctx, r.Client, baoPolicy)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return &constraintViolationError{errs: errs}
+	}
+	return nil
+}
+
+// constraintToPolicies — при изменении BaoPolicyConstraint перепроверяются все BaoPolicy.
+func (r *BaoPolicyReconciler) constraintToPolicies(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := &kubebaoiov1alpha1.BaoPolicyList{}
+	if err := r.List(ctx, list); err != nil {
+		r.Log.Error(err, "Ошибка получения BaoPolicy для проверки ограничений")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, item := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
+	}
+	return requests
+}
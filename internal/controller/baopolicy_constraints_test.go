@@ -0,0 +1,211 @@
+// Тесты ограничений BaoPolicyConstraint: шаблоны путей, capabilities, TTL обёртки и проверка
+// перед записью политики.
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestPathAllowed(t *testing.T) {
+	allowed := []string{"secret/data/team-a/*", "sys/mounts", "transit/encrypt/team-a*"}
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{path: "secret/data/team-a/config", allowed: true},
+		{path: "secret/data/team-a/app/db", allowed: true},
+		{path: "secret/data/team-a/*", allowed: true},
+		{path: "secret/data/team-a/+/config", allowed: true},
+		{path: "sys/mounts", allowed: true},
+		{path: "transit/encrypt/team-a-key", allowed: true},
+		// Шаблон без "*" — только точное совпадение
+		{path: "sys/mounts/kv"},
+		{path: "sys/mount"},
+		// Glob и "+" в пути правила не расширяют разрешённое
+		{path: "secret/data/team-a*"},
+		{path: "secret/data/*"},
+		{path: "secret/data/+/config"},
+		{path: "secret/+/team-a/config"},
+		{path: "secret/data/team-b/config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, pathAllowed(tt.path, allowed))
+		})
+	}
+}
+
+func TestRuleViolations(t *testing.T) {
+	read := []kubebaoiov1alpha1.Capability{kubebaoiov1alpha1.CapabilityRead}
+
+	tests := []struct {
+		name       string
+		constraint kubebaoiov1alpha1.BaoPolicyConstraintSpec
+		rules      []kubebaoiov1alpha1.PolicyRule
+		want       []string // поля нарушений
+	}{
+		{
+			name:       "{{namespace}} is replaced with the BaoPolicy namespace",
+			constraint: kubebaoiov1alpha1.BaoPolicyConstraintSpec{AllowedPaths: []string{"secret/data/{{namespace}}/*"}},
+			rules: []kubebaoiov1alpha1.PolicyRule{
+				{Path: "secret/data/team-a/config", Capabilities: read},
+				{Path: "secret/data/team-b/config", Capabilities: read},
+				{Path: "secret/data/{{namespace}}/config", Capabilities: read},
+			},
+			want: []string{"spec.rules[1].path", "spec.rules[2].path"},
+		},
+		{
+			name:       "exact pattern",
+			constraint: kubebaoiov1alpha1.BaoPolicyConstraintSpec{AllowedPaths: []string{"secret/data/shared"}},
+			rules: []kubebaoiov1alpha1.PolicyRule{
+				{Path: "secret/data/shared", Capabilities: read},
+				{Path: "secret/data/shared/*", Capabilities: read},
+			},
+			want: []string{"spec.rules[1].path"},
+		},
+		{
+			name:       "+ and * in rule paths",
+			constraint: kubebaoiov1alpha1.BaoPolicyConstraintSpec{AllowedPaths: []string{"secret/data/{{namespace}}/*"}},
+			rules: []kubebaoiov1alpha1.PolicyRule{
+				{Path: "secret/data/team-a/+/config", Capabilities: read},
+				{Path: "secret/data/+/config", Capabilities: read},
+				{Path: "secret/data/team-a*", Capabilities: read},
+			},
+			want: []string{"spec.rules[1].path", "spec.rules[2].path"},
+		},
+		{
+			name:       "no allowedPaths allows any path",
+			constraint: kubebaoiov1alpha1.BaoPolicyConstraintSpec{},
+			rules:      []kubebaoiov1alpha1.PolicyRule{{Path: "sys/*", Capabilities: read}},
+		},
+		{
+			name: "forbiddenCapabilities",
+			constraint: kubebaoiov1alpha1.BaoPolicyConstraintSpec{ForbiddenCapabilities: []kubebaoiov1alpha1.Capability{
+				kubebaoiov1alpha1.CapabilitySudo, kubebaoiov1alpha1.CapabilityDelete,
+			}},
+			rules: []kubebaoiov1alpha1.PolicyRule{
+				{Path: "secret/data/team-a/*", Capabilities: read},
+				{Path: "secret/data/team-a/*", Capabilities: []kubebaoiov1alpha1.Capability{
+					kubebaoiov1alpha1.CapabilityRead, kubebaoiov1alpha1.CapabilitySudo, kubebaoiov1alpha1.CapabilityDelete,
+				}},
+			},
+			want: []string{"spec.rules[1].capabilities", "spec.rules[1].capabilities"},
+		},
+		{
+			name:       "maxWrappingTTL bounds the rule TTLs",
+			constraint: kubebaoiov1alpha1.BaoPolicyConstraintSpec{MaxWrappingTTL: "1h"},
+			rules: []kubebaoiov1alpha1.PolicyRule{
+				{Path: "secret/data/a", Capabilities: read, MaxWrappingTTL: "30m"},
+				{Path: "secret/data/b", Capabilities: read},
+				{Path: "secret/data/c", Capabilities: read, MinWrappingTTL: "2h", MaxWrappingTTL: "3h"},
+				{Path: "secret/data/d", Capabilities: read, MaxWrappingTTL: "later"},
+			},
+			want: []string{
+				"spec.rules[1].maxWrappingTTL",
+				"spec.rules[2].minWrappingTTL", "spec.rules[2].maxWrappingTTL",
+				"spec.rules[3].maxWrappingTTL",
+			},
+		},
+		{
+			name: "invalid maxWrappingTTL of the constraint rejects every rule",
+			constraint: kubebaoiov1alpha1.BaoPolicyConstraintSpec{
+				AllowedPaths:   []string{"secret/*"},
+				MaxWrappingTTL: "forever",
+			},
+			rules: []kubebaoiov1alpha1.PolicyRule{{Path: "secret/data/a", Capabilities: read, MaxWrappingTTL: "1m"}},
+			want:  []string{"spec.rules"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint := &kubebaoiov1alpha1.BaoPolicyConstraint{
+				ObjectMeta: metav1.ObjectMeta{Name: "tenants"},
+				Spec:       tt.constraint,
+			}
+			errs := ruleViolations(constraint, "team-a", tt.rules)
+
+			fields := make([]string, 0, len(errs))
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tt.want, nonNil(fields))
+		})
+	}
+}
+
+// nonNil — пустой список нарушений сравнивается с nil в таблице.
+func nonNil(fields []string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+func TestCheckPolicyConstraints(t *testing.T) {
+	tenant := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name: "team-a", Labels: map[string]string{"kubebao.io/tenant": "true"},
+	}}
+	platform := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "platform"}}
+	constraint := &kubebaoiov1alpha1.BaoPolicyConstraint{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants"},
+		Spec: kubebaoiov1alpha1.BaoPolicyConstraintSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubebao.io/tenant": "true"}},
+			AllowedPaths:      []string{"secret/data/{{namespace}}/*"},
+		},
+	}
+	rules := []kubebaoiov1alpha1.PolicyRule{{
+		Path:         "secret/data/platform/*",
+		Capabilities: []kubebaoiov1alpha1.Capability{kubebaoiov1alpha1.CapabilityRead},
+	}}
+
+	scheme := newTestScheme(t)
+	require.NoError(t, corev1.AddToScheme(scheme))
+	r := &BaoPolicyReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenant, platform, constraint).Build(),
+		Log:    logr.Discard(),
+	}
+	ctx := context.Background()
+
+	// Namespace выбран namespaceSelector — политика не записывается
+	tenantPolicy := &kubebaoiov1alpha1.BaoPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "app"},
+		Spec:       kubebaoiov1alpha1.BaoPolicySpec{Rules: rules},
+	}
+	err := r.checkPolicyConstraints(ctx, tenantPolicy)
+	var violation *constraintViolationError
+	require.True(t, errors.As(err, &violation))
+	assert.Equal(t, kubebaoiov1alpha1.ReasonConstraintViolation, failureReason(err))
+	assert.Equal(t, field.ErrorTypeForbidden, violation.errs[0].Type)
+
+	// Ошибка reconcile совпадает с нарушениями, которые отклоняет webhook
+	errs, err := PolicyConstraintViolations(ctx, r.Client, tenantPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, errs, violation.errs)
+
+	// Namespace не выбран
+	platformPolicy := tenantPolicy.DeepCopy()
+	platformPolicy.Namespace = "platform"
+	assert.NoError(t, r.checkPolicyConstraints(ctx, platformPolicy))
+
+	// ClusterBaoPolicy ограничениям не подлежит
+	clusterPolicy := &kubebaoiov1alpha1.ClusterBaoPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+		Spec:       kubebaoiov1alpha1.BaoPolicySpec{Rules: rules},
+	}
+	assert.NoError(t, r.checkPolicyConstraints(ctx, clusterPolicy))
+}
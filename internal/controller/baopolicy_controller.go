@@ -6,16 +6,18 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
 	"github.com/kubebao/kubebao/internal/openbao"
@@ -32,6 +34,30 @@ type BaoPolicyReconciler struct {
 	Scheme        *runtime.Scheme
 	Log           logr.Logger
 	OpenBaoClient *openbao.Client
+	// Recorder — события Kubernetes по BaoPolicy
+	Recorder record.EventRecorder
+	// ClusterName — имя кластера в маркере владельца политики (общий OpenBao для нескольких кластеров)
+	ClusterName string
+	// LegacyPolicyNames — имена политик BaoPolicy без префикса namespace, как в прежних версиях
+	LegacyPolicyNames bool
+}
+
+// PolicyObject — BaoPolicy или ClusterBaoPolicy: общие spec и status, разное имя политики.
+type PolicyObject interface {
+	client.Object
+	GetPolicySpec() *kubebaoiov1alpha1.BaoPolicySpec
+	GetPolicyStatus() *kubebaoiov1alpha1.BaoPolicyStatus
+	GetPolicyName() string
+	ToHCL() string
+}
+
+// PolicyName — имя политики в OpenBao. BaoPolicy получает префикс namespace, если не включены
+// прежние имена (--legacy-policy-names); ClusterBaoPolicy — всегда без префикса.
+func PolicyName(obj PolicyObject, legacyNames bool) string {
+	if baoPolicy, ok := obj.(*kubebaoiov1alpha1.BaoPolicy); ok && legacyNames {
+		return baoPolicy.GetUnscopedPolicyName()
+	}
+	return obj.GetPolicyName()
 }
 
 // +kubebuilder:rbac:groups=kubebao.io,resources=baopolicies,verbs=get;list;watch;create;update;patch;delete
@@ -40,22 +66,27 @@ type BaoPolicyReconciler struct {
 
 // Reconcile — цикл согласования BaoPolicy. Записывает HCL-политику в OpenBao sys/policies/acl/.
 func (r *BaoPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := r.Log.WithValues("baopolicy", req.NamespacedName)
-	log.V(1).Info("Начало reconcile BaoPolicy", "namespace", req.Namespace, "name", req.Name)
+	return r.reconcilePolicy(ctx, req, &kubebaoiov1alpha1.BaoPolicy{})
+}
+
+// reconcilePolicy — общий цикл BaoPolicy и ClusterBaoPolicy; baoPolicy — пустой объект нужного вида.
+func (r *BaoPolicyReconciler) reconcilePolicy(ctx context.Context, req ctrl.Request, baoPolicy PolicyObject) (ctrl.Result, error) {
+	log := r.Log.WithValues("policy", req.NamespacedName)
+	log.V(1).Info("Начало reconcile политики", "namespace", req.Namespace, "name", req.Name)
 
-	// Загрузка BaoPolicy
-	baoPolicy := &kubebaoiov1alpha1.BaoPolicy{}
+	// Загрузка BaoPolicy или ClusterBaoPolicy
 	if err := r.Get(ctx, req.NamespacedName, baoPolicy); err != nil {
 		if apierrors.IsNotFound(err) {
-			log.V(1).Info("BaoPolicy не найден — завершение")
+			log.V(1).Info("Политика не найдена — завершение")
 			return ctrl.Result{}, nil
 		}
-		log.Error(err, "Ошибка получения BaoPolicy")
+		log.Error(err, "Ошибка получения политики")
 		return ctrl.Result{}, err
 	}
+	status := baoPolicy.GetPolicyStatus()
 
 	// Обработка удаления — снятие finalizer
-	if !baoPolicy.DeletionTimestamp.IsZero() {
+	if !baoPolicy.GetDeletionTimestamp().IsZero() {
 		return r.handleDeletion(ctx, baoPolicy)
 	}
 
@@ -68,22 +99,42 @@ func (r *BaoPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	// Запись/обновление политики в OpenBao
-	log.V(1).Info("Синхронизация политики", "policyName", baoPolicy.GetPolicyName())
-	if err := r.syncPolicy(ctx, baoPolicy); err != nil {
+	log.V(1).Info("Синхронизация политики", "policyName", PolicyName(baoPolicy, r.LegacyPolicyNames))
+	previousVersion := status.PolicyVersion
+	drifted, err := r.syncPolicy(ctx, baoPolicy)
+	if err != nil {
 		log.Error(err, "Ошибка синхронизации политики")
+		reason := failureReason(err)
 		r.setCondition(baoPolicy, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionFalse,
-			kubebaoiov1alpha1.ReasonFailed, err.Error())
+			reason, err.Error())
+		status.ConsecutiveFailures++
+		if r.Recorder != nil {
+			r.Recorder.Event(baoPolicy, corev1.EventTypeWarning, reason, truncateMessage(err.Error()))
+		}
 		if err := r.Status().Update(ctx, baoPolicy); err != nil {
 			return ctrl.Result{}, err
 		}
-		log.Info("Повтор через 30 секунд")
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		retryIn := failureBackoff(status.ConsecutiveFailures)
+		log.Info("Повтор синхронизации политики", "reason", reason, "failures", status.ConsecutiveFailures, "retryIn", retryIn)
+		return ctrl.Result{RequeueAfter: retryIn}, nil
+	}
+
+	if r.Recorder != nil && status.PolicyVersion != previousVersion {
+		r.Recorder.Eventf(baoPolicy, corev1.EventTypeNormal, eventReasonSynced,
+			"Policy %s written to OpenBao", status.AppliedPolicyName)
+	}
+	refreshInterval := r.refreshInterval(baoPolicy)
+	if drifted {
+		r.markPolicyDrifted(baoPolicy)
+	} else {
+		r.clearPolicyDrift(baoPolicy, refreshInterval)
 	}
 
 	// Обновление статуса — Ready, LastSyncTime
-	baoPolicy.Status.ObservedGeneration = baoPolicy.Generation
+	status.ConsecutiveFailures = 0
+	status.ObservedGeneration = baoPolicy.GetGeneration()
 	now := metav1.Now()
-	baoPolicy.Status.LastSyncTime = &now
+	status.LastSyncTime = &now
 	r.setCondition(baoPolicy, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionTrue,
 		kubebaoiov1alpha1.ReasonSuccess, "Policy synced successfully")
 
@@ -91,79 +142,122 @@ func (r *BaoPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
-	log.Info("Политика успешно синхронизирована", "policy", baoPolicy.Name)
-	return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	log.Info("Политика успешно синхронизирована", "nextSync", refreshInterval)
+	return ctrl.Result{RequeueAfter: withJitter(refreshInterval)}, nil
 }
 
-// syncPolicy — конвертирует BaoPolicy в HCL, сравнивает хэш с PolicyVersion, при изменении пишет в OpenBao.
-func (r *BaoPolicyReconciler) syncPolicy(ctx context.Context, baoPolicy *kubebaoiov1alpha1.BaoPolicy) error {
-	log := r.Log.WithValues("baopolicy", types.NamespacedName{
-		Name:      baoPolicy.Name,
-		Namespace: baoPolicy.Namespace,
-	})
+// syncPolicy — конвертирует BaoPolicy в HCL и сверяет его с политикой в OpenBao. Политика
+// записывается, если её нет или она отличается; возвращает true, если отличие внесено вне
+// оператора (дрейф) и политика восстановлена.
+func (r *BaoPolicyReconciler) syncPolicy(ctx context.Context, baoPolicy PolicyObject) (bool, error) {
+	log := r.Log.WithValues("policy", client.ObjectKeyFromObject(baoPolicy))
+	status := baoPolicy.GetPolicyStatus()
 
 	// Клиент OpenBao
 	baoClient := r.OpenBaoClient
 	if baoClient == nil {
-		return fmt.Errorf("OpenBao client not configured")
+		return false, fmt.Errorf("OpenBao client not configured")
+	}
+
+	// Правила, которые OpenBao отклонил бы или понял бы иначе (glob, шаблоны identity, TTL)
+	if errs := ValidatePolicySpec(baoPolicy.GetPolicySpec()); len(errs) > 0 {
+		return false, &invalidSpecError{errs: errs}
+	}
+
+	// Правила вне BaoPolicyConstraint namespace не записываются; уже записанная политика остаётся
+	if err := r.checkPolicyConstraints(ctx, baoPolicy); err != nil {
+		return false, err
 	}
 
 	// Генерация HCL из BaoPolicy.Spec.Rules (path "secret/*" { capabilities = [...] })
 	policyHCL := baoPolicy.ToHCL()
-	policyName := baoPolicy.GetPolicyName()
+	policyName := PolicyName(baoPolicy, r.LegacyPolicyNames)
 	log.V(1).Info("HCL политики сгенерирован", "policyName", policyName)
 
-	// Хэш HCL для проверки необходимости обновления
+	// Хэш HCL с маркером владельца: политики, записанные до появления маркера, перезаписываются один раз
+	policyHCL = withOwnerMarker(policyHCL, r.policyOwner(baoPolicy))
 	hash := sha256.Sum256([]byte(policyHCL))
 	version := hex.EncodeToString(hash[:8])
+	applied := status.PolicyVersion == version && status.AppliedPolicyName == policyName
+
+	// Политика в OpenBao сравнивается при каждом reconcile: правка через API или CLI — дрейф
+	current, err := baoClient.ReadACLPolicy(ctx, policyName)
+	exists := true
+	switch {
+	case openbao.IsNotFound(err):
+		exists = false
+	case err != nil:
+		return false, fmt.Errorf("failed to read policy from OpenBao: %w", err)
+	}
 
-	// Check if policy needs update
-	if baoPolicy.Status.PolicyVersion == version {
+	if exists && normalizePolicy(current) == normalizePolicy(policyHCL) {
 		log.V(1).Info("Политика не изменилась, обновление пропущено")
-		return nil
+		status.PolicyVersion = version
+		status.AppliedPolicyName = policyName
+		return false, nil
+	}
+
+	// Чужую политику с тем же именем не перезаписываем
+	if exists {
+		if err := r.checkPolicyOwnership(baoPolicy, policyName, current); err != nil {
+			return false, err
+		}
 	}
 
-	// Запись в sys/policies/acl/{policyName}
-	path := fmt.Sprintf("sys/policies/acl/%s", policyName)
-	log.V(1).Info("Запись политики в OpenBao", "path", path)
-	data := map[string]interface{}{
-		"policy": policyHCL,
+	// Записанная оператором политика изменена или удалена вне оператора
+	drifted := applied
+	if drifted {
+		log.Info("Политика изменена в OpenBao вне kubebao, восстановление", "policyName", policyName, "deleted", !exists)
 	}
 
-	_, err := baoClient.WriteSecret(ctx, path, data)
-	if err != nil {
-		return fmt.Errorf("failed to write policy to OpenBao: %w", err)
+	// Запись в sys/policies/acl/{policyName}; первая строка — маркер владельца
+	log.V(1).Info("Запись политики в OpenBao", "policyName", policyName)
+	if err := baoClient.WriteACLPolicy(ctx, policyName, policyHCL); err != nil {
+		return false, fmt.Errorf("failed to write policy to OpenBao: %w", err)
 	}
 
 	log.Info("Политика записана в OpenBao", "policyName", policyName)
 
+	// Политика переименована — прежняя удаляется по deletionPolicy
+	if previous := status.AppliedPolicyName; previous != "" && previous != policyName {
+		if err := r.deletePolicy(ctx, baoClient, baoPolicy, previous); err != nil {
+			log.Error(err, "Ошибка удаления прежней политики", "policyName", previous)
+		}
+	}
+
 	// Update status
-	baoPolicy.Status.PolicyVersion = version
-	baoPolicy.Status.AppliedPolicyName = policyName
+	status.PolicyVersion = version
+	status.AppliedPolicyName = policyName
 
-	return nil
+	return drifted, nil
 }
 
-// handleDeletion handles the deletion of a BaoPolicy
-func (r *BaoPolicyReconciler) handleDeletion(ctx context.Context, baoPolicy *kubebaoiov1alpha1.BaoPolicy) (ctrl.Result, error) {
-	log := r.Log.WithValues("baopolicy", types.NamespacedName{
-		Name:      baoPolicy.Name,
-		Namespace: baoPolicy.Namespace,
-	})
+// handleDeletion — при deletionPolicy: Delete удаляет политику из OpenBao, если она создана этим
+// BaoPolicy. Недоступный OpenBao задерживает снятие finalizer с экспоненциальной задержкой.
+func (r *BaoPolicyReconciler) handleDeletion(ctx context.Context, baoPolicy PolicyObject) (ctrl.Result, error) {
+	log := r.Log.WithValues("policy", client.ObjectKeyFromObject(baoPolicy))
+	status := baoPolicy.GetPolicyStatus()
 
 	if controllerutil.ContainsFinalizer(baoPolicy, baoPolicyFinalizer) {
-		// Delete policy from OpenBao
-		if r.OpenBaoClient != nil && baoPolicy.Status.AppliedPolicyName != "" {
-			policyName := baoPolicy.Status.AppliedPolicyName
-			path := fmt.Sprintf("sys/policies/acl/%s", policyName)
-
-			// Note: We use ReadSecret here to simulate DELETE - in production
-			// you'd want a proper delete method
-			log.Info("Удаление политики из OpenBao", "policyName", policyName)
-
-			// For now, we'll just log the deletion intent
-			// The actual deletion would require the DELETE HTTP method
-			_ = path
+		policyName := status.AppliedPolicyName
+		switch {
+		case policyName == "":
+		case r.OpenBaoClient == nil:
+			log.Info("Клиент OpenBao не настроен, политика не удалена", "policyName", policyName)
+		default:
+			if err := r.deletePolicy(ctx, r.OpenBaoClient, baoPolicy, policyName); err != nil {
+				log.Error(err, "Ошибка удаления политики из OpenBao", "policyName", policyName)
+				reason := failureReason(err)
+				if r.Recorder != nil {
+					r.Recorder.Event(baoPolicy, corev1.EventTypeWarning, reason, truncateMessage(err.Error()))
+				}
+				status.ConsecutiveFailures++
+				r.setCondition(baoPolicy, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionFalse, reason, err.Error())
+				if err := r.Status().Update(ctx, baoPolicy); err != nil {
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: failureBackoff(status.ConsecutiveFailures)}, nil
+			}
 		}
 
 		// Remove finalizer
@@ -176,14 +270,15 @@ func (r *BaoPolicyReconciler) handleDeletion(ctx context.Context, baoPolicy *kub
 	return ctrl.Result{}, nil
 }
 
-// setCondition sets a condition on the BaoPolicy status
-func (r *BaoPolicyReconciler) setCondition(baoPolicy *kubebaoiov1alpha1.BaoPolicy, condType string, status metav1.ConditionStatus, reason, message string) {
+// setCondition sets a condition on the BaoPolicy or ClusterBaoPolicy status
+func (r *BaoPolicyReconciler) setCondition(baoPolicy PolicyObject, condType string, status metav1.ConditionStatus, reason, message string) {
 	now := metav1.Now()
+	policyStatus := baoPolicy.GetPolicyStatus()
 
 	var existingCondition *metav1.Condition
-	for i := range baoPolicy.Status.Conditions {
-		if baoPolicy.Status.Conditions[i].Type == condType {
-			existingCondition = &baoPolicy.Status.Conditions[i]
+	for i := range policyStatus.Conditions {
+		if policyStatus.Conditions[i].Type == condType {
+			existingCondition = &policyStatus.Conditions[i]
 			break
 		}
 	}
@@ -196,7 +291,7 @@ func (r *BaoPolicyReconciler) setCondition(baoPolicy *kubebaoiov1alpha1.BaoPolic
 		existingCondition.Reason = reason
 		existingCondition.Message = message
 	} else {
-		baoPolicy.Status.Conditions = append(baoPolicy.Status.Conditions, metav1.Condition{
+		policyStatus.Conditions = append(policyStatus.Conditions, metav1.Condition{
 			Type:               condType,
 			Status:             status,
 			LastTransitionTime: now,
@@ -209,6 +304,7 @@ func (r *BaoPolicyReconciler) setCondition(baoPolicy *kubebaoiov1alpha1.BaoPolic
 // SetupWithManager sets up the controller with the Manager
 func (r *BaoPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&kubebaoiov1alpha1.BaoPolicy{}).
+		For(&kubebaoiov1alpha1.BaoPolicy{}, builder.WithPredicates(specChanged)).
+		Watches(&kubebaoiov1alpha1.BaoPolicyConstraint{}, handler.EnqueueRequestsFromMapFunc(r.constraintToPolicies)).
 		Complete(r)
 }
@@ -0,0 +1,76 @@
+// Тесты контроллеров BaoPolicy и ClusterBaoPolicy: ошибки повторяются по failureBackoff.
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestPolicyFailuresDoNotRequeueImmediately(t *testing.T) {
+	spec := kubebaoiov1alpha1.BaoPolicySpec{Rules: []kubebaoiov1alpha1.PolicyRule{{
+		Path:         "secret/data/app/*",
+		Capabilities: []kubebaoiov1alpha1.Capability{kubebaoiov1alpha1.CapabilityRead},
+	}}}
+	meta := metav1.ObjectMeta{Name: "app", Generation: 1, Finalizers: []string{baoPolicyFinalizer}}
+	namespaced := meta
+	namespaced.Namespace = "default"
+
+	tests := []struct {
+		name      string
+		policy    PolicyObject
+		reconcile func(r *BaoPolicyReconciler) func(context.Context, ctrl.Request) (ctrl.Result, error)
+	}{
+		{
+			name:   "BaoPolicy",
+			policy: &kubebaoiov1alpha1.BaoPolicy{ObjectMeta: namespaced, Spec: spec},
+			reconcile: func(r *BaoPolicyReconciler) func(context.Context, ctrl.Request) (ctrl.Result, error) {
+				return r.Reconcile
+			},
+		},
+		{
+			name:   "ClusterBaoPolicy",
+			policy: &kubebaoiov1alpha1.ClusterBaoPolicy{ObjectMeta: meta, Spec: spec},
+			reconcile: func(r *BaoPolicyReconciler) func(context.Context, ctrl.Request) (ctrl.Result, error) {
+				return (&ClusterBaoPolicyReconciler{BaoPolicyReconciler: *r}).Reconcile
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Без клиента OpenBao каждый reconcile завершается ошибкой
+			r := &BaoPolicyReconciler{
+				Client: fake.NewClientBuilder().WithScheme(newTestScheme(t)).
+					WithObjects(tt.policy).WithStatusSubresource(tt.policy).Build(),
+				Log: logr.Discard(),
+			}
+			reconcile := tt.reconcile(r)
+			ctx := context.Background()
+			req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tt.policy)}
+
+			previous := tt.policy.DeepCopyObject().(PolicyObject)
+			require.NoError(t, r.Get(ctx, req.NamespacedName, previous))
+			for failures := int32(1); failures <= 3; failures++ {
+				result, err := reconcile(ctx, req)
+				require.NoError(t, err)
+				assert.GreaterOrEqual(t, result.RequeueAfter, minFailureBackoff<<(failures-1))
+
+				current := tt.policy.DeepCopyObject().(PolicyObject)
+				require.NoError(t, r.Get(ctx, req.NamespacedName, current))
+				assert.Equal(t, failures, current.GetPolicyStatus().ConsecutiveFailures)
+				assertStatusWriteFiltered(t, previous, current)
+				previous = current
+			}
+		})
+	}
+}
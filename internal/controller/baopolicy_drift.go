@@ -0,0 +1,75 @@
+// Дрейф политики OpenBao: политика, изменённая или удалённая вне оператора, обнаруживается
+// сравнением с HCL из BaoPolicy при каждом reconcile и восстанавливается.
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+const (
+	// Интервал сверки политики с OpenBao по умолчанию
+	defaultPolicyRefreshInterval = 5 * time.Minute
+	// Минимальный интервал сверки
+	minPolicyRefreshInterval = 30 * time.Second
+)
+
+// normalizePolicy — HCL без различий в переводах строк, отступах и пустых строках:
+// такие правки не меняют смысл политики и дрейфом не считаются.
+func normalizePolicy(policy string) string {
+	lines := strings.Split(strings.ReplaceAll(policy, "\r\n", "\n"), "\n")
+	normalized := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			normalized = append(normalized, line)
+		}
+	}
+	return strings.Join(normalized, "\n")
+}
+
+// refreshInterval — интервал сверки из spec.refreshInterval (по умолчанию 5m, минимум 30s).
+func (r *BaoPolicyReconciler) refreshInterval(baoPolicy PolicyObject) time.Duration {
+	refreshInterval := baoPolicy.GetPolicySpec().RefreshInterval
+	if refreshInterval == "" {
+		return defaultPolicyRefreshInterval
+	}
+	d, err := time.ParseDuration(refreshInterval)
+	if err != nil {
+		return defaultPolicyRefreshInterval
+	}
+	if d < minPolicyRefreshInterval {
+		return minPolicyRefreshInterval
+	}
+	return d
+}
+
+// markPolicyDrifted — событие Drifted и условие Drifted=True после восстановления политики.
+func (r *BaoPolicyReconciler) markPolicyDrifted(baoPolicy PolicyObject) {
+	message := fmt.Sprintf("Policy %s was modified in OpenBao outside kubebao and restored", baoPolicy.GetPolicyStatus().AppliedPolicyName)
+	if r.Recorder != nil {
+		r.Recorder.Event(baoPolicy, corev1.EventTypeWarning, eventReasonDrifted, message)
+	}
+	r.setCondition(baoPolicy, kubebaoiov1alpha1.ConditionTypeDrifted, metav1.ConditionTrue,
+		kubebaoiov1alpha1.ReasonDriftRestored, message)
+}
+
+// clearPolicyDrift снимает Drifted спустя refreshInterval без повторного дрейфа, как и у BaoSecret.
+func (r *BaoPolicyReconciler) clearPolicyDrift(baoPolicy PolicyObject, refreshInterval time.Duration) {
+	for _, cond := range baoPolicy.GetPolicyStatus().Conditions {
+		if cond.Type != kubebaoiov1alpha1.ConditionTypeDrifted {
+			continue
+		}
+		if cond.Status == metav1.ConditionTrue && time.Since(cond.LastTransitionTime.Time) >= refreshInterval {
+			r.setCondition(baoPolicy, kubebaoiov1alpha1.ConditionTypeDrifted, metav1.ConditionFalse,
+				kubebaoiov1alpha1.ReasonInSync, "Policy matches the rules")
+		}
+		return
+	}
+}
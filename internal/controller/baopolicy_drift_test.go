@@ -0,0 +1,154 @@
+// Тесты дрейфа политики OpenBao: сравнение HCL, восстановление и снятие Drifted.
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestNormalizePolicy(t *testing.T) {
+	policy := "# kubebao:owner\npath \"secret/data/app/*\" {\n  capabilities = [\"read\"]\n}\n"
+
+	same := []string{
+		strings.ReplaceAll(policy, "\n", "\r\n"),
+		strings.ReplaceAll(policy, "  ", "\t"),
+		"\n\n" + strings.ReplaceAll(policy, "{\n", "{\n\n") + "\n\n",
+		"  " + strings.ReplaceAll(policy, "\n", "   \n"),
+	}
+	for _, variant := range same {
+		assert.Equal(t, normalizePolicy(policy), normalizePolicy(variant), "%q", variant)
+	}
+
+	different := []string{
+		strings.Replace(policy, `"read"`, `"read", "list"`, 1),
+		strings.Replace(policy, "app/*", "*", 1),
+		// Пробелы внутри строки значимы
+		strings.Replace(policy, "capabilities = ", "capabilities  = ", 1),
+		"",
+	}
+	for _, variant := range different {
+		assert.NotEqual(t, normalizePolicy(policy), normalizePolicy(variant), "%q", variant)
+	}
+}
+
+func TestPolicyDriftIsRestored(t *testing.T) {
+	baoPolicy := &kubebaoiov1alpha1.BaoPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 1},
+		Spec: kubebaoiov1alpha1.BaoPolicySpec{Rules: []kubebaoiov1alpha1.PolicyRule{{
+			Path:         "secret/data/app/*",
+			Capabilities: []kubebaoiov1alpha1.Capability{kubebaoiov1alpha1.CapabilityRead},
+		}}},
+	}
+	bao, baoClient := newFakeOpenBao(t)
+	recorder := record.NewFakeRecorder(10)
+	r := &BaoPolicyReconciler{
+		Client: fake.NewClientBuilder().WithScheme(newTestScheme(t)).
+			WithObjects(baoPolicy).WithStatusSubresource(baoPolicy).Build(),
+		Log:           logr.Discard(),
+		OpenBaoClient: baoClient,
+		Recorder:      recorder,
+	}
+	ctx := context.Background()
+	reconcile := func() *kubebaoiov1alpha1.BaoPolicy {
+		t.Helper()
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(baoPolicy)})
+		require.NoError(t, err)
+		current := &kubebaoiov1alpha1.BaoPolicy{}
+		require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(baoPolicy), current))
+		require.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeReady),
+			"sync failed: %v", current.Status.Conditions)
+		return current
+	}
+	drainEvents := func() []string {
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		return events
+	}
+
+	current := reconcile()
+	path := "sys/policies/acl/" + current.Status.AppliedPolicyName
+	written, _ := bao.data[path]["policy"].(string)
+	require.NotEmpty(t, written)
+	assert.Nil(t, meta.FindStatusCondition(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+	writes := len(bao.writes())
+	drainEvents()
+
+	// Другие отступы и переводы строк — не дрейф, политика не перезаписывается
+	bao.data[path]["policy"] = "\r\n" + strings.ReplaceAll(written, "\n", "\r\n\r\n  ")
+	current = reconcile()
+	assert.Len(t, bao.writes(), writes)
+	assert.Nil(t, meta.FindStatusCondition(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+	assert.Empty(t, drainEvents())
+
+	// Правило изменено через API OpenBao — политика восстанавливается
+	bao.data[path]["policy"] = strings.Replace(written, `"read"`, `"read", "delete"`, 1)
+	current = reconcile()
+	assert.Equal(t, written, bao.data[path]["policy"])
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+	assert.Contains(t, drainEvents(),
+		"Warning Drifted Policy "+current.Status.AppliedPolicyName+" was modified in OpenBao outside kubebao and restored")
+
+	// Политика удалена вне оператора — записывается заново
+	delete(bao.data, path)
+	current = reconcile()
+	assert.Equal(t, written, bao.data[path]["policy"])
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+	assert.NotEmpty(t, drainEvents())
+
+	// Без дрейфа Drifted остаётся до истечения refreshInterval
+	current = reconcile()
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+
+	// Изменение spec — не дрейф
+	current.Spec.Rules[0].Capabilities = append(current.Spec.Rules[0].Capabilities, kubebaoiov1alpha1.CapabilityList)
+	require.NoError(t, r.Update(ctx, current))
+	drainEvents()
+	reconcile()
+	for _, event := range drainEvents() {
+		assert.NotContains(t, event, "Drifted")
+	}
+}
+
+func TestClearPolicyDrift(t *testing.T) {
+	r := &BaoPolicyReconciler{Log: logr.Discard()}
+	drifted := func(since time.Duration) *kubebaoiov1alpha1.BaoPolicy {
+		return &kubebaoiov1alpha1.BaoPolicy{Status: kubebaoiov1alpha1.BaoPolicyStatus{Conditions: []metav1.Condition{{
+			Type:               kubebaoiov1alpha1.ConditionTypeDrifted,
+			Status:             metav1.ConditionTrue,
+			Reason:             kubebaoiov1alpha1.ReasonDriftRestored,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-since)),
+		}}}}
+	}
+
+	recent := drifted(time.Minute)
+	r.clearPolicyDrift(recent, 5*time.Minute)
+	assert.True(t, meta.IsStatusConditionTrue(recent.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+
+	old := drifted(10 * time.Minute)
+	r.clearPolicyDrift(old, 5*time.Minute)
+	cond := meta.FindStatusCondition(old.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, kubebaoiov1alpha1.ReasonInSync, cond.Reason)
+
+	// Без условия Drifted ничего не добавляется
+	clean := &kubebaoiov1alpha1.BaoPolicy{}
+	r.clearPolicyDrift(clean, 5*time.Minute)
+	assert.Empty(t, clean.Status.Conditions)
+}
@@ -0,0 +1,96 @@
+// Владение политиками OpenBao: первая строка политики — комментарий с BaoPolicy-владельцем.
+// Политики с чужим маркером или без маркера (кроме записанных этим BaoPolicy до появления
+// маркера) оператор не перезаписывает и не удаляет.
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+// Причина события при пропуске удаления чужой политики
+const eventReasonPolicyRetained = "PolicyRetained"
+
+// policyOwner — значение маркера владельца: <кластер>/<namespace>/<name>; у ClusterBaoPolicy namespace пуст.
+func (r *BaoPolicyReconciler) policyOwner(baoPolicy PolicyObject) string {
+	return ownerMarker(r.ClusterName, baoPolicy.GetNamespace(), baoPolicy.GetName())
+}
+
+// PolicyOwnerMarker — строка маркера, по которой BaoPolicy (или ClusterBaoPolicy при пустом
+// namespace) кластера cluster признаёт политику своей; используется при импорте политик.
+func PolicyOwnerMarker(cluster, namespace, name string) string {
+	return kubebaoiov1alpha1.PolicyOwnerPrefix + ownerMarker(cluster, namespace, name)
+}
+
+// withOwnerMarker добавляет маркер владельца первой строкой HCL.
+func withOwnerMarker(policyHCL, owner string) string {
+	return kubebaoiov1alpha1.PolicyOwnerPrefix + owner + "\n" + policyHCL
+}
+
+// policyOwnerOf — владелец из маркера в первой строке политики, "" — если маркера нет.
+func policyOwnerOf(policy string) string {
+	firstLine, _, _ := strings.Cut(policy, "\n")
+	owner, ok := strings.CutPrefix(strings.TrimSpace(firstLine), kubebaoiov1alpha1.PolicyOwnerPrefix)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(owner)
+}
+
+// checkPolicyOwnership разрешает перезапись существующей политики current, если она принадлежит
+// этому BaoPolicy.
+func (r *BaoPolicyReconciler) checkPolicyOwnership(baoPolicy PolicyObject, policyName, current string) error {
+	if r.ownsPolicy(baoPolicy, policyName, current) {
+		return nil
+	}
+	return &ownershipConflictError{kind: "policy", path: policyName, owner: policyOwnerOf(current)}
+}
+
+// ownsPolicy — политика policyName с содержимым current принадлежит BaoPolicy: маркер указывает
+// на него, либо маркера нет, а политика записана этим BaoPolicy до появления маркера
+// (AppliedPolicyName). Иначе прежние политики без маркера не удалялись бы после переименования.
+func (r *BaoPolicyReconciler) ownsPolicy(baoPolicy PolicyObject, policyName, current string) bool {
+	owner := policyOwnerOf(current)
+	return owner == r.policyOwner(baoPolicy) ||
+		owner == "" && baoPolicy.GetPolicyStatus().AppliedPolicyName == policyName
+}
+
+// deletePolicy удаляет политику при deletionPolicy: Delete, если она принадлежит BaoPolicy
+// (ownsPolicy). Отсутствующая политика считается удалённой.
+func (r *BaoPolicyReconciler) deletePolicy(ctx context.Context, baoClient *openbao.Client, baoPolicy PolicyObject, policyName string) error {
+	log := r.Log.WithValues("policy", client.ObjectKeyFromObject(baoPolicy), "policyName", policyName)
+
+	if baoPolicy.GetPolicySpec().DeletionPolicy == kubebaoiov1alpha1.DeletionPolicyRetain {
+		log.Info("Политика оставлена в OpenBao (deletionPolicy: Retain)")
+		return nil
+	}
+
+	current, err := baoClient.ReadACLPolicy(ctx, policyName)
+	switch {
+	case openbao.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	if !r.ownsPolicy(baoPolicy, policyName, current) {
+		log.Info("Политика создана не этим BaoPolicy, удаление пропущено", "owner", policyOwnerOf(current))
+		if r.Recorder != nil {
+			r.Recorder.Eventf(baoPolicy, corev1.EventTypeWarning, eventReasonPolicyRetained,
+				"Policy %s is not owned by this BaoPolicy and was not deleted", policyName)
+		}
+		return nil
+	}
+
+	if err := baoClient.DeleteACLPolicy(ctx, policyName); err != nil {
+		return err
+	}
+	log.Info("Политика удалена из OpenBao")
+	return nil
+}
@@ -0,0 +1,60 @@
+// Тесты имён политик BaoPolicy и владения политиками OpenBao.
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestPolicyNameIsUnambiguous(t *testing.T) {
+	first := &kubebaoiov1alpha1.BaoPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "a-b", Name: "c"},
+	}
+	second := &kubebaoiov1alpha1.BaoPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "b-c"},
+	}
+
+	assert.Equal(t, "a-b_c", PolicyName(first, false))
+	assert.Equal(t, "a_b-c", PolicyName(second, false))
+	assert.Equal(t, "c", PolicyName(first, true))
+
+	role := &kubebaoiov1alpha1.BaoRole{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "a-b", Name: "c"},
+	}
+	assert.Equal(t, "a-b_c", role.GetRoleName())
+}
+
+func TestOwnsPolicy(t *testing.T) {
+	r := &BaoPolicyReconciler{ClusterName: "prod"}
+	baoPolicy := &kubebaoiov1alpha1.BaoPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Status:     kubebaoiov1alpha1.BaoPolicyStatus{AppliedPolicyName: "app"},
+	}
+	rules := `path "secret/*" { capabilities = ["read"] }`
+
+	tests := []struct {
+		name       string
+		policyName string
+		current    string
+		owned      bool
+	}{
+		{name: "own marker", policyName: "default_app",
+			current: PolicyOwnerMarker("prod", "default", "app") + "\n" + rules, owned: true},
+		{name: "unmarked, applied by this BaoPolicy", policyName: "app", current: rules, owned: true},
+		{name: "unmarked, not applied", policyName: "default_app", current: rules},
+		{name: "marker of another BaoPolicy", policyName: "app",
+			current: PolicyOwnerMarker("prod", "other", "app") + "\n" + rules},
+		{name: "marker of another cluster", policyName: "default_app",
+			current: PolicyOwnerMarker("stage", "default", "app") + "\n" + rules},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.owned, r.ownsPolicy(baoPolicy, tt.policyName, tt.current))
+			assert.Equal(t, tt.owned, r.checkPolicyOwnership(baoPolicy, tt.policyName, tt.current) == nil)
+		})
+	}
+}
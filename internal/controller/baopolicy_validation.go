@@ -0,0 +1,57 @@
+// Проверка и значения по умолчанию spec BaoPolicy и ClusterBaoPolicy, общие для admission
+// webhook и reconcile.
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/acl"
+)
+
+// ValidatePolicySpec — ошибки spec политики: пустые правила, правила вне грамматики ACL,
+// интервал сверки и deletionPolicy.
+func ValidatePolicySpec(spec *kubebaoiov1alpha1.BaoPolicySpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	rulesPath := specPath.Child("rules")
+	if len(spec.Rules) == 0 {
+		errs = append(errs, field.Required(rulesPath, "at least one rule is required"))
+	}
+	for i, rule := range spec.Rules {
+		if len(rule.Capabilities) == 0 {
+			errs = append(errs, field.Required(rulesPath.Index(i).Child("capabilities"), "at least one capability is required"))
+		}
+	}
+	errs = append(errs, acl.ValidateRules(spec.Rules, rulesPath)...)
+
+	errs = append(errs, validateDuration(specPath.Child("refreshInterval"), spec.RefreshInterval)...)
+
+	switch spec.DeletionPolicy {
+	case "", kubebaoiov1alpha1.DeletionPolicyDelete, kubebaoiov1alpha1.DeletionPolicyRetain:
+	default:
+		errs = append(errs, field.NotSupported(specPath.Child("deletionPolicy"), spec.DeletionPolicy,
+			[]string{kubebaoiov1alpha1.DeletionPolicyDelete, kubebaoiov1alpha1.DeletionPolicyRetain}))
+	}
+	return errs
+}
+
+// DefaultPolicySpec заполняет пустые поля spec значениями по умолчанию CRD.
+func DefaultPolicySpec(spec *kubebaoiov1alpha1.BaoPolicySpec) {
+	if spec.RefreshInterval == "" {
+		spec.RefreshInterval = "5m"
+	}
+	if spec.DeletionPolicy == "" {
+		spec.DeletionPolicy = kubebaoiov1alpha1.DeletionPolicyDelete
+	}
+	for i := range spec.Rules {
+		if cg := spec.Rules[i].ControlGroup; cg != nil {
+			for j := range cg.Factors {
+				if cg.Factors[j].Approvals == 0 {
+					cg.Factors[j].Approvals = 1
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,443 @@
+// Контроллер BaoPushSecret — обратная синхронизация Kubernetes Secret → OpenBao KV v2.
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+const (
+	// Финализатор для BaoPushSecret — удаление секрета из KV при deletionPolicy: Delete
+	baoPushSecretFinalizer = "kubebao.io/push-finalizer"
+	// Индекс BaoPushSecret по имени исходного Secret
+	pushSecretRefIndex = ".spec.secretRef.name"
+	// Кластер в маркере владельца, если --cluster-name не задан
+	defaultClusterName = "default"
+)
+
+// BaoPushSecretReconciler — контроллер, записывающий выбранные ключи Kubernetes Secret в OpenBao KV.
+// Путь в KV помечается маркером владельца в custom_metadata; чужие и неразмеченные пути не перезаписываются.
+type BaoPushSecretReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Log           logr.Logger
+	OpenBaoClient *openbao.Client
+	// OpenBaoClients — клиенты для BaoPushSecret с OpenBaoRef/RoleName (identity ServiceAccount tenant'а)
+	OpenBaoClients *OpenBaoClients
+	// ClusterName различает кластеры, пишущие в один OpenBao, в маркере владельца
+	ClusterName string
+	// Recorder — события Kubernetes по BaoPushSecret
+	Recorder record.EventRecorder
+	// AllowOperatorIdentity — BaoPushSecret без openbaoRef и roleName пишет в OpenBao токеном
+	// оператора (--push-secret-operator-identity). Выключено: иначе любой tenant с правом создать
+	// BaoPushSecret записал бы в любой путь KV, доступный оператору
+	AllowOperatorIdentity bool
+}
+
+// +kubebuilder:rbac:groups=kubebao.io,resources=baopushsecrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kubebao.io,resources=baopushsecrets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kubebao.io,resources=baopushsecrets/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile — цикл согласования BaoPushSecret: чтение Secret, проверка владельца пути и запись с CAS.
+func (r *BaoPushSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("baopushsecret", req.NamespacedName)
+	log.V(1).Info("Начало reconcile BaoPushSecret")
+
+	pushSecret := &kubebaoiov1alpha1.BaoPushSecret{}
+	if err := r.Get(ctx, req.NamespacedName, pushSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("BaoPushSecret не найден — завершение")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Ошибка получения BaoPushSecret")
+		return ctrl.Result{}, err
+	}
+
+	if !pushSecret.DeletionTimestamp.IsZero() {
+		return r.handleDeletion(ctx, pushSecret)
+	}
+
+	if !controllerutil.ContainsFinalizer(pushSecret, baoPushSecretFinalizer) {
+		controllerutil.AddFinalizer(pushSecret, baoPushSecretFinalizer)
+		if err := r.Update(ctx, pushSecret); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.pushSecret(ctx, pushSecret); err != nil {
+		log.Error(err, "Ошибка записи секрета в OpenBao")
+		reason := pushFailureReason(err)
+		r.setCondition(pushSecret, kubebaoiov1alpha1.ConditionTypeSynced, metav1.ConditionFalse, reason, err.Error())
+		r.setCondition(pushSecret, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionFalse, reason, "Failed to push secret")
+		pushSecret.Status.ConsecutiveFailures++
+		if r.Recorder != nil {
+			r.Recorder.Event(pushSecret, corev1.EventTypeWarning, reason, truncateMessage(err.Error()))
+		}
+		if err := r.Status().Update(ctx, pushSecret); err != nil {
+			return ctrl.Result{}, err
+		}
+		retryIn := failureBackoff(pushSecret.Status.ConsecutiveFailures)
+		log.Info("Повтор записи секрета", "reason", reason, "failures", pushSecret.Status.ConsecutiveFailures, "retryIn", retryIn)
+		return ctrl.Result{RequeueAfter: retryIn}, nil
+	}
+
+	pushSecret.Status.ConsecutiveFailures = 0
+	pushSecret.Status.ObservedGeneration = pushSecret.Generation
+	now := metav1.Now()
+	pushSecret.Status.LastSyncTime = &now
+	r.setCondition(pushSecret, kubebaoiov1alpha1.ConditionTypeSynced, metav1.ConditionTrue,
+		kubebaoiov1alpha1.ReasonSuccess, "Secret pushed successfully")
+	r.setCondition(pushSecret, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionTrue,
+		kubebaoiov1alpha1.ReasonSuccess, "Secret is pushed")
+
+	if err := r.Status().Update(ctx, pushSecret); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: r.parseRefreshInterval(pushSecret.Spec.RefreshInterval)}, nil
+}
+
+// pushSecret записывает данные Secret в KV, если они изменились или версия в OpenBao ушла
+// от записанной оператором. Запись идёт с CAS по текущей версии: параллельное изменение
+// другим клиентом приводит к ошибке, а не к потере данных.
+func (r *BaoPushSecretReconciler) pushSecret(ctx context.Context, pushSecret *kubebaoiov1alpha1.BaoPushSecret) error {
+	log := r.Log.WithValues("baopushsecret", types.NamespacedName{
+		Name:      pushSecret.Name,
+		Namespace: pushSecret.Namespace,
+	})
+
+	source := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: pushSecret.Spec.SecretRef.Name, Namespace: pushSecret.Namespace}, source); err != nil {
+		return fmt.Errorf("failed to get secret %s: %w", pushSecret.Spec.SecretRef.Name, err)
+	}
+
+	data, err := pushData(pushSecret, source)
+	if err != nil {
+		return err
+	}
+	version := pushDataVersion(data)
+
+	baoClient, err := r.openBaoClientFor(ctx, pushSecret)
+	if err != nil {
+		return err
+	}
+
+	path := pushSecret.Spec.Path
+	owner := r.ownerMarker(pushSecret)
+
+	cas := 0
+	meta, err := baoClient.KVReadMetadata(ctx, path)
+	switch {
+	case openbao.IsNotFound(err):
+		// Маркер владельца ставится до записи данных: путь занят с первой версии
+		if err := baoClient.KVWriteMetadata(ctx, path, map[string]string{kubebaoiov1alpha1.OwnerMetadataKey: owner}); err != nil {
+			return fmt.Errorf("failed to claim %s: %w", path, err)
+		}
+		log.Info("Путь KV занят BaoPushSecret", "path", path, "owner", owner)
+	case err != nil:
+		r.forgetClientOnDenied(pushSecret, err)
+		return fmt.Errorf("failed to read metadata of %s: %w", path, err)
+	default:
+		if current := meta.CustomMetadata[kubebaoiov1alpha1.OwnerMetadataKey]; current != owner {
+			return &ownershipConflictError{path: path, owner: current}
+		}
+		if meta.CurrentVersion == pushSecret.Status.RemoteVersion && version == pushSecret.Status.SecretVersion {
+			log.V(1).Info("Данные не изменились, запись пропущена", "path", path, "version", meta.CurrentVersion)
+			return nil
+		}
+		cas = meta.CurrentVersion
+	}
+
+	remoteVersion, err := baoClient.KVWriteCAS(ctx, path, data, cas)
+	if err != nil {
+		if openbao.IsCASMismatch(err) {
+			return fmt.Errorf("secret %s was modified concurrently, will retry: %w", path, err)
+		}
+		r.forgetClientOnDenied(pushSecret, err)
+		return fmt.Errorf("failed to write secret to OpenBao: %w", err)
+	}
+
+	log.Info("Секрет записан в OpenBao", "path", path, "version", remoteVersion, "keysCount", len(data))
+	pushSecret.Status.SecretVersion = version
+	pushSecret.Status.RemoteVersion = remoteVersion
+	return nil
+}
+
+// pushData — выбранные ключи Secret под именами в OpenBao. KV хранит строки, поэтому
+// бинарные значения отклоняются: их нужно предварительно закодировать в Secret.
+func pushData(pushSecret *kubebaoiov1alpha1.BaoPushSecret, source *corev1.Secret) (map[string]interface{}, error) {
+	selected := pushSecret.Spec.Data
+	if len(selected) == 0 {
+		for key := range source.Data {
+			selected = append(selected, kubebaoiov1alpha1.PushSecretKey{SecretKey: key})
+		}
+	}
+
+	data := make(map[string]interface{}, len(selected))
+	for _, item := range selected {
+		value, ok := source.Data[item.SecretKey]
+		if !ok {
+			return nil, fmt.Errorf("key %s not found in secret %s", item.SecretKey, source.Name)
+		}
+		if !utf8.Valid(value) {
+			return nil, fmt.Errorf("key %s of secret %s is not valid UTF-8", item.SecretKey, source.Name)
+		}
+		remoteKey := item.RemoteKey
+		if remoteKey == "" {
+			remoteKey = item.SecretKey
+		}
+		data[remoteKey] = string(value)
+	}
+	return data, nil
+}
+
+// pushDataVersion — SHA256-хэш данных (первые 8 байт в hex), как у BaoSecret.
+func pushDataVersion(data map[string]interface{}) string {
+	jsonData, _ := json.Marshal(data)
+	hash := sha256.Sum256(jsonData)
+	return hex.EncodeToString(hash[:8])
+}
+
+// parseRefreshInterval — интервал перепроверки владельца и версии. По умолчанию 1 час, минимум 1 минута.
+func (r *BaoPushSecretReconciler) parseRefreshInterval(interval string) time.Duration {
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return time.Hour
+	}
+	if d < time.Minute {
+		return time.Minute
+	}
+	return d
+}
+
+// ownerMarker — значение маркера владельца: <кластер>/<namespace>/<name>.
+func (r *BaoPushSecretReconciler) ownerMarker(pushSecret *kubebaoiov1alpha1.BaoPushSecret) string {
+	return ownerMarker(r.ClusterName, pushSecret.Namespace, pushSecret.Name)
+}
+
+// ownerMarker — маркер владельца объекта OpenBao: <кластер>/<namespace>/<name>.
+func ownerMarker(cluster, namespace, name string) string {
+	if cluster == "" {
+		cluster = defaultClusterName
+	}
+	return strings.Join([]string{cluster, namespace, name}, "/")
+}
+
+// ownershipConflictError — объект OpenBao (секрет KV или политика) принадлежит другому
+// владельцу или создан не оператором.
+type ownershipConflictError struct {
+	kind  string
+	path  string
+	owner string
+}
+
+func (e *ownershipConflictError) Error() string {
+	kind := e.kind
+	if kind == "" {
+		kind = "secret"
+	}
+	if e.owner == "" {
+		return fmt.Sprintf("%s %s already exists in OpenBao and is not managed by kubebao", kind, e.path)
+	}
+	return fmt.Sprintf("%s %s is owned by %s", kind, e.path, e.owner)
+}
+
+func isOwnershipConflict(err error) bool {
+	var conflict *ownershipConflictError
+	return errors.As(err, &conflict)
+}
+
+// handleDeletion — при deletionPolicy: Delete удаляет секрет со всеми версиями, если путь
+// всё ещё принадлежит этому BaoPushSecret.
+func (r *BaoPushSecretReconciler) handleDeletion(ctx context.Context, pushSecret *kubebaoiov1alpha1.BaoPushSecret) (ctrl.Result, error) {
+	log := r.Log.WithValues("baopushsecret", types.NamespacedName{
+		Name:      pushSecret.Name,
+		Namespace: pushSecret.Namespace,
+	})
+
+	if !controllerutil.ContainsFinalizer(pushSecret, baoPushSecretFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if pushSecret.Spec.DeletionPolicy == kubebaoiov1alpha1.DeletionPolicyDelete {
+		if err := r.deleteRemote(ctx, pushSecret); err != nil {
+			log.Error(err, "Ошибка удаления секрета из OpenBao")
+			reason := pushFailureReason(err)
+			if r.Recorder != nil {
+				r.Recorder.Event(pushSecret, corev1.EventTypeWarning, reason, truncateMessage(err.Error()))
+			}
+			pushSecret.Status.ConsecutiveFailures++
+			r.setCondition(pushSecret, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionFalse, reason, err.Error())
+			if err := r.Status().Update(ctx, pushSecret); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: failureBackoff(pushSecret.Status.ConsecutiveFailures)}, nil
+		}
+	} else {
+		log.Info("Секрет в OpenBao сохраняется (deletionPolicy: Retain)", "path", pushSecret.Spec.Path)
+	}
+
+	controllerutil.RemoveFinalizer(pushSecret, baoPushSecretFinalizer)
+	if err := r.Update(ctx, pushSecret); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// deleteRemote удаляет путь KV, только если маркер владельца указывает на этот объект.
+func (r *BaoPushSecretReconciler) deleteRemote(ctx context.Context, pushSecret *kubebaoiov1alpha1.BaoPushSecret) error {
+	baoClient, err := r.openBaoClientFor(ctx, pushSecret)
+	if err != nil {
+		return err
+	}
+
+	path := pushSecret.Spec.Path
+	meta, err := baoClient.KVReadMetadata(ctx, path)
+	switch {
+	case openbao.IsNotFound(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to read metadata of %s: %w", path, err)
+	}
+
+	if current := meta.CustomMetadata[kubebaoiov1alpha1.OwnerMetadataKey]; current != r.ownerMarker(pushSecret) {
+		r.Log.Info("Путь KV принадлежит другому владельцу, удаление пропущено", "path", path, "owner", current)
+		return nil
+	}
+
+	if err := baoClient.KVDeleteMetadata(ctx, path); err != nil && !openbao.IsNotFound(err) {
+		return err
+	}
+	r.Log.Info("Секрет удалён из OpenBao", "path", path)
+	return nil
+}
+
+// openBaoClientFor — клиент tenant'а при OpenBaoRef/RoleName. Общий клиент оператора — только
+// с AllowOperatorIdentity, иначе BaoPushSecret без identity tenant'а отклоняется.
+func (r *BaoPushSecretReconciler) openBaoClientFor(ctx context.Context, pushSecret *kubebaoiov1alpha1.BaoPushSecret) (*openbao.Client, error) {
+	if pushSecret.Spec.OpenBaoRef == nil && pushSecret.Spec.RoleName == "" {
+		if !r.AllowOperatorIdentity {
+			return nil, &invalidSpecError{errs: field.ErrorList{field.Required(field.NewPath("spec", "roleName"),
+				"openbaoRef or roleName is required: pushing with the operator identity is disabled (--push-secret-operator-identity)")}}
+		}
+		if r.OpenBaoClient == nil {
+			return nil, &authenticationError{Err: fmt.Errorf("OpenBao client not configured")}
+		}
+		return r.OpenBaoClient, nil
+	}
+
+	if r.OpenBaoClients == nil {
+		return nil, &authenticationError{Err: fmt.Errorf("per-resource OpenBao connections are not configured")}
+	}
+	baoClient, err := r.OpenBaoClients.Get(ctx, pushSecret.Namespace, pushSecret.Spec.OpenBaoRef, pushSecret.Spec.RoleName)
+	if err != nil {
+		return nil, &authenticationError{Err: err}
+	}
+	return baoClient, nil
+}
+
+// pushFailureReason — failureReason, а отсутствующий исходный Secret — SecretNotFound.
+func pushFailureReason(err error) string {
+	if apierrors.IsNotFound(err) {
+		return kubebaoiov1alpha1.ReasonSecretNotFound
+	}
+	return failureReason(err)
+}
+
+// forgetClientOnDenied сбрасывает кешированный клиент tenant'а после 403.
+func (r *BaoPushSecretReconciler) forgetClientOnDenied(pushSecret *kubebaoiov1alpha1.BaoPushSecret, err error) {
+	if r.OpenBaoClients == nil || !openbao.IsPermissionDenied(err) {
+		return
+	}
+	if pushSecret.Spec.OpenBaoRef == nil && pushSecret.Spec.RoleName == "" {
+		return
+	}
+	r.OpenBaoClients.Forget(pushSecret.Namespace, pushSecret.Spec.OpenBaoRef, pushSecret.Spec.RoleName)
+}
+
+// setCondition — обновление условия в status.conditions (Ready, Synced)
+func (r *BaoPushSecretReconciler) setCondition(pushSecret *kubebaoiov1alpha1.BaoPushSecret, condType string, status metav1.ConditionStatus, reason, message string) {
+	now := metav1.Now()
+
+	var existingCondition *metav1.Condition
+	for i := range pushSecret.Status.Conditions {
+		if pushSecret.Status.Conditions[i].Type == condType {
+			existingCondition = &pushSecret.Status.Conditions[i]
+			break
+		}
+	}
+
+	if existingCondition != nil {
+		if existingCondition.Status != status {
+			existingCondition.LastTransitionTime = now
+		}
+		existingCondition.Status = status
+		existingCondition.Reason = reason
+		existingCondition.Message = message
+	} else {
+		pushSecret.Status.Conditions = append(pushSecret.Status.Conditions, metav1.Condition{
+			Type:               condType,
+			Status:             status,
+			LastTransitionTime: now,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+}
+
+// secretToPushSecrets — BaoPushSecret того же namespace, ссылающиеся на изменившийся Secret.
+func (r *BaoPushSecretReconciler) secretToPushSecrets(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &kubebaoiov1alpha1.BaoPushSecretList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace()), client.MatchingFields{pushSecretRefIndex: obj.GetName()}); err != nil {
+		r.Log.Error(err, "Ошибка поиска BaoPushSecret по Secret", "secret", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, item := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: item.Name, Namespace: item.Namespace}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *BaoPushSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &kubebaoiov1alpha1.BaoPushSecret{}, pushSecretRefIndex,
+		func(obj client.Object) []string {
+			return []string{obj.(*kubebaoiov1alpha1.BaoPushSecret).Spec.SecretRef.Name}
+		})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kubebaoiov1alpha1.BaoPushSecret{}, builder.WithPredicates(specChanged)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.secretToPushSecrets)).
+		Complete(r)
+}
@@ -0,0 +1,120 @@
+// Тесты BaoPushSecret: identity оператора только по флагу, причина и задержка повтора ошибок.
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+// newPushSecretReconciler — reconciler с BaoPushSecret без openbaoRef и roleName и его Secret.
+func newPushSecretReconciler(t *testing.T, baoClient *openbao.Client, allowOperatorIdentity bool) (*BaoPushSecretReconciler, *record.FakeRecorder, ctrl.Request) {
+	t.Helper()
+
+	pushSecret := &kubebaoiov1alpha1.BaoPushSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "app", Finalizers: []string{baoPushSecretFinalizer}},
+		Spec: kubebaoiov1alpha1.BaoPushSecretSpec{
+			SecretRef: kubebaoiov1alpha1.LocalSecretReference{Name: "app"},
+			Path:      "team-a/app",
+		},
+	}
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "app"},
+		Data:       map[string][]byte{"token": []byte("s3cr3t")},
+	}
+
+	scheme := newTestScheme(t)
+	require.NoError(t, corev1.AddToScheme(scheme))
+	recorder := record.NewFakeRecorder(10)
+	r := &BaoPushSecretReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(pushSecret, source).WithStatusSubresource(pushSecret).Build(),
+		Log:                   logr.Discard(),
+		OpenBaoClient:         baoClient,
+		Recorder:              recorder,
+		AllowOperatorIdentity: allowOperatorIdentity,
+	}
+	return r, recorder, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pushSecret)}
+}
+
+func TestBaoPushSecretRequiresTenantIdentity(t *testing.T) {
+	r, recorder, req := newPushSecretReconciler(t, nil, false)
+	ctx := context.Background()
+	initial := &kubebaoiov1alpha1.BaoPushSecret{}
+	require.NoError(t, r.Get(ctx, req.NamespacedName, initial))
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, result.RequeueAfter, minFailureBackoff)
+	assert.Less(t, result.RequeueAfter, 2*minFailureBackoff)
+
+	pushSecret := &kubebaoiov1alpha1.BaoPushSecret{}
+	require.NoError(t, r.Get(ctx, req.NamespacedName, pushSecret))
+	ready := meta.FindStatusCondition(pushSecret.Status.Conditions, kubebaoiov1alpha1.ConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, kubebaoiov1alpha1.ReasonInvalidSpec, ready.Reason)
+	assert.Equal(t, int32(1), pushSecret.Status.ConsecutiveFailures)
+	assert.Contains(t, <-recorder.Events, "Warning InvalidSpec")
+	// Запись status об ошибке не ставит объект в очередь повторно — повтор только через RequeueAfter
+	assertStatusWriteFiltered(t, initial, pushSecret)
+
+	// Задержка растёт с каждой ошибкой подряд
+	result, err = r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, result.RequeueAfter, 2*minFailureBackoff)
+	failed := &kubebaoiov1alpha1.BaoPushSecret{}
+	require.NoError(t, r.Get(ctx, req.NamespacedName, failed))
+	assert.Equal(t, int32(2), failed.Status.ConsecutiveFailures)
+	assertStatusWriteFiltered(t, pushSecret, failed)
+}
+
+func TestBaoPushSecretOperatorIdentity(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"errors":["Vault is sealed"]}`))
+	}))
+	t.Cleanup(server.Close)
+	baoClient, err := openbao.NewClient(&openbao.Config{
+		Address:      server.URL,
+		Token:        "test-token",
+		MaxRetries:   1,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+	}, hclog.NewNullLogger())
+	require.NoError(t, err)
+
+	r, recorder, req := newPushSecretReconciler(t, baoClient, true)
+	ctx := context.Background()
+
+	result, err := r.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Less(t, result.RequeueAfter, 2*minFailureBackoff)
+	assert.Contains(t, requests, "GET /v1/secret/metadata/team-a/app")
+
+	pushSecret := &kubebaoiov1alpha1.BaoPushSecret{}
+	require.NoError(t, r.Get(ctx, req.NamespacedName, pushSecret))
+	ready := meta.FindStatusCondition(pushSecret.Status.Conditions, kubebaoiov1alpha1.ConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, kubebaoiov1alpha1.ReasonOpenBaoUnavailable, ready.Reason)
+	assert.Contains(t, <-recorder.Events, "Warning OpenBaoUnavailable")
+}
@@ -0,0 +1,471 @@
+// Контроллер BaoRole — синхронизация ролей kubernetes и jwt auth OpenBao из BaoRole CRD.
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/acl"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+const (
+	// Финализатор для BaoRole — вызов handleDeletion при удалении
+	baoRoleFinalizer = "kubebao.io/role-finalizer"
+	// Индекс BaoRole по именам BaoPolicy из spec.policies
+	baoRolePolicyIndex = "spec.policies"
+)
+
+// BaoRoleReconciler — контроллер, синхронизирующий роли методов аутентификации OpenBao из BaoRole CRD.
+type BaoRoleReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	Log           logr.Logger
+	OpenBaoClient *openbao.Client
+	// Recorder — события Kubernetes по BaoRole
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=kubebao.io,resources=baoroles,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kubebao.io,resources=baoroles/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kubebao.io,resources=baoroles/finalizers,verbs=update
+
+// Reconcile — цикл согласования BaoRole. Записывает роль в OpenBao auth/<mount>/role/.
+func (r *BaoRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("baorole", req.NamespacedName)
+	log.V(1).Info("Начало reconcile BaoRole", "namespace", req.Namespace, "name", req.Name)
+
+	baoRole := &kubebaoiov1alpha1.BaoRole{}
+	if err := r.Get(ctx, req.NamespacedName, baoRole); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("BaoRole не найден — завершение")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Ошибка получения BaoRole")
+		return ctrl.Result{}, err
+	}
+
+	// Обработка удаления — снятие finalizer
+	if !baoRole.DeletionTimestamp.IsZero() {
+		return r.handleDeletion(ctx, baoRole)
+	}
+
+	if !controllerutil.ContainsFinalizer(baoRole, baoRoleFinalizer) {
+		controllerutil.AddFinalizer(baoRole, baoRoleFinalizer)
+		if err := r.Update(ctx, baoRole); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	previousVersion := baoRole.Status.RoleVersion
+	drifted, err := r.syncRole(ctx, baoRole)
+	if err != nil {
+		log.Error(err, "Ошибка синхронизации роли")
+		reason := failureReason(err)
+		r.setCondition(baoRole, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionFalse, reason, err.Error())
+		baoRole.Status.ConsecutiveFailures++
+		if r.Recorder != nil {
+			r.Recorder.Event(baoRole, corev1.EventTypeWarning, reason, truncateMessage(err.Error()))
+		}
+		if err := r.Status().Update(ctx, baoRole); err != nil {
+			return ctrl.Result{}, err
+		}
+		retryIn := failureBackoff(baoRole.Status.ConsecutiveFailures)
+		log.Info("Повтор синхронизации роли", "reason", reason, "failures", baoRole.Status.ConsecutiveFailures, "retryIn", retryIn)
+		return ctrl.Result{RequeueAfter: retryIn}, nil
+	}
+
+	if r.Recorder != nil && baoRole.Status.RoleVersion != previousVersion {
+		r.Recorder.Eventf(baoRole, corev1.EventTypeNormal, eventReasonSynced,
+			"Role %s/%s written to OpenBao", baoRole.Status.AppliedAuthMountPath, baoRole.Status.AppliedRoleName)
+	}
+	refreshInterval := r.refreshInterval(baoRole)
+	if drifted {
+		message := fmt.Sprintf("Role %s was modified in OpenBao outside kubebao and restored", baoRole.Status.AppliedRoleName)
+		if r.Recorder != nil {
+			r.Recorder.Event(baoRole, corev1.EventTypeWarning, eventReasonDrifted, message)
+		}
+		r.setCondition(baoRole, kubebaoiov1alpha1.ConditionTypeDrifted, metav1.ConditionTrue,
+			kubebaoiov1alpha1.ReasonDriftRestored, message)
+	} else {
+		r.clearRoleDrift(baoRole, refreshInterval)
+	}
+
+	baoRole.Status.ConsecutiveFailures = 0
+	baoRole.Status.ObservedGeneration = baoRole.Generation
+	now := metav1.Now()
+	baoRole.Status.LastSyncTime = &now
+	r.setCondition(baoRole, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionTrue,
+		kubebaoiov1alpha1.ReasonSuccess, "Role synced successfully")
+
+	if err := r.Status().Update(ctx, baoRole); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Роль успешно синхронизирована", "nextSync", refreshInterval)
+	return ctrl.Result{RequeueAfter: withJitter(refreshInterval)}, nil
+}
+
+// syncRole — сверяет роль в OpenBao с BaoRole и записывает её, если её нет или она отличается;
+// возвращает true, если отличие внесено вне оператора (дрейф) и роль восстановлена.
+func (r *BaoRoleReconciler) syncRole(ctx context.Context, baoRole *kubebaoiov1alpha1.BaoRole) (bool, error) {
+	log := r.Log.WithValues("baorole", types.NamespacedName{Name: baoRole.Name, Namespace: baoRole.Namespace})
+
+	baoClient := r.OpenBaoClient
+	if baoClient == nil {
+		return false, fmt.Errorf("OpenBao client not configured")
+	}
+
+	policies, err := r.resolvePolicies(ctx, baoRole)
+	if err != nil {
+		return false, err
+	}
+	params, err := roleParameters(baoRole, policies)
+	if err != nil {
+		return false, err
+	}
+
+	roleName := baoRole.GetRoleName()
+	mount := baoRole.GetAuthMountPath()
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode role parameters: %w", err)
+	}
+	hash := sha256.Sum256(append([]byte(mount+"/"+roleName+"\n"), encoded...))
+	version := hex.EncodeToString(hash[:8])
+	written := baoRole.Status.AppliedRoleName == roleName && baoRole.Status.AppliedAuthMountPath == mount
+	applied := written && baoRole.Status.RoleVersion == version
+
+	// Роль в OpenBao сравнивается при каждом reconcile: правка через API или CLI — дрейф
+	current, err := baoClient.ReadAuthRole(ctx, mount, roleName)
+	exists := true
+	switch {
+	case openbao.IsNotFound(err):
+		exists = false
+	case err != nil:
+		return false, fmt.Errorf("failed to read role from OpenBao: %w", err)
+	}
+
+	// Роль не хранит маркер владельца: своя только роль из status.appliedRoleName. Существующую
+	// роль, не записанную этим BaoRole, не перезаписываем и не присваиваем, даже если она совпадает:
+	// иначе удаление BaoRole удалило бы чужую роль
+	previousName, previousMount := baoRole.Status.AppliedRoleName, baoRole.Status.AppliedAuthMountPath
+	drifted := false
+	switch {
+	case exists && !written:
+		return false, &ownershipConflictError{kind: "auth role", path: mount + "/" + roleName}
+	case exists && roleMatches(current, params):
+		log.V(1).Info("Роль не изменилась, обновление пропущено")
+	default:
+		if previousName == "" {
+			// Имя первой роли сохраняется в статусе до записи: если статус после записи не обновится,
+			// следующий reconcile не примет свою роль за чужую. При переименовании статус хранит
+			// прежнюю роль до её удаления
+			baoRole.Status.AppliedRoleName = roleName
+			baoRole.Status.AppliedAuthMountPath = mount
+			if err := r.Status().Update(ctx, baoRole); err != nil {
+				return false, fmt.Errorf("failed to record role %s/%s in status: %w", mount, roleName, err)
+			}
+		}
+		drifted = applied
+		if drifted {
+			log.Info("Роль изменена в OpenBao вне kubebao, восстановление", "role", roleName, "deleted", !exists)
+		}
+		log.V(1).Info("Запись роли в OpenBao", "mount", mount, "role", roleName)
+		if err := baoClient.WriteAuthRole(ctx, mount, roleName, params); err != nil {
+			return false, fmt.Errorf("failed to write role to OpenBao: %w", err)
+		}
+		log.Info("Роль записана в OpenBao", "mount", mount, "role", roleName)
+	}
+
+	// Роль переименована или перенесена в другой mount — прежняя удаляется по deletionPolicy
+	if previousName != "" && (previousName != roleName || previousMount != mount) {
+		if err := r.deleteRole(ctx, baoClient, baoRole, previousMount, previousName); err != nil {
+			log.Error(err, "Ошибка удаления прежней роли", "mount", previousMount, "role", previousName)
+		}
+	}
+
+	baoRole.Status.RoleVersion = version
+	baoRole.Status.AppliedRoleName = roleName
+	baoRole.Status.AppliedAuthMountPath = mount
+	baoRole.Status.Policies = policies
+	return drifted, nil
+}
+
+// resolvePolicies — имена политик OpenBao для BaoPolicy из spec.policies. BaoPolicy, ещё не
+// записанный в OpenBao, — ошибка: роль без политики выдавала бы токены только с default.
+func (r *BaoRoleReconciler) resolvePolicies(ctx context.Context, baoRole *kubebaoiov1alpha1.BaoRole) ([]string, error) {
+	policies := make([]string, 0, len(baoRole.Spec.Policies))
+	for _, name := range baoRole.Spec.Policies {
+		baoPolicy := &kubebaoiov1alpha1.BaoPolicy{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: baoRole.Namespace, Name: name}, baoPolicy); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("BaoPolicy %s not found in namespace %s", name, baoRole.Namespace)
+			}
+			return nil, fmt.Errorf("failed to get BaoPolicy %s: %w", name, err)
+		}
+		if baoPolicy.Status.AppliedPolicyName == "" {
+			return nil, fmt.Errorf("BaoPolicy %s has not been written to OpenBao yet", name)
+		}
+		policies = append(policies, baoPolicy.Status.AppliedPolicyName)
+	}
+	return policies, nil
+}
+
+// roleParameters — параметры роли для auth/<mount>/role/<name>. Незаданные поля передаются
+// пустыми, чтобы удаление поля из spec сбрасывало его и в OpenBao.
+func roleParameters(baoRole *kubebaoiov1alpha1.BaoRole, policies []string) (map[string]interface{}, error) {
+	spec := &baoRole.Spec
+	params := map[string]interface{}{
+		"token_policies": policies,
+	}
+
+	ttls := []struct{ param, field, value string }{
+		{"token_ttl", "tokenTTL", spec.TokenTTL},
+		{"token_max_ttl", "tokenMaxTTL", spec.TokenMaxTTL},
+	}
+	for _, ttl := range ttls {
+		var seconds int64
+		if ttl.value != "" {
+			d, err := acl.ParseTTL(ttl.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", ttl.field, err)
+			}
+			seconds = int64(d / time.Second)
+		}
+		params[ttl.param] = seconds
+	}
+
+	switch spec.AuthMethod {
+	case "", kubebaoiov1alpha1.AuthMethodKubernetes:
+		if len(spec.BoundServiceAccountNames) == 0 {
+			return nil, fmt.Errorf("boundServiceAccountNames is required for the kubernetes auth method")
+		}
+		namespaces := spec.BoundServiceAccountNamespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{baoRole.Namespace}
+		}
+		params["bound_service_account_names"] = spec.BoundServiceAccountNames
+		params["bound_service_account_namespaces"] = namespaces
+		params["audience"] = spec.Audience
+	case kubebaoiov1alpha1.AuthMethodJWT:
+		if len(spec.BoundAudiences) == 0 && spec.BoundSubject == "" {
+			return nil, fmt.Errorf("boundAudiences or boundSubject is required for the jwt auth method")
+		}
+		userClaim := spec.UserClaim
+		if userClaim == "" {
+			userClaim = "sub"
+		}
+		audiences := spec.BoundAudiences
+		if audiences == nil {
+			audiences = []string{}
+		}
+		params["role_type"] = kubebaoiov1alpha1.AuthMethodJWT
+		params["user_claim"] = userClaim
+		params["bound_audiences"] = audiences
+		params["bound_subject"] = spec.BoundSubject
+	default:
+		return nil, fmt.Errorf("unsupported auth method %q", spec.AuthMethod)
+	}
+	return params, nil
+}
+
+// roleMatches — параметры роли в OpenBao совпадают с желаемыми. Сравниваются только поля,
+// которыми управляет BaoRole; порядок элементов списков не важен.
+func roleMatches(current, desired map[string]interface{}) bool {
+	for key, value := range desired {
+		if normalizeRoleValue(current[key]) != normalizeRoleValue(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeRoleValue — значение параметра роли в виде строки: OpenBao возвращает числа как
+// json.Number, списки — как []interface{}.
+func normalizeRoleValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		items := slices.Clone(v)
+		slices.Sort(items)
+		return strings.Join(items, "\n")
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, normalizeRoleValue(item))
+		}
+		slices.Sort(items)
+		return strings.Join(items, "\n")
+	}
+	return fmt.Sprint(value)
+}
+
+// deleteRole удаляет роль при deletionPolicy: Delete. Вызывается только для роли из
+// status.appliedRoleName — syncRole записывает туда лишь созданные этим BaoRole роли, чужие роли
+// не присваиваются. Отсутствующая роль считается удалённой.
+func (r *BaoRoleReconciler) deleteRole(ctx context.Context, baoClient *openbao.Client, baoRole *kubebaoiov1alpha1.BaoRole, mount, roleName string) error {
+	log := r.Log.WithValues("baorole", baoRole.Namespace+"/"+baoRole.Name, "mount", mount, "role", roleName)
+
+	if baoRole.Spec.DeletionPolicy == kubebaoiov1alpha1.DeletionPolicyRetain {
+		log.Info("Роль оставлена в OpenBao (deletionPolicy: Retain)")
+		return nil
+	}
+	if err := baoClient.DeleteAuthRole(ctx, mount, roleName); err != nil {
+		return err
+	}
+	log.Info("Роль удалена из OpenBao")
+	return nil
+}
+
+// handleDeletion — при deletionPolicy: Delete удаляет роль из OpenBao. Недоступный OpenBao
+// задерживает снятие finalizer с экспоненциальной задержкой.
+func (r *BaoRoleReconciler) handleDeletion(ctx context.Context, baoRole *kubebaoiov1alpha1.BaoRole) (ctrl.Result, error) {
+	log := r.Log.WithValues("baorole", types.NamespacedName{Name: baoRole.Name, Namespace: baoRole.Namespace})
+
+	if controllerutil.ContainsFinalizer(baoRole, baoRoleFinalizer) {
+		roleName, mount := baoRole.Status.AppliedRoleName, baoRole.Status.AppliedAuthMountPath
+		switch {
+		case roleName == "":
+		case r.OpenBaoClient == nil:
+			log.Info("Клиент OpenBao не настроен, роль не удалена", "role", roleName)
+		default:
+			if err := r.deleteRole(ctx, r.OpenBaoClient, baoRole, mount, roleName); err != nil {
+				log.Error(err, "Ошибка удаления роли из OpenBao", "role", roleName)
+				reason := failureReason(err)
+				if r.Recorder != nil {
+					r.Recorder.Event(baoRole, corev1.EventTypeWarning, reason, truncateMessage(err.Error()))
+				}
+				baoRole.Status.ConsecutiveFailures++
+				r.setCondition(baoRole, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionFalse, reason, err.Error())
+				if err := r.Status().Update(ctx, baoRole); err != nil {
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: failureBackoff(baoRole.Status.ConsecutiveFailures)}, nil
+			}
+		}
+
+		controllerutil.RemoveFinalizer(baoRole, baoRoleFinalizer)
+		if err := r.Update(ctx, baoRole); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// refreshInterval — интервал сверки из spec.refreshInterval, по умолчанию и минимум как у BaoPolicy.
+func (r *BaoRoleReconciler) refreshInterval(baoRole *kubebaoiov1alpha1.BaoRole) time.Duration {
+	if baoRole.Spec.RefreshInterval == "" {
+		return defaultPolicyRefreshInterval
+	}
+	d, err := time.ParseDuration(baoRole.Spec.RefreshInterval)
+	if err != nil {
+		return defaultPolicyRefreshInterval
+	}
+	if d < minPolicyRefreshInterval {
+		return minPolicyRefreshInterval
+	}
+	return d
+}
+
+// clearRoleDrift снимает Drifted спустя refreshInterval без повторного дрейфа.
+func (r *BaoRoleReconciler) clearRoleDrift(baoRole *kubebaoiov1alpha1.BaoRole, refreshInterval time.Duration) {
+	for _, cond := range baoRole.Status.Conditions {
+		if cond.Type != kubebaoiov1alpha1.ConditionTypeDrifted {
+			continue
+		}
+		if cond.Status == metav1.ConditionTrue && time.Since(cond.LastTransitionTime.Time) >= refreshInterval {
+			r.setCondition(baoRole, kubebaoiov1alpha1.ConditionTypeDrifted, metav1.ConditionFalse,
+				kubebaoiov1alpha1.ReasonInSync, "Role matches BaoRole")
+		}
+		return
+	}
+}
+
+// setCondition sets a condition on the BaoRole status
+func (r *BaoRoleReconciler) setCondition(baoRole *kubebaoiov1alpha1.BaoRole, condType string, status metav1.ConditionStatus, reason, message string) {
+	now := metav1.Now()
+
+	for i := range baoRole.Status.Conditions {
+		existing := &baoRole.Status.Conditions[i]
+		if existing.Type != condType {
+			continue
+		}
+		if existing.Status != status {
+			existing.LastTransitionTime = now
+		}
+		existing.Status = status
+		existing.Reason = reason
+		existing.Message = message
+		return
+	}
+
+	baoRole.Status.Conditions = append(baoRole.Status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             status,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// policyToRoles — BaoRole, ссылающиеся на изменённый BaoPolicy: новое имя политики попадает в роль сразу.
+func (r *BaoRoleReconciler) policyToRoles(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &kubebaoiov1alpha1.BaoRoleList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace()), client.MatchingFields{baoRolePolicyIndex: obj.GetName()}); err != nil {
+		r.Log.Error(err, "Ошибка поиска BaoRole по BaoPolicy", "baopolicy", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, item := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: item.Name, Namespace: item.Namespace}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *BaoRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &kubebaoiov1alpha1.BaoRole{}, baoRolePolicyIndex,
+		func(obj client.Object) []string {
+			return obj.(*kubebaoiov1alpha1.BaoRole).Spec.Policies
+		})
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kubebaoiov1alpha1.BaoRole{}, builder.WithPredicates(specChanged)).
+		Watches(&kubebaoiov1alpha1.BaoPolicy{}, handler.EnqueueRequestsFromMapFunc(r.policyToRoles)).
+		Complete(r)
+}
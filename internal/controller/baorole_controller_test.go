@@ -0,0 +1,353 @@
+// Тесты параметров ролей BaoRole и владения ролями OpenBao.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+// fakeOpenBao — in-memory OpenBao для тестов: хранит данные по пути и записывает запросы.
+type fakeOpenBao struct {
+	mu       sync.Mutex
+	data     map[string]map[string]interface{}
+	requests []string
+}
+
+// newFakeOpenBao — сервер fakeOpenBao и клиент к нему.
+func newFakeOpenBao(t *testing.T) (*fakeOpenBao, *openbao.Client) {
+	t.Helper()
+
+	bao := &fakeOpenBao{data: map[string]map[string]interface{}{}}
+	server := httptest.NewServer(http.HandlerFunc(bao.serveHTTP))
+	t.Cleanup(server.Close)
+
+	baoClient, err := openbao.NewClient(&openbao.Config{
+		Address:    server.URL,
+		Token:      "test-token",
+		MaxRetries: 1,
+	}, hclog.NewNullLogger())
+	require.NoError(t, err)
+	return bao, baoClient
+}
+
+func (b *fakeOpenBao) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	path := r.URL.Path[len("/v1/"):]
+	b.requests = append(b.requests, r.Method+" "+path)
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := b.data[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	case http.MethodPut, http.MethodPost:
+		data := map[string]interface{}{}
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		_ = decoder.Decode(&data)
+		b.data[path] = data
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(b.data, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// writes — запросы, изменившие данные (PUT, POST, DELETE).
+func (b *fakeOpenBao) writes() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var writes []string
+	for _, req := range b.requests {
+		if req[:4] != "GET " {
+			writes = append(writes, req)
+		}
+	}
+	return writes
+}
+
+// newTestScheme — схема с типами kubebao.io/v1alpha1 для fake-клиента.
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, kubebaoiov1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+func TestRoleParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    kubebaoiov1alpha1.BaoRoleSpec
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "kubernetes defaults to the BaoRole namespace",
+			spec: kubebaoiov1alpha1.BaoRoleSpec{BoundServiceAccountNames: []string{"app"}, TokenTTL: "1h"},
+			want: map[string]interface{}{
+				"token_policies":                   []string{"default_app"},
+				"token_ttl":                        int64(3600),
+				"token_max_ttl":                    int64(0),
+				"bound_service_account_names":      []string{"app"},
+				"bound_service_account_namespaces": []string{"default"},
+				"audience":                         "",
+			},
+		},
+		{
+			name: "jwt defaults user_claim and bound_audiences",
+			spec: kubebaoiov1alpha1.BaoRoleSpec{
+				AuthMethod:   kubebaoiov1alpha1.AuthMethodJWT,
+				BoundSubject: "system:serviceaccount:default:app",
+				TokenMaxTTL:  "24h",
+			},
+			want: map[string]interface{}{
+				"token_policies":  []string{"default_app"},
+				"token_ttl":       int64(0),
+				"token_max_ttl":   int64(86400),
+				"role_type":       kubebaoiov1alpha1.AuthMethodJWT,
+				"user_claim":      "sub",
+				"bound_audiences": []string{},
+				"bound_subject":   "system:serviceaccount:default:app",
+			},
+		},
+		{
+			name:    "kubernetes without service accounts",
+			spec:    kubebaoiov1alpha1.BaoRoleSpec{},
+			wantErr: "boundServiceAccountNames is required",
+		},
+		{
+			name:    "jwt without audiences and subject",
+			spec:    kubebaoiov1alpha1.BaoRoleSpec{AuthMethod: kubebaoiov1alpha1.AuthMethodJWT},
+			wantErr: "boundAudiences or boundSubject is required",
+		},
+		{
+			name:    "invalid TTL",
+			spec:    kubebaoiov1alpha1.BaoRoleSpec{BoundServiceAccountNames: []string{"app"}, TokenTTL: "soon"},
+			wantErr: "invalid tokenTTL",
+		},
+		{
+			name:    "unsupported auth method",
+			spec:    kubebaoiov1alpha1.BaoRoleSpec{AuthMethod: "ldap"},
+			wantErr: `unsupported auth method "ldap"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baoRole := &kubebaoiov1alpha1.BaoRole{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+				Spec:       tt.spec,
+			}
+			params, err := roleParameters(baoRole, []string{"default_app"})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, params)
+		})
+	}
+}
+
+func TestNormalizeRoleValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "nil", value: nil, want: ""},
+		{name: "string", value: "sub", want: "sub"},
+		{name: "json.Number", value: json.Number("3600"), want: "3600"},
+		{name: "int64 TTL", value: int64(3600), want: "3600"},
+		{name: "float64", value: float64(3600), want: "3600"},
+		{name: "[]string is sorted", value: []string{"b", "a"}, want: "a\nb"},
+		{name: "[]interface{} is sorted", value: []interface{}{"b", "a"}, want: "a\nb"},
+		{name: "[]interface{} of numbers", value: []interface{}{json.Number("2"), json.Number("1")}, want: "1\n2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeRoleValue(tt.value))
+		})
+	}
+}
+
+func TestRoleMatches(t *testing.T) {
+	desired := map[string]interface{}{
+		"token_policies":              []string{"default_app", "default_db"},
+		"token_ttl":                   int64(3600),
+		"bound_service_account_names": []string{"app"},
+		"audience":                    "",
+	}
+
+	tests := []struct {
+		name    string
+		current map[string]interface{}
+		matches bool
+	}{
+		{
+			name: "as returned by OpenBao",
+			current: map[string]interface{}{
+				"token_policies":              []interface{}{"default_db", "default_app"},
+				"token_ttl":                   json.Number("3600"),
+				"bound_service_account_names": []interface{}{"app"},
+				"audience":                    "",
+				"token_type":                  "default",
+			},
+			matches: true,
+		},
+		{
+			name: "missing empty field",
+			current: map[string]interface{}{
+				"token_policies":              []interface{}{"default_app", "default_db"},
+				"token_ttl":                   json.Number("3600"),
+				"bound_service_account_names": []interface{}{"app"},
+			},
+			matches: true,
+		},
+		{
+			name: "different TTL",
+			current: map[string]interface{}{
+				"token_policies":              []interface{}{"default_app", "default_db"},
+				"token_ttl":                   json.Number("60"),
+				"bound_service_account_names": []interface{}{"app"},
+			},
+		},
+		{
+			name: "extra policy",
+			current: map[string]interface{}{
+				"token_policies":              []interface{}{"default_app", "default_db", "root"},
+				"token_ttl":                   json.Number("3600"),
+				"bound_service_account_names": []interface{}{"app"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, roleMatches(tt.current, desired))
+		})
+	}
+}
+
+func TestSyncRoleDoesNotAdoptForeignRole(t *testing.T) {
+	bao, baoClient := newFakeOpenBao(t)
+	baoRole := &kubebaoiov1alpha1.BaoRole{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Finalizers: []string{baoRoleFinalizer}},
+		Spec:       kubebaoiov1alpha1.BaoRoleSpec{BoundServiceAccountNames: []string{"app"}},
+	}
+	params, err := roleParameters(baoRole, []string{})
+	require.NoError(t, err)
+	// Роль с теми же параметрами создана вне оператора
+	bao.data["auth/kubernetes/role/default_app"] = params
+
+	r := &BaoRoleReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(baoRole).WithStatusSubresource(baoRole).Build(),
+		Log:           logr.Discard(),
+		OpenBaoClient: baoClient,
+	}
+
+	_, err = r.syncRole(context.Background(), baoRole)
+	var conflict *ownershipConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Empty(t, baoRole.Status.AppliedRoleName)
+
+	// Удаление BaoRole чужую роль не трогает
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(baoRole), baoRole))
+	_, err = r.handleDeletion(context.Background(), baoRole)
+	require.NoError(t, err)
+	assert.Empty(t, bao.writes())
+	assert.Contains(t, bao.data, "auth/kubernetes/role/default_app")
+}
+
+func TestSyncRoleCreatesAndDeletesOwnRole(t *testing.T) {
+	bao, baoClient := newFakeOpenBao(t)
+	baoRole := &kubebaoiov1alpha1.BaoRole{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Finalizers: []string{baoRoleFinalizer}},
+		Spec:       kubebaoiov1alpha1.BaoRoleSpec{BoundServiceAccountNames: []string{"app"}},
+	}
+	r := &BaoRoleReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(baoRole).WithStatusSubresource(baoRole).Build(),
+		Log:           logr.Discard(),
+		OpenBaoClient: baoClient,
+	}
+
+	drifted, err := r.syncRole(context.Background(), baoRole)
+	require.NoError(t, err)
+	assert.False(t, drifted)
+	assert.Equal(t, "default_app", baoRole.Status.AppliedRoleName)
+	assert.Contains(t, bao.data, "auth/kubernetes/role/default_app")
+
+	// Имя роли сохранено в статусе до записи роли
+	stored := &kubebaoiov1alpha1.BaoRole{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(baoRole), stored))
+	assert.Equal(t, "default_app", stored.Status.AppliedRoleName)
+
+	// Повторный reconcile признаёт роль своей, даже если статус после записи не обновился
+	_, err = r.syncRole(context.Background(), stored)
+	require.NoError(t, err)
+
+	_, err = r.handleDeletion(context.Background(), baoRole)
+	require.NoError(t, err)
+	assert.NotContains(t, bao.data, "auth/kubernetes/role/default_app")
+}
+
+func TestRoleFailuresDoNotRequeueImmediately(t *testing.T) {
+	bao, baoClient := newFakeOpenBao(t)
+	baoRole := &kubebaoiov1alpha1.BaoRole{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 1, Finalizers: []string{baoRoleFinalizer}},
+		Spec:       kubebaoiov1alpha1.BaoRoleSpec{BoundServiceAccountNames: []string{"app"}},
+	}
+	// Чужая роль с тем же именем — каждый reconcile завершается конфликтом владения
+	bao.data["auth/kubernetes/role/default_app"] = map[string]interface{}{"token_policies": []interface{}{"root"}}
+
+	r := &BaoRoleReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(baoRole).WithStatusSubresource(baoRole).Build(),
+		Log:           logr.Discard(),
+		OpenBaoClient: baoClient,
+	}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(baoRole)}
+
+	previous := &kubebaoiov1alpha1.BaoRole{}
+	require.NoError(t, r.Get(ctx, req.NamespacedName, previous))
+	for failures := int32(1); failures <= 3; failures++ {
+		result, err := r.Reconcile(ctx, req)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, result.RequeueAfter, minFailureBackoff<<(failures-1))
+
+		current := &kubebaoiov1alpha1.BaoRole{}
+		require.NoError(t, r.Get(ctx, req.NamespacedName, current))
+		assert.Equal(t, failures, current.Status.ConsecutiveFailures)
+		assertStatusWriteFiltered(t, previous, current)
+		previous = current
+	}
+	assert.Empty(t, bao.writes())
+}
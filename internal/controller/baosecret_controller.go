@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,9 +16,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
 	"github.com/kubebao/kubebao/internal/openbao"
@@ -38,6 +45,16 @@ type BaoSecretReconciler struct {
 	Scheme       *runtime.Scheme
 	Log          logr.Logger
 	OpenBaoClient *openbao.Client
+	// OpenBaoClients — клиенты для BaoSecret с OpenBaoRef/RoleName (identity ServiceAccount tenant'а)
+	OpenBaoClients *OpenBaoClients
+	// LeaseManager — продление аренд динамических секретов; события аренд запускают reconcile
+	LeaseManager *openbao.LeaseManager
+	// EventWatcher — подписка на kv-v2/data-write; nil — только опрос по refreshInterval
+	EventWatcher *openbao.EventWatcher
+	// EventResyncInterval — опрос KV при активной подписке (по умолчанию DefaultEventResyncInterval)
+	EventResyncInterval time.Duration
+	// Recorder — события Kubernetes по BaoSecret (например, Drifted)
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=kubebao.io,resources=baosecrets,verbs=get;list;watch;create;update;patch;delete
@@ -102,20 +119,38 @@ func (r *BaoSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		"targetSecret", baoSecret.Spec.Target.Name,
 		"targetNamespace", baoSecret.Spec.Target.Namespace,
 	)
+	// Ручное изменение целевого Secret фиксируется до записи, которая его откатит
+	drifted, err := r.detectDrift(ctx, baoSecret)
+	if err != nil {
+		log.Error(err, "Ошибка проверки дрейфа целевого Secret")
+	}
+
+	previousVersion := baoSecret.Status.SecretVersion
 	if err := r.syncSecret(ctx, baoSecret); err != nil {
 		log.Error(err, "Ошибка синхронизации секрета")
+		reason := failureReason(err)
 		r.setCondition(baoSecret, kubebaoiov1alpha1.ConditionTypeSynced, metav1.ConditionFalse,
-			kubebaoiov1alpha1.ReasonFailed, err.Error())
+			reason, err.Error())
 		r.setCondition(baoSecret, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionFalse,
-			kubebaoiov1alpha1.ReasonFailed, "Failed to sync secret")
+			reason, "Failed to sync secret")
+		r.recordSyncFailure(baoSecret, err)
+		if r.Recorder != nil {
+			r.Recorder.Event(baoSecret, corev1.EventTypeWarning, reason, truncateMessage(err.Error()))
+		}
 		if err := r.Status().Update(ctx, baoSecret); err != nil {
 			return ctrl.Result{}, err
 		}
-		log.Info("Повторная попытка через 30 секунд")
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		// Экспоненциальная задержка по числу ошибок подряд: недоступный OpenBao или
+		// отсутствующий путь не опрашиваются каждые несколько секунд
+		retryIn := failureBackoff(baoSecret.Status.ConsecutiveFailures)
+		log.Info("Повторная попытка", "reason", reason, "failures", baoSecret.Status.ConsecutiveFailures, "retryIn", retryIn)
+		return ctrl.Result{RequeueAfter: retryIn}, nil
 	}
 
 	// Обновление статуса BaoSecret — условия Ready/Synced, время последней синхронизации
+	if !drifted {
+		r.clearDrift(baoSecret, r.parseRefreshInterval(baoSecret.Spec.RefreshInterval))
+	}
 	baoSecret.Status.ObservedGeneration = baoSecret.Generation
 	now := metav1.Now()
 	baoSecret.Status.LastSyncTime = &now
@@ -123,6 +158,21 @@ func (r *BaoSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		kubebaoiov1alpha1.ReasonSuccess, "Secret synced successfully")
 	r.setCondition(baoSecret, kubebaoiov1alpha1.ConditionTypeReady, metav1.ConditionTrue,
 		kubebaoiov1alpha1.ReasonSuccess, "Secret is ready")
+	r.recordSyncSuccess(baoSecret)
+	if r.Recorder != nil && baoSecret.Status.SyncedSecretName != "" && baoSecret.Status.SecretVersion != previousVersion {
+		r.Recorder.Eventf(baoSecret, corev1.EventTypeNormal, eventReasonSynced,
+			"Secret %s synced, version %s", baoSecret.Status.SyncedSecretName, baoSecret.Status.SecretVersion)
+	}
+
+	// Перезапуск workload'ов, читающих Secret через env; ошибка не отменяет успешную синхронизацию
+	rolloutWait, err := r.rollout(ctx, baoSecret)
+	if err != nil {
+		log.Error(err, "Ошибка перезапуска workload'ов")
+		if r.Recorder != nil {
+			r.Recorder.Event(baoSecret, corev1.EventTypeWarning, eventReasonRolloutFailed, err.Error())
+		}
+		rolloutWait = withJitter(30 * time.Second)
+	}
 
 	if err := r.Status().Update(ctx, baoSecret); err != nil {
 		log.Error(err, "Ошибка обновления статуса BaoSecret")
@@ -131,6 +181,18 @@ func (r *BaoSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	// Планирование следующей синхронизации по refreshInterval (по умолчанию 1 час)
 	refreshInterval := r.parseRefreshInterval(baoSecret.Spec.RefreshInterval)
+	if baoSecret.Spec.Wrapping != nil {
+		refreshInterval = r.wrappingRecheckInterval(baoSecret, refreshInterval)
+	} else if isDynamicEngine(baoSecret.Spec.SecretEngine) {
+		// Динамический секрет обновляется по TTL аренды, а не по refreshInterval
+		refreshInterval = r.dynamicRequeueInterval(baoSecret, refreshInterval)
+	} else {
+		// Сроки аренд и wrapping-токенов точные, разброс добавляется только к опросу KV
+		refreshInterval = withJitter(r.eventDrivenInterval(baoSecret, refreshInterval))
+	}
+	if rolloutWait > 0 && rolloutWait < refreshInterval {
+		refreshInterval = rolloutWait
+	}
 	log.Info("Секрет успешно синхронизирован", "secret", baoSecret.Spec.Target.Name, "nextSync", refreshInterval)
 	
 	return ctrl.Result{RequeueAfter: refreshInterval}, nil
@@ -144,57 +206,144 @@ func (r *BaoSecretReconciler) syncSecret(ctx context.Context, baoSecret *kubebao
 		Namespace: baoSecret.Namespace,
 	})
 
-	// Клиент OpenBao — общий для всех BaoSecret (настраивается в main через env/config)
-	baoClient := r.OpenBaoClient
-	if baoClient == nil {
-		return fmt.Errorf("OpenBao client not configured")
+	// Spec без webhook может быть недопустимым: ошибка без обращения к OpenBao
+	if errs := ValidateBaoSecret(baoSecret.Namespace, &baoSecret.Spec); len(errs) > 0 {
+		return &invalidSpecError{errs: errs}
+	}
+
+	// Клиент OpenBao — общий клиент оператора или клиент с identity tenant'а (OpenBaoRef/RoleName)
+	baoClient, err := r.openBaoClientFor(ctx, baoSecret)
+	if err != nil {
+		r.setCondition(baoSecret, kubebaoiov1alpha1.ConditionTypeAuthenticated, metav1.ConditionFalse,
+			kubebaoiov1alpha1.ReasonAuthenticationFailed, err.Error())
+		return &authenticationError{Err: err}
+	}
+	r.setCondition(baoSecret, kubebaoiov1alpha1.ConditionTypeAuthenticated, metav1.ConditionTrue,
+		kubebaoiov1alpha1.ReasonSuccess, "Authenticated to OpenBao")
+
+	// Secret-zero: в Secret кладётся только одноразовый wrapping-токен
+	if baoSecret.Spec.Wrapping != nil {
+		return r.syncWrappedSecret(ctx, baoSecret, baoClient)
+	}
+
+	// Динамические движки (database, pki, aws и т.п.) выдают учётные данные с арендой
+	if isDynamicEngine(baoSecret.Spec.SecretEngine) {
+		return r.syncDynamicSecret(ctx, baoSecret, baoClient)
 	}
 
 	// Чтение секрета из OpenBao KV v2 (путь вида secret/data/myapp/database)
-	log.Info("Чтение секрета из OpenBao KV", "path", baoSecret.Spec.SecretPath)
-	secretData, err := baoClient.KVRead(ctx, baoSecret.Spec.SecretPath)
+	secretData := map[string]interface{}{}
+	metadata := map[string]interface{}{}
+	if baoSecret.Spec.SecretPath != "" {
+		log.Info("Чтение секрета из OpenBao KV", "path", baoSecret.Spec.SecretPath)
+		secretData, metadata, err = baoClient.KVReadWithMetadata(ctx, baoSecret.Spec.SecretPath)
+		if err != nil {
+			log.Error(err, "Ошибка чтения секрета из OpenBao", "path", baoSecret.Spec.SecretPath)
+			r.forgetClientOnDenied(baoSecret, err)
+			return fmt.Errorf("failed to read secret from OpenBao: %w", err)
+		}
+		log.Info("Секрет прочитан из OpenBao", "keysCount", len(secretData), "path", baoSecret.Spec.SecretPath)
+		metadata["path"] = baoSecret.Spec.SecretPath
+	}
+
+	// Дополнительные источники dataFrom объединяются после secretPath
+	sources, err := r.readDataFrom(ctx, baoClient, baoSecret)
 	if err != nil {
-		log.Error(err, "Ошибка чтения секрета из OpenBao", "path", baoSecret.Spec.SecretPath)
-		return fmt.Errorf("failed to read secret from OpenBao: %w", err)
+		return err
+	}
+
+	data, err := r.renderSecretData(baoSecret, secretData, metadata, sources)
+	if err != nil {
+		return err
+	}
+
+	baoSecret.Status.WrappingTokenExpiry = nil
+	if err := r.writeTargetSecret(ctx, baoSecret, data, nil); err != nil {
+		return err
+	}
+	r.setSourceVersion(baoSecret, metadata, sources)
+
+	// Аренды, оставшиеся от динамического движка, больше не нужны
+	if baoSecret.Status.LeaseID != "" {
+		r.releaseDynamicLeases(ctx, baoSecret, baoClient)
 	}
-	log.Info("Секрет прочитан из OpenBao", "keysCount", len(secretData), "path", baoSecret.Spec.SecretPath)
+	return nil
+}
+
+// renderSecretData — данные целевого Secret из секрета OpenBao: один ключ (SecretKey) или все,
+// затем источники dataFrom и шаблон, если задан. metadata доступны шаблону как .Metadata;
+// .Data без dataFrom содержит все ключи secretPath, с dataFrom — выбранные ключи всех источников.
+func (r *BaoSecretReconciler) renderSecretData(baoSecret *kubebaoiov1alpha1.BaoSecret, secretData, metadata map[string]interface{}, sources []sourceData) (map[string][]byte, error) {
+	log := r.Log.WithValues("baosecret", types.NamespacedName{
+		Name:      baoSecret.Name,
+		Namespace: baoSecret.Namespace,
+	})
 
-	// Извлечение конкретного ключа (SecretKey) или всех ключей
-	var data map[string][]byte
+	// Извлечение конкретного ключа (SecretKey) или всех ключей — как keys у dataFrom
+	primary := kubebaoiov1alpha1.SecretSource{Path: baoSecret.Spec.SecretPath}
 	if baoSecret.Spec.SecretKey != "" {
-		value, ok := secretData[baoSecret.Spec.SecretKey]
-		if !ok {
-			return fmt.Errorf("key %s not found in secret", baoSecret.Spec.SecretKey)
-		}
-		data = map[string][]byte{
-			baoSecret.Spec.SecretKey: []byte(fmt.Sprintf("%v", value)),
-		}
-	} else {
-		data = make(map[string][]byte)
-		for k, v := range secretData {
-			data[k] = []byte(fmt.Sprintf("%v", v))
-		}
+		primary.Keys = []string{baoSecret.Spec.SecretKey}
+	}
+	selected, err := selectSourceKeys(primary, secretData)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := mergeSources(baoSecret.Spec.ConflictPolicy, append([]sourceData{{path: baoSecret.Spec.SecretPath, data: selected}}, sources...))
+	if err != nil {
+		return nil, err
 	}
 
-	// Применение шаблона StringData/Data — подстановка {{ .Data.key }} из sourceData
+	data := make(map[string][]byte, len(merged))
+	for k, v := range merged {
+		data[k] = []byte(fmt.Sprintf("%v", v))
+	}
+
+	// Применение шаблона StringData/Data — text/template над {{ .Data }} и {{ .Metadata }}
 	if baoSecret.Spec.Template != nil {
-		data, err = r.applyTemplate(data, baoSecret.Spec.Template, secretData)
+		// С dataFrom .Data — объединённые источники после выбора ключей: невыбранные ключи
+		// secretPath не участвуют в конфликтах
+		templateData := secretData
+		if len(sources) > 0 {
+			templateData = merged
+		}
+
+		data, err = r.applyTemplate(data, baoSecret.Spec.Template, templateContext{Data: templateData, Metadata: metadata})
 		if err != nil {
 			log.Error(err, "Ошибка применения шаблона")
-			return fmt.Errorf("failed to apply template: %w", err)
+			return nil, fmt.Errorf("failed to apply template: %w", err)
 		}
 		log.V(1).Info("Шаблон применён")
 	}
 
+	return data, nil
+}
+
+// writeTargetSecret — создаёт/обновляет целевой Secret с данными data и обновляет ссылки
+// на него в статусе. mutate (если задан) вносит дополнительные изменения, например аннотации.
+// Поведение определяется creationPolicy: Owner/Orphan создают Secret и не трогают чужой,
+// Merge пишет только свои ключи в существующий Secret, None ничего не записывает.
+func (r *BaoSecretReconciler) writeTargetSecret(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret, data map[string][]byte, mutate func(secret *corev1.Secret)) error {
+	log := r.Log.WithValues("baosecret", types.NamespacedName{
+		Name:      baoSecret.Name,
+		Namespace: baoSecret.Namespace,
+	})
+
 	// Целевой namespace — из spec или совпадает с namespace BaoSecret
-	targetNamespace := baoSecret.Spec.Target.Namespace
-	if targetNamespace == "" {
-		targetNamespace = baoSecret.Namespace
-	}
+	targetNamespace := r.targetNamespace(baoSecret)
 
 	// Хэш данных для отслеживания изменений (версионирование)
 	version := r.calculateVersion(data)
 
+	policy := creationPolicy(baoSecret)
+	if policy == kubebaoiov1alpha1.CreationPolicyNone {
+		log.V(1).Info("creationPolicy: None — целевой Secret не записывается", "version", version)
+		baoSecret.Status.SecretVersion = version
+		baoSecret.Status.SyncedSecretName = ""
+		baoSecret.Status.SyncedSecretNamespace = ""
+		return nil
+	}
+
 	// CreateOrUpdate — идемпотентное создание/обновление Secret (операция: "created" или "updated")
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -204,12 +353,23 @@ func (r *BaoSecretReconciler) syncSecret(ctx context.Context, baoSecret *kubebao
 	}
 
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		exists := !secret.CreationTimestamp.IsZero()
+		switch {
+		case !exists && policy == kubebaoiov1alpha1.CreationPolicyMerge:
+			return fmt.Errorf("target secret %s/%s does not exist (creationPolicy: Merge)", targetNamespace, secret.Name)
+		case exists:
+			if err := checkTargetOwnership(baoSecret, secret); err != nil {
+				return err
+			}
+		}
+
 		// Метки для идентификации управляемого секрета
 		if secret.Labels == nil {
 			secret.Labels = make(map[string]string)
 		}
-		secret.Labels["kubebao.io/managed-by"] = "kubebao-operator"
-		secret.Labels["kubebao.io/baosecret"] = baoSecret.Name
+		secret.Labels[labelManagedBy] = managedByOperator
+		secret.Labels[labelBaoSecret] = baoSecret.Name
+		secret.Labels[labelBaoSecretNamespace] = baoSecret.Namespace
 		for k, v := range baoSecret.Spec.Target.Labels {
 			secret.Labels[k] = v
 		}
@@ -218,33 +378,57 @@ func (r *BaoSecretReconciler) syncSecret(ctx context.Context, baoSecret *kubebao
 		if secret.Annotations == nil {
 			secret.Annotations = make(map[string]string)
 		}
-		secret.Annotations["kubebao.io/source-path"] = baoSecret.Spec.SecretPath
-		secret.Annotations["kubebao.io/version"] = version
+		secret.Annotations["kubebao.io/source-path"] = sourcePaths(&baoSecret.Spec)
+		secret.Annotations[annotationVersion] = version
 		for k, v := range baoSecret.Spec.Target.Annotations {
 			secret.Annotations[k] = v
 		}
 
-		// Тип Secret (Opaque, kubernetes.io/tls, kubernetes.io/dockerconfigjson и т.д.)
-		if baoSecret.Spec.Target.Type != "" {
-			secret.Type = corev1.SecretType(baoSecret.Spec.Target.Type)
+		if policy == kubebaoiov1alpha1.CreationPolicyMerge {
+			// Свои ключи обновляются, ключи, пропавшие из OpenBao, удаляются, чужие остаются
+			if secret.Data == nil {
+				secret.Data = make(map[string][]byte)
+			}
+			for _, key := range managedKeys(secret) {
+				if _, ok := data[key]; !ok {
+					delete(secret.Data, key)
+				}
+			}
+			for k, v := range data {
+				secret.Data[k] = v
+			}
+			secret.Annotations[annotationManagedKeys] = joinKeys(data)
 		} else {
-			secret.Type = corev1.SecretTypeOpaque
+			// Тип Secret (Opaque, kubernetes.io/tls, kubernetes.io/dockerconfigjson и т.д.)
+			if baoSecret.Spec.Target.Type != "" {
+				secret.Type = corev1.SecretType(baoSecret.Spec.Target.Type)
+			} else {
+				secret.Type = corev1.SecretTypeOpaque
+			}
+
+			// Данные секрета (все ключи → []byte)
+			secret.Data = data
+			delete(secret.Annotations, annotationManagedKeys)
 		}
 
-		// Данные секрета (все ключи → []byte)
-		secret.Data = data
+		if mutate != nil {
+			mutate(secret)
+		}
 
 		// Owner reference: при "Owner" Secret удаляется вместе с BaoSecret; при "Orphan" — остаётся
-		if baoSecret.Spec.Target.CreationPolicy == "Owner" || baoSecret.Spec.Target.CreationPolicy == "" {
-			if targetNamespace == baoSecret.Namespace {
-				return controllerutil.SetControllerReference(baoSecret, secret, r.Scheme)
-			}
+		if policy == kubebaoiov1alpha1.CreationPolicyOwner && targetNamespace == baoSecret.Namespace {
+			return controllerutil.SetControllerReference(baoSecret, secret, r.Scheme)
 		}
+		removeOwnerReference(secret, baoSecret)
 
 		return nil
 	})
 
 	if err != nil {
+		var unmanaged *unmanagedTargetError
+		if errors.As(err, &unmanaged) {
+			return err
+		}
 		return fmt.Errorf("failed to create/update secret: %w", err)
 	}
 
@@ -254,8 +438,16 @@ func (r *BaoSecretReconciler) syncSecret(ctx context.Context, baoSecret *kubebao
 		"namespace", secret.Namespace,
 		"version", version,
 		"keysCount", len(data),
+		"creationPolicy", policy,
 	)
 
+	// target изменился — прежний Secret очищается так же, как при удалении BaoSecret
+	if previous := r.syncedTarget(baoSecret); baoSecret.Status.SyncedSecretName != "" && previous != client.ObjectKeyFromObject(secret) {
+		if err := r.cleanupTarget(ctx, baoSecret, previous); err != nil {
+			log.Error(err, "Ошибка очистки прежнего целевого Secret", "previous", previous)
+		}
+	}
+
 	// Update status
 	baoSecret.Status.SecretVersion = version
 	baoSecret.Status.SyncedSecretName = secret.Name
@@ -264,100 +456,103 @@ func (r *BaoSecretReconciler) syncSecret(ctx context.Context, baoSecret *kubebao
 	return nil
 }
 
-// handleDeletion — вызывается при удалении BaoSecret. При Orphan policy Secret не удаляется.
-func (r *BaoSecretReconciler) handleDeletion(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret) (ctrl.Result, error) {
-	log := r.Log.WithValues("baosecret", types.NamespacedName{
-		Name:      baoSecret.Name,
-		Namespace: baoSecret.Namespace,
-	})
-
-	if controllerutil.ContainsFinalizer(baoSecret, baoSecretFinalizer) {
-		// Secret с owner reference удалится сборщиком мусора автоматически.
-		// При Orphan policy — намеренно оставляем Secret (пользователь удалит вручную при необходимости)
-		if baoSecret.Spec.Target.CreationPolicy == "Orphan" {
-			log.Info("Секрет оставляется при удалении (Orphan policy)")
+// removeOwnerReference убирает ссылку на BaoSecret, оставшуюся после смены политики на Orphan/Merge.
+func removeOwnerReference(secret *corev1.Secret, baoSecret *kubebaoiov1alpha1.BaoSecret) {
+	refs := secret.GetOwnerReferences()
+	kept := refs[:0]
+	for _, ref := range refs {
+		if ref.UID != baoSecret.UID {
+			kept = append(kept, ref)
 		}
+	}
+	if len(kept) != len(refs) {
+		secret.SetOwnerReferences(kept)
+	}
+}
 
-		// Remove finalizer
-		controllerutil.RemoveFinalizer(baoSecret, baoSecretFinalizer)
-		if err := r.Update(ctx, baoSecret); err != nil {
-			return ctrl.Result{}, err
+// openBaoClientFor — общий клиент оператора, либо (при OpenBaoRef или RoleName) кешированный
+// клиент, вошедший под ServiceAccount из namespace BaoSecret.
+func (r *BaoSecretReconciler) openBaoClientFor(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret) (*openbao.Client, error) {
+	if baoSecret.Spec.OpenBaoRef == nil && baoSecret.Spec.RoleName == "" {
+		if r.OpenBaoClient == nil {
+			return nil, fmt.Errorf("OpenBao client not configured")
 		}
+		return r.OpenBaoClient, nil
 	}
 
-	return ctrl.Result{}, nil
+	if r.OpenBaoClients == nil {
+		return nil, fmt.Errorf("per-resource OpenBao connections are not configured")
+	}
+	return r.OpenBaoClients.Get(ctx, baoSecret.Namespace, baoSecret.Spec.OpenBaoRef, baoSecret.Spec.RoleName)
 }
 
-// applyTemplate — подстановка шаблонов {{ .Data.key }} в StringData и Data из sourceData
-func (r *BaoSecretReconciler) applyTemplate(data map[string][]byte, template *kubebaoiov1alpha1.SecretTemplate, sourceData map[string]interface{}) (map[string][]byte, error) {
-	result := make(map[string][]byte)
-
-	// Copy existing data
-	for k, v := range data {
-		result[k] = v
+// forgetClientOnDenied сбрасывает кешированный клиент tenant'а после 403 — следующий reconcile
+// выполнит login заново и получит актуальные политики роли.
+func (r *BaoSecretReconciler) forgetClientOnDenied(baoSecret *kubebaoiov1alpha1.BaoSecret, err error) {
+	if r.OpenBaoClients == nil || !openbao.IsPermissionDenied(err) {
+		return
 	}
-
-	// Apply string data templates
-	if template.StringData != nil {
-		for key, tmpl := range template.StringData {
-			// Simple template replacement - in production, use text/template
-			value := tmpl
-			for k, v := range sourceData {
-				placeholder := fmt.Sprintf("{{ .Data.%s }}", k)
-				value = replaceAll(value, placeholder, fmt.Sprintf("%v", v))
-			}
-			result[key] = []byte(value)
-		}
+	if baoSecret.Spec.OpenBaoRef == nil && baoSecret.Spec.RoleName == "" {
+		return
 	}
+	r.OpenBaoClients.Forget(baoSecret.Namespace, baoSecret.Spec.OpenBaoRef, baoSecret.Spec.RoleName)
+}
 
-	// Apply data templates
-	if template.Data != nil {
-		for key, tmpl := range template.Data {
-			value := tmpl
-			for k, v := range sourceData {
-				placeholder := fmt.Sprintf("{{ .Data.%s }}", k)
-				value = replaceAll(value, placeholder, fmt.Sprintf("%v", v))
-			}
-			result[key] = []byte(value)
-		}
+// targetNamespace — namespace целевого Secret: из spec или namespace BaoSecret.
+func (r *BaoSecretReconciler) targetNamespace(baoSecret *kubebaoiov1alpha1.BaoSecret) string {
+	if baoSecret.Spec.Target.Namespace != "" {
+		return baoSecret.Spec.Target.Namespace
 	}
-
-	return result, nil
+	return baoSecret.Namespace
 }
 
-// replaceAll — замена всех вхождений old на new в строке s (без regex)
-func replaceAll(s, old, new string) string {
-	for {
-		newS := s
-		if idx := indexOf(newS, old); idx >= 0 {
-			newS = newS[:idx] + new + newS[idx+len(old):]
+// handleDeletion — вызывается при удалении BaoSecret. Целевой Secret очищается по creationPolicy.
+func (r *BaoSecretReconciler) handleDeletion(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret) (ctrl.Result, error) {
+	log := r.Log.WithValues("baosecret", types.NamespacedName{
+		Name:      baoSecret.Name,
+		Namespace: baoSecret.Namespace,
+	})
+
+	if controllerutil.ContainsFinalizer(baoSecret, baoSecretFinalizer) {
+		// Целевой Secret очищается по creationPolicy; Secret в другом namespace сборщик мусора
+		// не удалит, поэтому Owner удаляется явно. Orphan — намеренно оставляем Secret
+		if err := r.cleanupTarget(ctx, baoSecret, r.syncedTarget(baoSecret)); err != nil {
+			log.Error(err, "Ошибка очистки целевого Secret, повтор через 30 секунд")
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 		}
-		if newS == s {
-			break
+
+		if creationPolicy(baoSecret) != kubebaoiov1alpha1.CreationPolicyOrphan && baoSecret.Status.LeaseID != "" {
+			// Учётные данные удаляются вместе с Secret — аренда отзывается сразу, не дожидаясь TTL
+			if baoClient, err := r.openBaoClientFor(ctx, baoSecret); err != nil {
+				log.Info("Аренда не отозвана, она истечёт по TTL", "leaseID", baoSecret.Status.LeaseID, "error", err.Error())
+			} else {
+				r.releaseDynamicLeases(ctx, baoSecret, baoClient)
+			}
 		}
-		s = newS
-	}
-	return s
-}
 
-// indexOf — возвращает индекс первого вхождения substr в s или -1
-func indexOf(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return i
+		// Remove finalizer
+		controllerutil.RemoveFinalizer(baoSecret, baoSecretFinalizer)
+		if err := r.Update(ctx, baoSecret); err != nil {
+			return ctrl.Result{}, err
 		}
 	}
-	return -1
+
+	return ctrl.Result{}, nil
 }
 
 // calculateVersion — SHA256-хэш данных (первые 8 байт в hex) для версионирования
 func (r *BaoSecretReconciler) calculateVersion(data map[string][]byte) string {
+	// API-сервер возвращает пустой Data как nil — хэш не должен от этого зависеть
+	if data == nil {
+		data = map[string][]byte{}
+	}
 	jsonData, _ := json.Marshal(data)
 	hash := sha256.Sum256(jsonData)
 	return hex.EncodeToString(hash[:8])
 }
 
-// parseRefreshInterval — разбор интервала ("1h", "30m"). Минимум 1 минута.
+// parseRefreshInterval — разбор интервала ("1h", "30m"). Минимум 1 минута. Недопустимое
+// значение отклоняет ValidateBaoSecret (Ready=False, InvalidSpec), здесь — только запасной вариант.
 func (r *BaoSecretReconciler) parseRefreshInterval(interval string) time.Duration {
 	if interval == "" {
 		return defaultRefreshInterval
@@ -409,8 +604,32 @@ func (r *BaoSecretReconciler) setCondition(baoSecret *kubebaoiov1alpha1.BaoSecre
 
 // SetupWithManager sets up the controller with the Manager
 func (r *BaoSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kubebaoiov1alpha1.BaoSecret{}).
-		Owns(&corev1.Secret{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&kubebaoiov1alpha1.BaoSecret{}, builder.WithPredicates(specChanged)).
+		// Целевые Secret отслеживаются по меткам, а не owner reference: так видны и Secret
+		// в других namespace, и Secret с creationPolicy Orphan/Merge
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.secretToBaoSecret),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetLabels()[labelManagedBy] == managedByOperator
+			})))
+
+	// События аренд (продление, истечение) ставят BaoSecret в очередь
+	if r.LeaseManager != nil {
+		leaseEvents := make(chan event.GenericEvent, leaseEventBuffer)
+		r.LeaseManager.OnEvent(r.leaseEventHandler(leaseEvents))
+		b = b.WatchesRawSource(source.Channel(leaseEvents, &handler.EnqueueRequestForObject{}))
+	}
+
+	// Запись в KV ставит в очередь BaoSecret с этим путём (индекс по secretPath и dataFrom)
+	if r.EventWatcher != nil {
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), &kubebaoiov1alpha1.BaoSecret{}, kvPathIndex, kvPaths); err != nil {
+			return err
+		}
+		kvEvents := make(chan event.GenericEvent, kvEventBuffer)
+		r.EventWatcher.OnEvent(r.kvEventHandler(kvEvents))
+		r.EventWatcher.OnReconnect(r.kvResyncHandler(kvEvents))
+		b = b.WatchesRawSource(source.Channel(kvEvents, &handler.EnqueueRequestForObject{}))
+	}
+
+	return b.Complete(r)
 }
@@ -0,0 +1,188 @@
+// Дрейф целевого Secret: ручное изменение данных обнаруживается по хэшу в kubebao.io/version
+// при каждом событии Secret и сразу откатывается. Чужие Secret без метки kubebao не перезаписываются.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+const (
+	// Метки целевого Secret: признак управления и BaoSecret-владелец
+	labelManagedBy          = "kubebao.io/managed-by"
+	labelBaoSecret          = "kubebao.io/baosecret"
+	labelBaoSecretNamespace = "kubebao.io/baosecret-namespace"
+	managedByOperator       = "kubebao-operator"
+
+	// Аннотации целевого Secret: хэш записанных данных и ключи, записанные при creationPolicy: Merge
+	annotationVersion     = "kubebao.io/version"
+	annotationManagedKeys = "kubebao.io/managed-keys"
+
+	// Причина события Kubernetes при обнаружении дрейфа
+	eventReasonDrifted = "Drifted"
+)
+
+// creationPolicy — политика создания целевого Secret (по умолчанию Owner).
+func creationPolicy(baoSecret *kubebaoiov1alpha1.BaoSecret) string {
+	if baoSecret.Spec.Target.CreationPolicy == "" {
+		return kubebaoiov1alpha1.CreationPolicyOwner
+	}
+	return baoSecret.Spec.Target.CreationPolicy
+}
+
+// unmanagedTargetError — целевой Secret существует и не принадлежит этому BaoSecret.
+type unmanagedTargetError struct {
+	namespace string
+	name      string
+	owner     string
+}
+
+func (e *unmanagedTargetError) Error() string {
+	if e.owner == "" {
+		return fmt.Sprintf("secret %s/%s already exists and is not managed by kubebao; use creationPolicy: Merge to write into it", e.namespace, e.name)
+	}
+	return fmt.Sprintf("secret %s/%s is managed by BaoSecret %s", e.namespace, e.name, e.owner)
+}
+
+// checkTargetOwnership проверяет, что существующий Secret можно перезаписать. Secret, созданные
+// до появления метки namespace, считаются своими, если совпадает имя BaoSecret. Merge пишет и в
+// Secret без метки kubebao, но не в Secret другого BaoSecret: иначе два BaoSecret перезаписывали
+// бы метки и kubebao.io/managed-keys друг друга и удаляли бы ключи друг друга.
+func checkTargetOwnership(baoSecret *kubebaoiov1alpha1.BaoSecret, secret *corev1.Secret) error {
+	if secret.Labels[labelManagedBy] != managedByOperator {
+		if creationPolicy(baoSecret) == kubebaoiov1alpha1.CreationPolicyMerge {
+			return nil
+		}
+		return &unmanagedTargetError{namespace: secret.Namespace, name: secret.Name}
+	}
+
+	ownerNamespace := secret.Labels[labelBaoSecretNamespace]
+	if ownerNamespace == "" {
+		ownerNamespace = secret.Namespace
+	}
+	if secret.Labels[labelBaoSecret] != baoSecret.Name || ownerNamespace != baoSecret.Namespace {
+		return &unmanagedTargetError{
+			namespace: secret.Namespace,
+			name:      secret.Name,
+			owner:     ownerNamespace + "/" + secret.Labels[labelBaoSecret],
+		}
+	}
+	return nil
+}
+
+// managedKeys — ключи, записанные BaoSecret в Secret при creationPolicy: Merge.
+func managedKeys(secret *corev1.Secret) []string {
+	value := secret.Annotations[annotationManagedKeys]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// joinKeys — отсортированный список ключей для аннотации kubebao.io/managed-keys.
+func joinKeys(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// managedData — данные Secret, за которые отвечает BaoSecret: все ключи или, при Merge,
+// только записанные оператором.
+func managedData(baoSecret *kubebaoiov1alpha1.BaoSecret, secret *corev1.Secret) map[string][]byte {
+	if creationPolicy(baoSecret) != kubebaoiov1alpha1.CreationPolicyMerge {
+		return secret.Data
+	}
+	data := make(map[string][]byte)
+	for _, key := range managedKeys(secret) {
+		if value, ok := secret.Data[key]; ok {
+			data[key] = value
+		}
+	}
+	return data
+}
+
+// targetDrifted — данные Secret не совпадают с хэшем, записанным оператором в kubebao.io/version.
+func (r *BaoSecretReconciler) targetDrifted(baoSecret *kubebaoiov1alpha1.BaoSecret, secret *corev1.Secret) bool {
+	if checkTargetOwnership(baoSecret, secret) != nil {
+		return false
+	}
+	return r.calculateVersion(managedData(baoSecret, secret)) != secret.Annotations[annotationVersion]
+}
+
+// detectDrift проверяет целевой Secret перед синхронизацией. Сам откат выполняет обычная запись
+// целевого Secret; для динамических секретов и wrapping дрейф означает выпуск новых данных.
+func (r *BaoSecretReconciler) detectDrift(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret) (bool, error) {
+	if baoSecret.Status.SyncedSecretName == "" || creationPolicy(baoSecret) == kubebaoiov1alpha1.CreationPolicyNone {
+		return false, nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: baoSecret.Spec.Target.Name, Namespace: r.targetNamespace(baoSecret)}, secret)
+	switch {
+	case apierrors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to get target secret: %w", err)
+	}
+
+	if !r.targetDrifted(baoSecret, secret) {
+		return false, nil
+	}
+
+	r.Log.Info("Целевой Secret изменён вне kubebao, восстановление",
+		"baosecret", client.ObjectKeyFromObject(baoSecret),
+		"secret", client.ObjectKeyFromObject(secret),
+	)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(baoSecret, corev1.EventTypeWarning, eventReasonDrifted,
+			"Target Secret %s/%s was modified outside kubebao and is being restored", secret.Namespace, secret.Name)
+	}
+	r.setCondition(baoSecret, kubebaoiov1alpha1.ConditionTypeDrifted, metav1.ConditionTrue,
+		kubebaoiov1alpha1.ReasonDriftRestored, fmt.Sprintf("Target Secret %s/%s was modified outside kubebao and restored from OpenBao", secret.Namespace, secret.Name))
+	return true, nil
+}
+
+// clearDrift снимает Drifted спустя refreshInterval без повторного дрейфа: условие остаётся
+// видимым после отката, а не сбрасывается сразу следующим reconcile.
+func (r *BaoSecretReconciler) clearDrift(baoSecret *kubebaoiov1alpha1.BaoSecret, refreshInterval time.Duration) {
+	for _, cond := range baoSecret.Status.Conditions {
+		if cond.Type != kubebaoiov1alpha1.ConditionTypeDrifted {
+			continue
+		}
+		if cond.Status == metav1.ConditionTrue && time.Since(cond.LastTransitionTime.Time) >= refreshInterval {
+			r.setCondition(baoSecret, kubebaoiov1alpha1.ConditionTypeDrifted, metav1.ConditionFalse,
+				kubebaoiov1alpha1.ReasonInSync, "Target Secret matches OpenBao")
+		}
+		return
+	}
+}
+
+// secretToBaoSecret — BaoSecret, управляющий Secret, по меткам (в том числе в другом namespace).
+func (r *BaoSecretReconciler) secretToBaoSecret(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	name := labels[labelBaoSecret]
+	if name == "" || labels[labelManagedBy] != managedByOperator {
+		return nil
+	}
+
+	namespace := labels[labelBaoSecretNamespace]
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}}
+}
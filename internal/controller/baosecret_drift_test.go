@@ -0,0 +1,209 @@
+// Тесты дрейфа целевого Secret: чужие Secret, откат ручных изменений и снятие Drifted.
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// newTestBaoSecretReconciler — reconciler BaoSecret с fake-клиентом, fake OpenBao и записью
+// событий. В fake OpenBao записан KV-секрет app: username=admin, password=s3cr3t.
+func newTestBaoSecretReconciler(t *testing.T, objs ...client.Object) (*BaoSecretReconciler, *fakeOpenBao, *record.FakeRecorder) {
+	t.Helper()
+
+	bao, baoClient := newFakeOpenBao(t)
+	bao.data["secret/data/app"] = map[string]interface{}{
+		"data":     map[string]interface{}{"username": "admin", "password": "s3cr3t"},
+		"metadata": map[string]interface{}{"version": 1},
+	}
+
+	scheme := newTestScheme(t)
+	require.NoError(t, corev1.AddToScheme(scheme))
+	recorder := record.NewFakeRecorder(100)
+	r := &BaoSecretReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&kubebaoiov1alpha1.BaoSecret{}).Build(),
+		Scheme:        scheme,
+		Log:           logr.Discard(),
+		OpenBaoClient: baoClient,
+		Recorder:      recorder,
+	}
+	return r, bao, recorder
+}
+
+// reconcileBaoSecret — reconcile и BaoSecret после него.
+func reconcileBaoSecret(t *testing.T, r *BaoSecretReconciler, baoSecret *kubebaoiov1alpha1.BaoSecret) *kubebaoiov1alpha1.BaoSecret {
+	t.Helper()
+
+	ctx := context.Background()
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(baoSecret)})
+	require.NoError(t, err)
+	current := &kubebaoiov1alpha1.BaoSecret{}
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(baoSecret), current))
+	return current
+}
+
+func TestCheckTargetOwnership(t *testing.T) {
+	managedBy := func(name, namespace string) map[string]string {
+		labels := map[string]string{labelManagedBy: managedByOperator, labelBaoSecret: name}
+		if namespace != "" {
+			labels[labelBaoSecretNamespace] = namespace
+		}
+		return labels
+	}
+
+	tests := []struct {
+		name      string
+		policy    string
+		labels    map[string]string
+		wantOwner string
+		wantErr   bool
+	}{
+		{name: "own secret", labels: managedBy("app", "default")},
+		// Secret, записанный до появления метки namespace
+		{name: "own secret without namespace label", labels: managedBy("app", "")},
+		{name: "foreign secret", labels: map[string]string{"team": "a"}, wantErr: true},
+		{name: "foreign secret with Orphan", policy: kubebaoiov1alpha1.CreationPolicyOrphan, wantErr: true},
+		{name: "foreign secret with Merge", policy: kubebaoiov1alpha1.CreationPolicyMerge},
+		{name: "other BaoSecret", labels: managedBy("other", "default"), wantOwner: "default/other", wantErr: true},
+		{name: "same name in another namespace", labels: managedBy("app", "billing"), wantOwner: "billing/app", wantErr: true},
+		{name: "other BaoSecret with Merge", policy: kubebaoiov1alpha1.CreationPolicyMerge,
+			labels: managedBy("other", "default"), wantOwner: "default/other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baoSecret := &kubebaoiov1alpha1.BaoSecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+				Spec:       kubebaoiov1alpha1.BaoSecretSpec{Target: kubebaoiov1alpha1.SecretTarget{Name: "app", CreationPolicy: tt.policy}},
+			}
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Labels: tt.labels}}
+
+			err := checkTargetOwnership(baoSecret, secret)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var unmanaged *unmanagedTargetError
+			require.ErrorAs(t, err, &unmanaged)
+			assert.Equal(t, tt.wantOwner, unmanaged.owner)
+			assert.Equal(t, kubebaoiov1alpha1.ReasonUnmanagedTarget, failureReason(err))
+		})
+	}
+}
+
+func TestForeignTargetSecretIsNotOverwritten(t *testing.T) {
+	// creationTimestamp fake-клиент не заполняет, а writeTargetSecret по нему отличает существующий Secret
+	foreign := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", CreationTimestamp: metav1.Now()},
+		Data:       map[string][]byte{"password": []byte("manual")},
+	}
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 1},
+		Spec: kubebaoiov1alpha1.BaoSecretSpec{
+			SecretPath: "app",
+			Target:     kubebaoiov1alpha1.SecretTarget{Name: "app"},
+		},
+	}
+	r, _, _ := newTestBaoSecretReconciler(t, baoSecret, foreign)
+
+	current := reconcileBaoSecret(t, r, baoSecret)
+	ready := meta.FindStatusCondition(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, kubebaoiov1alpha1.ReasonUnmanagedTarget, ready.Reason)
+
+	secret := &corev1.Secret{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(foreign), secret))
+	assert.Equal(t, foreign.Data, secret.Data)
+	assert.Empty(t, secret.Labels)
+
+	// Чужой Secret не считается дрейфом
+	current.Status.SyncedSecretName = "app"
+	drifted, err := r.detectDrift(context.Background(), current)
+	require.NoError(t, err)
+	assert.False(t, drifted)
+}
+
+func TestTargetDriftIsRestored(t *testing.T) {
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 1},
+		Spec: kubebaoiov1alpha1.BaoSecretSpec{
+			SecretPath:      "app",
+			RefreshInterval: "1h",
+			Target:          kubebaoiov1alpha1.SecretTarget{Name: "app"},
+		},
+	}
+	r, _, recorder := newTestBaoSecretReconciler(t, baoSecret)
+	ctx := context.Background()
+	want := map[string][]byte{"username": []byte("admin"), "password": []byte("s3cr3t")}
+
+	current := reconcileBaoSecret(t, r, baoSecret)
+	secret := &corev1.Secret{}
+	require.NoError(t, r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "app"}, secret))
+	require.Equal(t, want, secret.Data)
+	assert.False(t, r.targetDrifted(current, secret))
+	assert.Nil(t, meta.FindStatusCondition(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+
+	// Ключ изменён вручную
+	secret.Data["password"] = []byte("manual")
+	require.NoError(t, r.Update(ctx, secret))
+	assert.True(t, r.targetDrifted(current, secret))
+
+	current = reconcileBaoSecret(t, r, baoSecret)
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(secret), secret))
+	assert.Equal(t, want, secret.Data)
+	assert.False(t, r.targetDrifted(current, secret))
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+	require.NotEmpty(t, recorder.Events)
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Contains(t, events, "Warning Drifted Target Secret default/app was modified outside kubebao and is being restored")
+
+	// Следующий reconcile без дрейфа не снимает условие раньше refreshInterval
+	current = reconcileBaoSecret(t, r, baoSecret)
+	assert.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+}
+
+func TestClearDrift(t *testing.T) {
+	r := &BaoSecretReconciler{Log: logr.Discard()}
+	drifted := func(since time.Duration) *kubebaoiov1alpha1.BaoSecret {
+		return &kubebaoiov1alpha1.BaoSecret{Status: kubebaoiov1alpha1.BaoSecretStatus{Conditions: []metav1.Condition{{
+			Type:               kubebaoiov1alpha1.ConditionTypeDrifted,
+			Status:             metav1.ConditionTrue,
+			Reason:             kubebaoiov1alpha1.ReasonDriftRestored,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-since)),
+		}}}}
+	}
+
+	recent := drifted(10 * time.Minute)
+	r.clearDrift(recent, time.Hour)
+	assert.True(t, meta.IsStatusConditionTrue(recent.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted))
+
+	old := drifted(2 * time.Hour)
+	r.clearDrift(old, time.Hour)
+	cond := meta.FindStatusCondition(old.Status.Conditions, kubebaoiov1alpha1.ConditionTypeDrifted)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, kubebaoiov1alpha1.ReasonInSync, cond.Reason)
+
+	// Без условия Drifted ничего не добавляется
+	clean := &kubebaoiov1alpha1.BaoSecret{}
+	r.clearDrift(clean, time.Hour)
+	assert.Empty(t, clean.Status.Conditions)
+}
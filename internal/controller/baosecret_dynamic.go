@@ -0,0 +1,371 @@
+// Динамические движки секретов (database, pki, aws и т.п.): учётные данные выдаются с арендой,
+// перевыпускаются по её TTL, а прежняя аренда отзывается после записи новых данных в Secret.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+const (
+	// Владелец аренд BaoSecret в LeaseManager: baosecret/<namespace>/<name>
+	leaseOwnerPrefix = "baosecret/"
+	// Минимальная пауза перед следующим reconcile динамического секрета
+	minLeaseRequeue = 5 * time.Second
+	// Ёмкость канала событий аренд
+	leaseEventBuffer = 128
+)
+
+// writeEngines — движки, выдающие секрет только на запись (pki/issue, ssh/sign). Остальные
+// читаются, если не заданы secretArgs.
+var writeEngines = map[string]bool{
+	"pki": true,
+	"ssh": true,
+}
+
+// isDynamicEngine — всё, кроме KV, считается динамическим движком.
+func isDynamicEngine(engine string) bool {
+	switch engine {
+	case "", "kv", "kv-v2":
+		return false
+	}
+	return true
+}
+
+// leaseOwner — ключ владельца аренд BaoSecret в LeaseManager.
+func leaseOwner(baoSecret *kubebaoiov1alpha1.BaoSecret) string {
+	return leaseOwnerPrefix + baoSecret.Namespace + "/" + baoSecret.Name
+}
+
+// fetchDynamicSecret выдаёт новые учётные данные: write с secretArgs (pki/issue/<role>),
+// иначе read (database/creds/<role>, aws/creds/<role>).
+func fetchDynamicSecret(ctx context.Context, baoClient *openbao.Client, spec *kubebaoiov1alpha1.BaoSecretSpec) (*api.Secret, error) {
+	var (
+		secret *api.Secret
+		err    error
+	)
+	if len(spec.SecretArgs) > 0 || writeEngines[spec.SecretEngine] {
+		args := make(map[string]interface{}, len(spec.SecretArgs))
+		for k, v := range spec.SecretArgs {
+			args[k] = v
+		}
+		secret, err = baoClient.WriteSecret(ctx, spec.SecretPath, args)
+	} else {
+		secret, err = baoClient.ReadCredentials(ctx, spec.SecretPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no data returned from %s", spec.SecretPath)
+	}
+	return secret, nil
+}
+
+// flattenDynamicData приводит ответ движка к скалярам: списки строк (ca_chain) склеиваются
+// через перевод строки, прочие составные значения кодируются в JSON.
+func flattenDynamicData(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		switch value := v.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			parts := make([]string, 0, len(value))
+			allStrings := true
+			for _, item := range value {
+				str, ok := item.(string)
+				if !ok {
+					allStrings = false
+					break
+				}
+				parts = append(parts, str)
+			}
+			if allStrings {
+				result[k] = strings.Join(parts, "\n")
+				continue
+			}
+			encoded, _ := json.Marshal(value)
+			result[k] = string(encoded)
+		case map[string]interface{}:
+			encoded, _ := json.Marshal(value)
+			result[k] = string(encoded)
+		default:
+			result[k] = value
+		}
+	}
+	return result
+}
+
+// syncDynamicSecret перевыпускает учётные данные, только когда текущие подходят к концу срока,
+// изменилась спецификация или пропал целевой Secret. Старая аренда отзывается после того,
+// как новые данные записаны в Secret.
+func (r *BaoSecretReconciler) syncDynamicSecret(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret, baoClient *openbao.Client) error {
+	log := r.Log.WithValues("baosecret", types.NamespacedName{
+		Name:      baoSecret.Name,
+		Namespace: baoSecret.Namespace,
+	})
+
+	valid, err := r.dynamicCredentialsValid(ctx, baoSecret, baoClient)
+	if err != nil {
+		return err
+	}
+	if valid {
+		log.V(1).Info("Учётные данные действительны, перевыпуск не требуется", "leaseID", baoSecret.Status.LeaseID)
+		return nil
+	}
+
+	log.Info("Выдача динамического секрета", "engine", baoSecret.Spec.SecretEngine, "path", baoSecret.Spec.SecretPath)
+	secret, err := fetchDynamicSecret(ctx, baoClient, &baoSecret.Spec)
+	if err != nil {
+		r.forgetClientOnDenied(baoSecret, err)
+		return fmt.Errorf("failed to issue dynamic secret from OpenBao: %w", err)
+	}
+
+	sourceData := flattenDynamicData(secret.Data)
+	metadata := map[string]interface{}{
+		"path":           baoSecret.Spec.SecretPath,
+		"lease_id":       secret.LeaseID,
+		"lease_duration": secret.LeaseDuration,
+		"renewable":      secret.Renewable,
+	}
+	data, err := r.renderSecretData(baoSecret, sourceData, metadata, nil)
+	if err != nil {
+		r.revokeLease(ctx, baoClient, secret.LeaseID)
+		return err
+	}
+
+	if err := r.writeTargetSecret(ctx, baoSecret, data, nil); err != nil {
+		// Учётные данные не доставлены — аренда не нужна
+		r.revokeLease(ctx, baoClient, secret.LeaseID)
+		return err
+	}
+
+	previous := baoSecret.Status.LeaseID
+	r.recordIssuedLease(baoSecret, baoClient, secret, sourceData)
+	log.Info("Динамический секрет записан",
+		"leaseID", secret.LeaseID,
+		"ttl", time.Duration(secret.LeaseDuration)*time.Second,
+		"renewable", secret.Renewable,
+	)
+
+	// Прежние учётные данные больше не используются
+	r.revokeStaleLeases(ctx, baoSecret, baoClient, previous, secret.LeaseID)
+	return nil
+}
+
+// dynamicCredentialsValid — текущие учётные данные можно оставить: спецификация не менялась,
+// целевой Secret на месте и момент перевыпуска не наступил. После рестарта оператора аренда
+// из статуса заново ставится на учёт по sys/leases/lookup.
+func (r *BaoSecretReconciler) dynamicCredentialsValid(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret, baoClient *openbao.Client) (bool, error) {
+	status := &baoSecret.Status
+	if status.ObservedGeneration != baoSecret.Generation || status.SyncedSecretName == "" {
+		return false, nil
+	}
+
+	existing := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: baoSecret.Spec.Target.Name, Namespace: r.targetNamespace(baoSecret)}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to get target secret: %w", err)
+	}
+
+	// Изменённые вручную учётные данные не восстановить без повторной выдачи
+	if r.targetDrifted(baoSecret, existing) {
+		return false, nil
+	}
+
+	if status.LeaseID != "" && r.LeaseManager != nil {
+		lease, ok := r.LeaseManager.Get(status.LeaseID)
+		if !ok {
+			adopted, err := baoClient.LookupLease(ctx, status.LeaseID)
+			if err != nil || adopted.LeaseDuration <= 0 {
+				r.Log.V(1).Info("Аренда из статуса недействительна", "leaseID", status.LeaseID)
+				return false, nil
+			}
+			r.LeaseManager.TrackFor(baoClient, leaseOwner(baoSecret), baoSecret.Spec.SecretPath, adopted)
+			lease, _ = r.LeaseManager.Get(status.LeaseID)
+		}
+		expiry := metav1.NewTime(lease.ExpiresAt)
+		status.LeaseExpiryTime = &expiry
+	}
+
+	rotateAt, ok := r.leaseRotationTime(baoSecret)
+	if !ok {
+		// Секрет без срока действия — перевыпуск по refreshInterval
+		if status.LastSyncTime == nil {
+			return false, nil
+		}
+		rotateAt = status.LastSyncTime.Add(r.parseRefreshInterval(baoSecret.Spec.RefreshInterval))
+	}
+	return time.Now().Before(rotateAt), nil
+}
+
+// leaseRotationTime — момент перевыпуска: 2/3 TTL для непродлеваемых аренд и сертификатов;
+// продлеваемую аренду продлевает LeaseManager, перевыпуск — только незадолго до истечения.
+func (r *BaoSecretReconciler) leaseRotationTime(baoSecret *kubebaoiov1alpha1.BaoSecret) (time.Time, bool) {
+	status := &baoSecret.Status
+	if r.LeaseManager != nil && status.LeaseID != "" {
+		if lease, ok := r.LeaseManager.Get(status.LeaseID); ok {
+			return leaseRotationAt(lease, r.LeaseManager.IsExpiring(lease.ID)), true
+		}
+	}
+
+	if status.LeaseExpiryTime == nil {
+		return time.Time{}, false
+	}
+	var duration time.Duration
+	if status.LeaseDuration != nil {
+		duration = status.LeaseDuration.Duration
+	}
+	return status.LeaseExpiryTime.Add(-duration / 3), true
+}
+
+// leaseRotationAt — момент перевыпуска отслеживаемой аренды. Аренда, продление которой не
+// удалось, перевыпускается как непродлеваемая: продление запускается после 2/3 TTL, поэтому
+// этот момент уже наступил.
+func leaseRotationAt(lease openbao.Lease, expiring bool) time.Time {
+	if lease.Renewable && lease.RenewFailures == 0 && !expiring {
+		return lease.ExpiresAt.Add(-lease.Duration / 10)
+	}
+	return lease.ExpiresAt.Add(-lease.Duration / 3)
+}
+
+// dynamicRequeueInterval — следующий reconcile к моменту перевыпуска вместо refreshInterval.
+func (r *BaoSecretReconciler) dynamicRequeueInterval(baoSecret *kubebaoiov1alpha1.BaoSecret, refreshInterval time.Duration) time.Duration {
+	rotateAt, ok := r.leaseRotationTime(baoSecret)
+	if !ok {
+		return refreshInterval
+	}
+	until := time.Until(rotateAt)
+	if until < minLeaseRequeue {
+		return minLeaseRequeue
+	}
+	return until
+}
+
+// recordIssuedLease ставит аренду на учёт и сохраняет её в статусе. Для секретов без аренды
+// (pki/issue без generate_lease) срок берётся из поля expiration сертификата.
+func (r *BaoSecretReconciler) recordIssuedLease(baoSecret *kubebaoiov1alpha1.BaoSecret, baoClient *openbao.Client, secret *api.Secret, data map[string]interface{}) {
+	status := &baoSecret.Status
+	status.WrappingTokenExpiry = nil
+	status.LeaseID = secret.LeaseID
+	status.LeaseDuration = nil
+	status.LeaseExpiryTime = nil
+
+	now := time.Now()
+	var expiry time.Time
+	switch {
+	case secret.LeaseID != "" && secret.LeaseDuration > 0:
+		expiry = now.Add(time.Duration(secret.LeaseDuration) * time.Second)
+		if r.LeaseManager != nil {
+			r.LeaseManager.TrackFor(baoClient, leaseOwner(baoSecret), baoSecret.Spec.SecretPath, secret)
+		}
+	case data["expiration"] != nil:
+		unix, err := json.Number(fmt.Sprintf("%v", data["expiration"])).Int64()
+		if err != nil || unix <= now.Unix() {
+			return
+		}
+		expiry = time.Unix(unix, 0)
+	default:
+		return
+	}
+
+	expiryTime := metav1.NewTime(expiry)
+	status.LeaseDuration = &metav1.Duration{Duration: expiry.Sub(now).Round(time.Second)}
+	status.LeaseExpiryTime = &expiryTime
+}
+
+// revokeStaleLeases отзывает аренды владельца, кроме текущей, включая аренду из статуса,
+// которую менеджер не отслеживает (например, выданную до рестарта оператора).
+func (r *BaoSecretReconciler) revokeStaleLeases(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret, baoClient *openbao.Client, previous, current string) {
+	stale := make(map[string]bool)
+	if previous != "" && previous != current {
+		stale[previous] = true
+	}
+	if r.LeaseManager != nil {
+		for _, lease := range r.LeaseManager.Leases(leaseOwner(baoSecret)) {
+			if lease.ID != current {
+				stale[lease.ID] = true
+			}
+		}
+	}
+
+	for leaseID := range stale {
+		r.revokeLease(ctx, baoClient, leaseID)
+	}
+}
+
+// releaseDynamicLeases отзывает все аренды BaoSecret и очищает их в статусе — при удалении
+// или переходе на KV.
+func (r *BaoSecretReconciler) releaseDynamicLeases(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret, baoClient *openbao.Client) {
+	r.revokeStaleLeases(ctx, baoSecret, baoClient, baoSecret.Status.LeaseID, "")
+	baoSecret.Status.LeaseID = ""
+	baoSecret.Status.LeaseDuration = nil
+	baoSecret.Status.LeaseExpiryTime = nil
+}
+
+// revokeLease отзывает аренду; ошибка не критична — аренда истечёт по TTL.
+func (r *BaoSecretReconciler) revokeLease(ctx context.Context, baoClient *openbao.Client, leaseID string) {
+	if leaseID == "" {
+		return
+	}
+
+	var err error
+	if _, tracked := r.trackedLease(leaseID); tracked {
+		// Менеджер отзывает через клиент, выдавший аренду, и снимает её с учёта
+		err = r.LeaseManager.Revoke(ctx, leaseID)
+	} else {
+		err = baoClient.RevokeLease(ctx, leaseID)
+	}
+	if err != nil {
+		r.Log.Info("Не удалось отозвать аренду, она истечёт по TTL", "leaseID", leaseID, "error", err.Error())
+		return
+	}
+	r.Log.V(1).Info("Аренда отозвана", "leaseID", leaseID)
+}
+
+// trackedLease — аренда на учёте в LeaseManager (если он настроен).
+func (r *BaoSecretReconciler) trackedLease(leaseID string) (openbao.Lease, bool) {
+	if r.LeaseManager == nil {
+		return openbao.Lease{}, false
+	}
+	return r.LeaseManager.Get(leaseID)
+}
+
+// leaseEventHandler ставит BaoSecret в очередь при событиях его аренд: продление обновляет срок
+// в статусе, Expiring/Expired/RenewFailed приводят к перевыпуску. Отправка не блокирует цикл
+// продления — при переполнении сработает плановый requeue.
+func (r *BaoSecretReconciler) leaseEventHandler(events chan<- event.GenericEvent) func(openbao.LeaseEvent) {
+	return func(ev openbao.LeaseEvent) {
+		if ev.Type == openbao.LeaseRevoked || !strings.HasPrefix(ev.Lease.Owner, leaseOwnerPrefix) {
+			return
+		}
+		namespace, name, ok := strings.Cut(strings.TrimPrefix(ev.Lease.Owner, leaseOwnerPrefix), "/")
+		if !ok {
+			return
+		}
+
+		obj := &kubebaoiov1alpha1.BaoSecret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		select {
+		case events <- event.GenericEvent{Object: obj}:
+		default:
+			r.Log.V(1).Info("Очередь событий аренд переполнена", "owner", ev.Lease.Owner, "event", string(ev.Type))
+		}
+	}
+}
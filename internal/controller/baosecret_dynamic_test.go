@@ -0,0 +1,238 @@
+// Тесты динамических секретов BaoSecret: момент перевыпуска, аренды после рестарта оператора
+// и отзыв прежней аренды после записи новых учётных данных.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+// dynamicOpenBao — OpenBao с движком database/creds/app: выдача, lookup и отзыв аренд.
+// onRevoke вызывается при отзыве, пока аренда ещё не удалена.
+type dynamicOpenBao struct {
+	mu       sync.Mutex
+	issued   int
+	active   map[string]bool
+	revoked  []string
+	onRevoke func(leaseID string)
+}
+
+func (b *dynamicOpenBao) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var body struct {
+		LeaseID string `json:"lease_id"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v1/database/creds/app":
+		b.issued++
+		leaseID := fmt.Sprintf("database/creds/app/%d", b.issued)
+		b.active[leaseID] = true
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_id":       leaseID,
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]interface{}{"username": fmt.Sprintf("user-%d", b.issued), "password": "s3cr3t"},
+		})
+	case "/v1/sys/leases/lookup":
+		if !b.active[body.LeaseID] {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid lease"]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"id": body.LeaseID, "ttl": 1800, "renewable": true},
+		})
+	case "/v1/sys/leases/revoke":
+		if b.onRevoke != nil {
+			b.onRevoke(body.LeaseID)
+		}
+		delete(b.active, body.LeaseID)
+		b.revoked = append(b.revoked, body.LeaseID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newTestDynamicReconciler — reconciler BaoSecret с dynamicOpenBao и менеджером аренд.
+func newTestDynamicReconciler(t *testing.T, objs ...client.Object) (*BaoSecretReconciler, *dynamicOpenBao) {
+	t.Helper()
+
+	bao := &dynamicOpenBao{active: map[string]bool{}}
+	server := httptest.NewServer(http.HandlerFunc(bao.serveHTTP))
+	t.Cleanup(server.Close)
+	baoClient, err := openbao.NewClient(&openbao.Config{Address: server.URL, Token: "test-token", MaxRetries: 1}, hclog.NewNullLogger())
+	require.NoError(t, err)
+
+	r, _, _ := newTestBaoSecretReconciler(t, objs...)
+	r.OpenBaoClient = baoClient
+	r.LeaseManager = openbao.NewLeaseManager(nil, nil)
+	return r, bao
+}
+
+func TestLeaseRotationAt(t *testing.T) {
+	issuedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lease := openbao.Lease{
+		ID:        "database/creds/app/1",
+		Renewable: true,
+		Duration:  3000 * time.Second,
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(3000 * time.Second),
+	}
+	nonRenewable := lease
+	nonRenewable.Renewable = false
+	failed := lease
+	failed.RenewFailures = 1
+
+	tests := []struct {
+		name     string
+		lease    openbao.Lease
+		expiring bool
+		want     time.Time
+	}{
+		// Продлеваемую аренду продлевает менеджер — перевыпуск за Duration/10 до истечения
+		{name: "renewable", lease: lease, want: lease.ExpiresAt.Add(-300 * time.Second)},
+		{name: "non-renewable", lease: nonRenewable, want: issuedAt.Add(2000 * time.Second)},
+		// Достигнут max_ttl
+		{name: "expiring", lease: lease, expiring: true, want: issuedAt.Add(2000 * time.Second)},
+		{name: "renewal failed", lease: failed, want: issuedAt.Add(2000 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, leaseRotationAt(tt.lease, tt.expiring))
+		})
+	}
+}
+
+func TestLeaseRotationTime(t *testing.T) {
+	r := &BaoSecretReconciler{Log: logr.Discard(), LeaseManager: openbao.NewLeaseManager(nil, nil)}
+	r.LeaseManager.Track("baosecret/default/db", "database/creds/app",
+		&api.Secret{LeaseID: "database/creds/app/1", LeaseDuration: 3000, Renewable: true})
+	lease, ok := r.LeaseManager.Get("database/creds/app/1")
+	require.True(t, ok)
+
+	// Отслеживаемая аренда важнее статуса
+	expiry := metav1.NewTime(time.Now().Add(time.Hour).Truncate(time.Second))
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{Status: kubebaoiov1alpha1.BaoSecretStatus{
+		LeaseID:         lease.ID,
+		LeaseExpiryTime: &expiry,
+		LeaseDuration:   &metav1.Duration{Duration: 90 * time.Minute},
+	}}
+	rotateAt, ok := r.leaseRotationTime(baoSecret)
+	require.True(t, ok)
+	assert.Equal(t, lease.ExpiresAt.Add(-300*time.Second), rotateAt)
+
+	// Аренда не на учёте (или сертификат без аренды) — 2/3 срока из статуса
+	baoSecret.Status.LeaseID = "database/creds/app/0"
+	rotateAt, ok = r.leaseRotationTime(baoSecret)
+	require.True(t, ok)
+	assert.Equal(t, expiry.Add(-30*time.Minute), rotateAt)
+
+	// Секрет без срока действия перевыпускается по refreshInterval
+	_, ok = r.leaseRotationTime(&kubebaoiov1alpha1.BaoSecret{})
+	assert.False(t, ok)
+}
+
+func TestDynamicSecretLeaseAdoptedAfterRestart(t *testing.T) {
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", Generation: 1},
+		Spec: kubebaoiov1alpha1.BaoSecretSpec{
+			SecretEngine: "database",
+			SecretPath:   "database/creds/app",
+			Target:       kubebaoiov1alpha1.SecretTarget{Name: "db"},
+		},
+	}
+	r, bao := newTestDynamicReconciler(t, baoSecret)
+
+	current := reconcileBaoSecret(t, r, baoSecret)
+	require.Equal(t, "database/creds/app/1", current.Status.LeaseID)
+	require.Len(t, r.LeaseManager.Leases(leaseOwner(current)), 1)
+
+	// Рестарт оператора: новый менеджер аренд ничего не знает об аренде из статуса
+	r.LeaseManager = openbao.NewLeaseManager(nil, nil)
+	current = reconcileBaoSecret(t, r, baoSecret)
+	assert.Equal(t, 1, bao.issued, "valid lease must not be reissued")
+	assert.Empty(t, bao.revoked)
+	lease, ok := r.LeaseManager.Get("database/creds/app/1")
+	require.True(t, ok, "lease must be adopted via sys/leases/lookup")
+	assert.Equal(t, leaseOwner(current), lease.Owner)
+	assert.Equal(t, 1800*time.Second, lease.Duration)
+	assert.True(t, lease.Renewable)
+	require.NotNil(t, current.Status.LeaseExpiryTime)
+	assert.WithinDuration(t, lease.ExpiresAt, current.Status.LeaseExpiryTime.Time, time.Second)
+
+	// Аренда отозвана вне оператора — lookup не находит её, выдаются новые учётные данные
+	r.LeaseManager = openbao.NewLeaseManager(nil, nil)
+	bao.mu.Lock()
+	delete(bao.active, "database/creds/app/1")
+	bao.mu.Unlock()
+	current = reconcileBaoSecret(t, r, baoSecret)
+	assert.Equal(t, 2, bao.issued)
+	assert.Equal(t, "database/creds/app/2", current.Status.LeaseID)
+}
+
+func TestDynamicSecretRevokesStaleLeaseAfterWrite(t *testing.T) {
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", Generation: 1},
+		Spec: kubebaoiov1alpha1.BaoSecretSpec{
+			SecretEngine: "database",
+			SecretPath:   "database/creds/app",
+			Target:       kubebaoiov1alpha1.SecretTarget{Name: "db"},
+		},
+	}
+	r, bao := newTestDynamicReconciler(t, baoSecret)
+	ctx := context.Background()
+
+	// К моменту отзыва в Secret уже записаны новые учётные данные
+	var usernameAtRevoke []string
+	bao.onRevoke = func(string) {
+		secret := &corev1.Secret{}
+		require.NoError(t, r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "db"}, secret))
+		usernameAtRevoke = append(usernameAtRevoke, string(secret.Data["username"]))
+	}
+
+	current := reconcileBaoSecret(t, r, baoSecret)
+	require.Equal(t, "database/creds/app/1", current.Status.LeaseID)
+
+	// Аренда, оставшаяся от прежнего оператора без учёта в менеджере, тоже отзывается
+	r.LeaseManager.TrackFor(r.OpenBaoClient, leaseOwner(current), "database/creds/app",
+		&api.Secret{LeaseID: "database/creds/app/0", LeaseDuration: 60})
+
+	// Изменение spec — перевыпуск
+	current.Spec.SecretArgs = nil
+	current.Generation = 2
+	require.NoError(t, r.Update(ctx, current))
+	current = reconcileBaoSecret(t, r, baoSecret)
+
+	assert.Equal(t, "database/creds/app/2", current.Status.LeaseID)
+	assert.ElementsMatch(t, []string{"database/creds/app/0", "database/creds/app/1"}, bao.revoked)
+	assert.Equal(t, []string{"user-2", "user-2"}, usernameAtRevoke)
+	leases := r.LeaseManager.Leases(leaseOwner(current))
+	require.Len(t, leases, 1)
+	assert.Equal(t, "database/creds/app/2", leases[0].ID)
+
+}
@@ -0,0 +1,149 @@
+// Событийная пересинхронизация BaoSecret: запись в KV (kv-v2/data-write) ставит в очередь
+// только BaoSecret, читающие изменённый путь. Пока подписки нет, работает обычный опрос.
+package controller
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+const (
+	// Индекс BaoSecret по путям KV (secretPath и dataFrom)
+	kvPathIndex = ".spec.kvPaths"
+	// Размер буфера событий OpenBao; при переполнении событие теряется и секрет догонит опрос
+	kvEventBuffer = 256
+	// DefaultEventResyncInterval — страховочный опрос KV при активной подписке на события
+	DefaultEventResyncInterval = 30 * time.Minute
+	// requeueJitter — доля интервала, на которую случайно сдвигается следующий reconcile
+	requeueJitter = 0.1
+)
+
+// kvPaths — пути KV, изменение которых влияет на BaoSecret. Динамические секреты и wrapping
+// не зависят от записей в KV и в индекс не попадают.
+func kvPaths(obj client.Object) []string {
+	baoSecret := obj.(*kubebaoiov1alpha1.BaoSecret)
+	spec := &baoSecret.Spec
+	if isDynamicEngine(spec.SecretEngine) || spec.Wrapping != nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(spec.DataFrom)+1)
+	if spec.SecretPath != "" {
+		paths = append(paths, spec.SecretPath)
+	}
+	for _, source := range spec.DataFrom {
+		paths = append(paths, source.Path)
+	}
+	return paths
+}
+
+// kvEventHandler ставит в очередь BaoSecret, читающие путь из события на сервере подписки.
+func (r *BaoSecretReconciler) kvEventHandler(events chan<- event.GenericEvent) func(openbao.KVEvent) {
+	return func(ev openbao.KVEvent) {
+		if ev.Mount != r.EventWatcher.Mount() {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		list := &kubebaoiov1alpha1.BaoSecretList{}
+		if err := r.List(ctx, list, client.MatchingFields{kvPathIndex: ev.Path}); err != nil {
+			r.Log.Error(err, "Ошибка поиска BaoSecret по пути KV", "path", ev.Path)
+			return
+		}
+
+		for i := range list.Items {
+			// Тот же путь на другом сервере или в другом namespace OpenBao — другой секрет
+			if !r.watchedByEvents(&list.Items[i]) {
+				continue
+			}
+			obj := &kubebaoiov1alpha1.BaoSecret{ObjectMeta: metav1.ObjectMeta{
+				Name:      list.Items[i].Name,
+				Namespace: list.Items[i].Namespace,
+			}}
+			select {
+			case events <- event.GenericEvent{Object: obj}:
+				r.Log.V(1).Info("Запись в KV — внеочередная синхронизация", "path", ev.Path, "version", ev.Version,
+					"baosecret", client.ObjectKeyFromObject(obj))
+			default:
+				r.Log.V(1).Info("Очередь событий OpenBao переполнена", "path", ev.Path)
+			}
+		}
+	}
+}
+
+// kvResyncHandler ставит в очередь все BaoSecret с путями KV после восстановления подписки:
+// записи за время разрыва не пришли событиями, а интервал опроса при подписке длинный.
+func (r *BaoSecretReconciler) kvResyncHandler(events chan<- event.GenericEvent) func() {
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		list := &kubebaoiov1alpha1.BaoSecretList{}
+		if err := r.List(ctx, list); err != nil {
+			r.Log.Error(err, "Ошибка получения списка BaoSecret после восстановления подписки")
+			return
+		}
+
+		for i := range list.Items {
+			if len(kvPaths(&list.Items[i])) == 0 || !r.watchedByEvents(&list.Items[i]) {
+				continue
+			}
+			obj := &kubebaoiov1alpha1.BaoSecret{ObjectMeta: metav1.ObjectMeta{
+				Name:      list.Items[i].Name,
+				Namespace: list.Items[i].Namespace,
+			}}
+			select {
+			case events <- event.GenericEvent{Object: obj}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// eventDrivenInterval — при активной подписке KV-секреты опрашиваются реже: изменения
+// приходят событиями, а опрос с EventResyncInterval страхует от потерянных событий. Секреты
+// другого сервера или namespace OpenBao событий не получают и опрашиваются как обычно.
+func (r *BaoSecretReconciler) eventDrivenInterval(baoSecret *kubebaoiov1alpha1.BaoSecret, refreshInterval time.Duration) time.Duration {
+	if r.EventWatcher == nil || !r.EventWatcher.Connected() || len(kvPaths(baoSecret)) == 0 ||
+		!r.watchedByEvents(baoSecret) {
+		return refreshInterval
+	}
+
+	resync := r.EventResyncInterval
+	if resync <= 0 {
+		resync = DefaultEventResyncInterval
+	}
+	if resync > refreshInterval {
+		return resync
+	}
+	return refreshInterval
+}
+
+// watchedByEvents — BaoSecret читает тот же сервер и namespace OpenBao, что и подписка.
+func (r *BaoSecretReconciler) watchedByEvents(baoSecret *kubebaoiov1alpha1.BaoSecret) bool {
+	ref := baoSecret.Spec.OpenBaoRef
+	if ref == nil {
+		return true
+	}
+	return r.EventWatcher.Serves(ref.Address, ref.Namespace)
+}
+
+// withJitter добавляет к интервалу до 10%, чтобы BaoSecret, созданные одновременно
+// (например, при старте оператора), не обращались к OpenBao одной волной.
+func withJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Float64()*requeueJitter*float64(d))
+}
@@ -0,0 +1,190 @@
+// Перезапуск workload'ов после изменения целевого Secret: переменные окружения из Secret
+// читаются только при старте пода, поэтому в pod template записывается новый kubebao.io/version.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+const (
+	// Минимальный интервал между перезапусками по умолчанию
+	defaultRolloutMinInterval = time.Minute
+	// Причины событий Kubernetes при перезапуске workload'ов
+	eventReasonRolloutTriggered = "RolloutTriggered"
+	eventReasonRolloutFailed    = "RolloutFailed"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch;patch
+
+// rolloutMinInterval — разбор minInterval; некорректное значение заменяется значением по умолчанию.
+func rolloutMinInterval(rollout *kubebaoiov1alpha1.SecretRollout) time.Duration {
+	if rollout.MinInterval == "" {
+		return defaultRolloutMinInterval
+	}
+	d, err := time.ParseDuration(rollout.MinInterval)
+	if err != nil || d < 0 {
+		return defaultRolloutMinInterval
+	}
+	return d
+}
+
+// podTemplate — pod template workload'а поддерживаемого вида.
+func podTemplate(obj client.Object) *corev1.PodTemplateSpec {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template
+	}
+	return nil
+}
+
+// newWorkload — пустой объект workload'а по kind.
+func newWorkload(kind string) (client.Object, error) {
+	switch kind {
+	case kubebaoiov1alpha1.RolloutKindDeployment:
+		return &appsv1.Deployment{}, nil
+	case kubebaoiov1alpha1.RolloutKindStatefulSet:
+		return &appsv1.StatefulSet{}, nil
+	case kubebaoiov1alpha1.RolloutKindDaemonSet:
+		return &appsv1.DaemonSet{}, nil
+	}
+	return nil, fmt.Errorf("unsupported rollout kind %q", kind)
+}
+
+// rolloutWorkloads — workload'ы из targets и найденные по selector в namespace BaoSecret (он же
+// namespace целевого Secret: другой target.namespace с rollout отклоняется), ключ — Kind/name
+// (без повторов).
+func (r *BaoSecretReconciler) rolloutWorkloads(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret) (map[string]client.Object, error) {
+	rollout := baoSecret.Spec.Rollout
+	namespace := baoSecret.Namespace
+	workloads := make(map[string]client.Object)
+
+	for _, target := range rollout.Targets {
+		obj, err := newWorkload(target.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.Get(ctx, types.NamespacedName{Name: target.Name, Namespace: namespace}, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				r.Log.Info("Workload для перезапуска не найден", "kind", target.Kind, "name", target.Name, "namespace", namespace)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s %s: %w", target.Kind, target.Name, err)
+		}
+		workloads[target.Kind+"/"+target.Name] = obj
+	}
+
+	if rollout.Selector == nil {
+		return workloads, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(rollout.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rollout selector: %w", err)
+	}
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deployments, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		workloads[kubebaoiov1alpha1.RolloutKindDeployment+"/"+deployments.Items[i].Name] = &deployments.Items[i]
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.List(ctx, statefulSets, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		workloads[kubebaoiov1alpha1.RolloutKindStatefulSet+"/"+statefulSets.Items[i].Name] = &statefulSets.Items[i]
+	}
+
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := r.List(ctx, daemonSets, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		workloads[kubebaoiov1alpha1.RolloutKindDaemonSet+"/"+daemonSets.Items[i].Name] = &daemonSets.Items[i]
+	}
+	return workloads, nil
+}
+
+// rollout перезапускает workload'ы, если данные Secret изменились после прошлого перезапуска.
+// Перезапуски не чаще minInterval: возвращается время, через которое нужно повторить попытку
+// (0 — ждать нечего). Первая синхронизация только запоминает версию — поды и так читают Secret при старте.
+func (r *BaoSecretReconciler) rollout(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret) (time.Duration, error) {
+	if baoSecret.Spec.Rollout == nil || creationPolicy(baoSecret) == kubebaoiov1alpha1.CreationPolicyNone {
+		return 0, nil
+	}
+
+	version := baoSecret.Status.SecretVersion
+	if version == "" || version == baoSecret.Status.RolloutVersion {
+		return 0, nil
+	}
+	if baoSecret.Status.RolloutVersion == "" {
+		baoSecret.Status.RolloutVersion = version
+		return 0, nil
+	}
+
+	if last := baoSecret.Status.LastRolloutTime; last != nil {
+		if wait := rolloutMinInterval(baoSecret.Spec.Rollout) - time.Since(last.Time); wait > 0 {
+			r.Log.Info("Перезапуск workload'ов отложен (minInterval)",
+				"baosecret", client.ObjectKeyFromObject(baoSecret), "retryIn", wait)
+			return wait, nil
+		}
+	}
+
+	workloads, err := r.rolloutWorkloads(ctx, baoSecret)
+	if err != nil {
+		return 0, err
+	}
+
+	restarted := make([]string, 0, len(workloads))
+	for key, obj := range workloads {
+		template := podTemplate(obj)
+		if template.Annotations[annotationVersion] == version {
+			continue
+		}
+
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		if template.Annotations == nil {
+			template.Annotations = make(map[string]string)
+		}
+		template.Annotations[annotationVersion] = version
+		if err := r.Patch(ctx, obj, patch); err != nil {
+			return 0, fmt.Errorf("failed to restart %s: %w", key, err)
+		}
+		restarted = append(restarted, key)
+	}
+	sort.Strings(restarted)
+
+	now := metav1.Now()
+	baoSecret.Status.RolloutVersion = version
+	baoSecret.Status.LastRolloutTime = &now
+	baoSecret.Status.RestartedWorkloads = restarted
+
+	if len(restarted) > 0 {
+		r.Log.Info("Workload'ы перезапущены после изменения Secret",
+			"baosecret", client.ObjectKeyFromObject(baoSecret), "workloads", restarted, "version", version)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(baoSecret, corev1.EventTypeNormal, eventReasonRolloutTriggered,
+				"Restarted %d workload(s) for Secret version %s: %v", len(restarted), version, restarted)
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,191 @@
+// Тесты перезапуска workload'ов после изменения целевого Secret.
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// newTestRolloutReconciler — reconciler BaoSecret с fake-клиентом, знающим workload'ы apps/v1.
+func newTestRolloutReconciler(t *testing.T, objs ...client.Object) (*BaoSecretReconciler, *record.FakeRecorder) {
+	t.Helper()
+
+	scheme := newTestScheme(t)
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	recorder := record.NewFakeRecorder(10)
+	return &BaoSecretReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		Scheme:   scheme,
+		Log:      logr.Discard(),
+		Recorder: recorder,
+	}, recorder
+}
+
+func testDeployment(name string, labels, templateAnnotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Annotations: templateAnnotations},
+		}},
+	}
+}
+
+// rolloutBaoSecret — BaoSecret с rollout, у которого уже был перезапуск для версии v1.
+func rolloutBaoSecret(rollout *kubebaoiov1alpha1.SecretRollout, version string, lastRollout time.Duration) *kubebaoiov1alpha1.BaoSecret {
+	last := metav1.NewTime(time.Now().Add(-lastRollout))
+	return &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec: kubebaoiov1alpha1.BaoSecretSpec{
+			SecretPath: "app",
+			Target:     kubebaoiov1alpha1.SecretTarget{Name: "app"},
+			Rollout:    rollout,
+		},
+		Status: kubebaoiov1alpha1.BaoSecretStatus{
+			SecretVersion:   version,
+			RolloutVersion:  "v1",
+			LastRolloutTime: &last,
+		},
+	}
+}
+
+func templateVersion(t *testing.T, r *BaoSecretReconciler, name string) string {
+	t.Helper()
+
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, deployment))
+	return deployment.Spec.Template.Annotations[annotationVersion]
+}
+
+func TestRolloutFirstSyncRecordsVersion(t *testing.T) {
+	r, recorder := newTestRolloutReconciler(t, testDeployment("api", nil, nil))
+	baoSecret := rolloutBaoSecret(&kubebaoiov1alpha1.SecretRollout{
+		Targets: []kubebaoiov1alpha1.RolloutTarget{{Kind: kubebaoiov1alpha1.RolloutKindDeployment, Name: "api"}},
+	}, "v1", 0)
+	baoSecret.Status.RolloutVersion = ""
+	baoSecret.Status.LastRolloutTime = nil
+
+	wait, err := r.rollout(context.Background(), baoSecret)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.Equal(t, "v1", baoSecret.Status.RolloutVersion)
+	assert.Nil(t, baoSecret.Status.LastRolloutTime)
+	assert.Empty(t, templateVersion(t, r, "api"), "first sync must not restart workloads")
+	assert.Empty(t, recorder.Events)
+
+	// Та же версия — ничего не делается
+	wait, err = r.rollout(context.Background(), baoSecret)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.Empty(t, templateVersion(t, r, "api"))
+}
+
+func TestRolloutMinIntervalDefers(t *testing.T) {
+	r, _ := newTestRolloutReconciler(t, testDeployment("api", nil, nil))
+	rollout := &kubebaoiov1alpha1.SecretRollout{
+		Targets:     []kubebaoiov1alpha1.RolloutTarget{{Kind: kubebaoiov1alpha1.RolloutKindDeployment, Name: "api"}},
+		MinInterval: "5m",
+	}
+	baoSecret := rolloutBaoSecret(rollout, "v2", 2*time.Minute)
+
+	wait, err := r.rollout(context.Background(), baoSecret)
+	require.NoError(t, err)
+	assert.InDelta(t, (3 * time.Minute).Seconds(), wait.Seconds(), 1)
+	assert.Equal(t, "v1", baoSecret.Status.RolloutVersion)
+	assert.Empty(t, templateVersion(t, r, "api"))
+
+	// Интервал истёк — перезапуск
+	baoSecret = rolloutBaoSecret(rollout, "v2", 6*time.Minute)
+	wait, err = r.rollout(context.Background(), baoSecret)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	assert.Equal(t, "v2", baoSecret.Status.RolloutVersion)
+	assert.Equal(t, []string{"Deployment/api"}, baoSecret.Status.RestartedWorkloads)
+	assert.Equal(t, "v2", templateVersion(t, r, "api"))
+	require.NotNil(t, baoSecret.Status.LastRolloutTime)
+	assert.WithinDuration(t, time.Now(), baoSecret.Status.LastRolloutTime.Time, time.Second)
+}
+
+func TestRolloutWorkloadsDeduplicates(t *testing.T) {
+	labels := map[string]string{"app": "shop"}
+	r, _ := newTestRolloutReconciler(t,
+		testDeployment("api", labels, nil),
+		testDeployment("worker", nil, nil),
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", Labels: labels}},
+		// Workload с подходящими метками в другом namespace не перезапускается
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "agent", Labels: labels}},
+	)
+	baoSecret := rolloutBaoSecret(&kubebaoiov1alpha1.SecretRollout{
+		Targets: []kubebaoiov1alpha1.RolloutTarget{
+			{Kind: kubebaoiov1alpha1.RolloutKindDeployment, Name: "api"},
+			{Kind: kubebaoiov1alpha1.RolloutKindDeployment, Name: "api"},
+			{Kind: kubebaoiov1alpha1.RolloutKindDeployment, Name: "worker"},
+			// Отсутствующий workload пропускается
+			{Kind: kubebaoiov1alpha1.RolloutKindDaemonSet, Name: "missing"},
+		},
+		Selector: &metav1.LabelSelector{MatchLabels: labels},
+	}, "v2", time.Hour)
+
+	workloads, err := r.rolloutWorkloads(context.Background(), baoSecret)
+	require.NoError(t, err)
+	keys := make([]string, 0, len(workloads))
+	for key := range workloads {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, []string{"Deployment/api", "Deployment/worker", "StatefulSet/db"}, keys)
+
+	_, err = r.rollout(context.Background(), baoSecret)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deployment/api", "Deployment/worker", "StatefulSet/db"}, baoSecret.Status.RestartedWorkloads)
+
+	baoSecret.Spec.Rollout.Targets = []kubebaoiov1alpha1.RolloutTarget{{Kind: "CronJob", Name: "api"}}
+	_, err = r.rolloutWorkloads(context.Background(), baoSecret)
+	assert.EqualError(t, err, `unsupported rollout kind "CronJob"`)
+}
+
+func TestRolloutSkipsWorkloadWithVersion(t *testing.T) {
+	r, recorder := newTestRolloutReconciler(t,
+		testDeployment("api", nil, map[string]string{annotationVersion: "v2", "team": "a"}),
+		testDeployment("worker", nil, map[string]string{"team": "a"}),
+	)
+	baoSecret := rolloutBaoSecret(&kubebaoiov1alpha1.SecretRollout{
+		Targets: []kubebaoiov1alpha1.RolloutTarget{
+			{Kind: kubebaoiov1alpha1.RolloutKindDeployment, Name: "api"},
+			{Kind: kubebaoiov1alpha1.RolloutKindDeployment, Name: "worker"},
+		},
+	}, "v2", time.Hour)
+
+	_, err := r.rollout(context.Background(), baoSecret)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deployment/worker"}, baoSecret.Status.RestartedWorkloads)
+	assert.Equal(t, "v2", templateVersion(t, r, "worker"))
+
+	worker := &appsv1.Deployment{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "worker"}, worker))
+	assert.Equal(t, "a", worker.Spec.Template.Annotations["team"], "other annotations must be kept")
+
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Normal RolloutTriggered Restarted 1 workload(s) for Secret version v2: [Deployment/worker]", <-recorder.Events)
+
+	// Все workload'ы уже на текущей версии — события нет
+	baoSecret.Status.RolloutVersion = "v1"
+	baoSecret.Status.LastRolloutTime = nil
+	_, err = r.rollout(context.Background(), baoSecret)
+	require.NoError(t, err)
+	assert.Empty(t, baoSecret.Status.RestartedWorkloads)
+	assert.Equal(t, "v2", baoSecret.Status.RolloutVersion)
+	assert.Empty(t, recorder.Events)
+}
@@ -0,0 +1,143 @@
+// Несколько источников BaoSecret: secretPath и dataFrom объединяются в один Secret
+// с выбором ключей, переименованием, префиксами и политикой конфликтов.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+// validateSources проверяет сочетание secretPath, dataFrom, движка, wrapping и creationPolicy.
+func validateSources(spec *kubebaoiov1alpha1.BaoSecretSpec) error {
+	if spec.SecretPath == "" {
+		switch {
+		case len(spec.DataFrom) == 0:
+			return fmt.Errorf("either secretPath or dataFrom must be set")
+		case isDynamicEngine(spec.SecretEngine):
+			return fmt.Errorf("secretPath is required for secretEngine %q", spec.SecretEngine)
+		case spec.Wrapping != nil:
+			return fmt.Errorf("secretPath is required for wrapping")
+		case spec.SecretKey != "":
+			return fmt.Errorf("secretKey requires secretPath; use dataFrom[].keys instead")
+		}
+	}
+	if spec.Target.CreationPolicy == kubebaoiov1alpha1.CreationPolicyNone && (isDynamicEngine(spec.SecretEngine) || spec.Wrapping != nil) {
+		// Выданные учётные данные или токен некуда было бы доставить
+		return fmt.Errorf("creationPolicy: None is not supported with secretEngine %q or wrapping", spec.SecretEngine)
+	}
+	if len(spec.DataFrom) == 0 {
+		return nil
+	}
+
+	if isDynamicEngine(spec.SecretEngine) {
+		return fmt.Errorf("dataFrom is not supported with secretEngine %q: use a separate BaoSecret", spec.SecretEngine)
+	}
+	if spec.Wrapping != nil {
+		return fmt.Errorf("dataFrom is not supported with wrapping")
+	}
+
+	switch spec.ConflictPolicy {
+	case "", kubebaoiov1alpha1.ConflictPolicyError, kubebaoiov1alpha1.ConflictPolicyOverwrite, kubebaoiov1alpha1.ConflictPolicyKeepFirst:
+	default:
+		return fmt.Errorf("unsupported conflictPolicy %q", spec.ConflictPolicy)
+	}
+
+	for i, source := range spec.DataFrom {
+		if source.Path == "" {
+			return fmt.Errorf("dataFrom[%d].path is required", i)
+		}
+	}
+	return nil
+}
+
+// sourcePaths — пути всех источников для аннотации kubebao.io/source-path.
+func sourcePaths(spec *kubebaoiov1alpha1.BaoSecretSpec) string {
+	paths := make([]string, 0, len(spec.DataFrom)+1)
+	if spec.SecretPath != "" {
+		paths = append(paths, spec.SecretPath)
+	}
+	for _, source := range spec.DataFrom {
+		paths = append(paths, source.Path)
+	}
+	return strings.Join(paths, ",")
+}
+
+// sourceData — ключи одного источника после выбора, переименования и префикса.
+type sourceData struct {
+	path     string
+	data     map[string]interface{}
+	metadata map[string]interface{}
+}
+
+// readDataFrom читает источники dataFrom по порядку.
+func (r *BaoSecretReconciler) readDataFrom(ctx context.Context, baoClient *openbao.Client, baoSecret *kubebaoiov1alpha1.BaoSecret) ([]sourceData, error) {
+	sources := make([]sourceData, 0, len(baoSecret.Spec.DataFrom))
+	for _, source := range baoSecret.Spec.DataFrom {
+		data, metadata, err := baoClient.KVReadWithMetadata(ctx, source.Path)
+		if err != nil {
+			r.forgetClientOnDenied(baoSecret, err)
+			return nil, fmt.Errorf("failed to read dataFrom %s: %w", source.Path, err)
+		}
+
+		selected, err := selectSourceKeys(source, data)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, sourceData{path: source.Path, data: selected, metadata: metadata})
+	}
+	return sources, nil
+}
+
+// selectSourceKeys применяет Keys, затем Rename и Prefix.
+func selectSourceKeys(source kubebaoiov1alpha1.SecretSource, data map[string]interface{}) (map[string]interface{}, error) {
+	selected := data
+	if len(source.Keys) > 0 {
+		selected = make(map[string]interface{}, len(source.Keys))
+		for _, key := range source.Keys {
+			value, ok := data[key]
+			if !ok {
+				return nil, fmt.Errorf("key %s not found in %s", key, source.Path)
+			}
+			selected[key] = value
+		}
+	}
+
+	result := make(map[string]interface{}, len(selected))
+	for key, value := range selected {
+		if renamed, ok := source.Rename[key]; ok {
+			key = renamed
+		}
+		if _, exists := result[source.Prefix+key]; exists {
+			return nil, fmt.Errorf("rename produces duplicate key %s in %s", source.Prefix+key, source.Path)
+		}
+		result[source.Prefix+key] = value
+	}
+	return result, nil
+}
+
+// mergeSources объединяет источники по порядку с учётом политики конфликтов: Error — ошибка
+// с указанием обоих путей, Overwrite — побеждает более поздний источник, KeepFirst — ранний.
+func mergeSources(policy string, sources []sourceData) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	origin := make(map[string]string)
+	for _, source := range sources {
+		for key, value := range source.data {
+			if previous, exists := origin[key]; exists {
+				switch policy {
+				case kubebaoiov1alpha1.ConflictPolicyOverwrite:
+				case kubebaoiov1alpha1.ConflictPolicyKeepFirst:
+					continue
+				default:
+					return nil, fmt.Errorf("key %s from %s conflicts with %s (conflictPolicy: Error)", key, source.path, previous)
+				}
+			}
+			result[key] = value
+			origin[key] = source.path
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,120 @@
+// Тесты нескольких источников BaoSecret: выбор ключей и политика конфликтов.
+package controller
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestSelectSourceKeys(t *testing.T) {
+	data := map[string]interface{}{"username": "admin", "password": "s3cr3t", "host": "db"}
+
+	tests := []struct {
+		name    string
+		source  kubebaoiov1alpha1.SecretSource
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{name: "all keys", source: kubebaoiov1alpha1.SecretSource{Path: "app/db"}, want: data},
+		{name: "selected keys", source: kubebaoiov1alpha1.SecretSource{Path: "app/db", Keys: []string{"username", "password"}},
+			want: map[string]interface{}{"username": "admin", "password": "s3cr3t"}},
+		{name: "rename and prefix", source: kubebaoiov1alpha1.SecretSource{
+			Path:   "app/db",
+			Keys:   []string{"username", "password"},
+			Rename: map[string]string{"username": "USER"},
+			Prefix: "DB_",
+		}, want: map[string]interface{}{"DB_USER": "admin", "DB_password": "s3cr3t"}},
+		{name: "missing key", source: kubebaoiov1alpha1.SecretSource{Path: "app/db", Keys: []string{"token"}},
+			wantErr: "key token not found in app/db"},
+		{name: "rename onto another key", source: kubebaoiov1alpha1.SecretSource{
+			Path:   "app/db",
+			Keys:   []string{"username", "host"},
+			Rename: map[string]string{"username": "host"},
+		}, wantErr: "rename produces duplicate key host in app/db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectSourceKeys(tt.source, data)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeSources(t *testing.T) {
+	sources := []sourceData{
+		{path: "app/config", data: map[string]interface{}{"host": "config", "port": "5432"}},
+		{path: "app/db", data: map[string]interface{}{"host": "db", "username": "admin"}},
+	}
+
+	tests := []struct {
+		policy  string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{policy: "", wantErr: "key host from app/db conflicts with app/config (conflictPolicy: Error)"},
+		{policy: kubebaoiov1alpha1.ConflictPolicyError, wantErr: "key host from app/db conflicts with app/config"},
+		{policy: kubebaoiov1alpha1.ConflictPolicyOverwrite,
+			want: map[string]interface{}{"host": "db", "port": "5432", "username": "admin"}},
+		{policy: kubebaoiov1alpha1.ConflictPolicyKeepFirst,
+			want: map[string]interface{}{"host": "config", "port": "5432", "username": "admin"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			got, err := mergeSources(tt.policy, sources)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// Без пересечений политика не важна
+	got, err := mergeSources(kubebaoiov1alpha1.ConflictPolicyError, sources[1:])
+	require.NoError(t, err)
+	assert.Equal(t, sources[1].data, got)
+}
+
+func TestRenderSecretDataSecretKeyWithDataFrom(t *testing.T) {
+	r := &BaoSecretReconciler{Log: logr.Discard()}
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{Spec: kubebaoiov1alpha1.BaoSecretSpec{
+		SecretPath:     "app/config",
+		SecretKey:      "password",
+		DataFrom:       []kubebaoiov1alpha1.SecretSource{{Path: "app/db"}},
+		ConflictPolicy: kubebaoiov1alpha1.ConflictPolicyError,
+		Template:       &kubebaoiov1alpha1.SecretTemplate{StringData: map[string]string{"dsn": "{{ .Data.username }}:{{ .Data.password }}"}},
+	}}
+	// username есть в обоих путях, но из secretPath выбран только password — конфликта нет
+	secretData := map[string]interface{}{"username": "config-user", "password": "s3cr3t"}
+	sources := []sourceData{{path: "app/db", data: map[string]interface{}{"username": "admin"}}}
+
+	data, err := r.renderSecretData(baoSecret, secretData, nil, sources)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"password": []byte("s3cr3t"),
+		"username": []byte("admin"),
+		"dsn":      []byte("admin:s3cr3t"),
+	}, data)
+
+	// Выбранный ключ по-прежнему конфликтует
+	sources[0].data["password"] = "other"
+	_, err = r.renderSecretData(baoSecret, secretData, nil, sources)
+	assert.ErrorContains(t, err, "key password from app/db conflicts with app/config")
+
+	baoSecret.Spec.SecretKey = "token"
+	_, err = r.renderSecretData(baoSecret, secretData, nil, nil)
+	assert.EqualError(t, err, "key token not found in app/config")
+}
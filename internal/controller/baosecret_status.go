@@ -0,0 +1,121 @@
+// Статус BaoSecret: версия источника в KV, счётчик ошибок и ограниченная история синхронизаций.
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+const (
+	// Максимум записей в status.syncHistory
+	maxSyncHistory = 10
+	// Максимальная длина сообщения об ошибке в статусе
+	maxStatusMessage = 1024
+)
+
+// kvVersion — версия из метаданных KV v2 (json.Number или число), "" — если её нет.
+func kvVersion(metadata map[string]interface{}) string {
+	switch v := metadata["version"].(type) {
+	case json.Number:
+		return v.String()
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	case int:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// kvCreatedTime — время записи версии из метаданных KV v2.
+func kvCreatedTime(metadata map[string]interface{}) *metav1.Time {
+	value, _ := metadata["created_time"].(string)
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil
+	}
+	created := metav1.NewTime(t)
+	return &created
+}
+
+// setSourceVersion записывает версии прочитанных источников KV: secretPath, затем dataFrom.
+func (r *BaoSecretReconciler) setSourceVersion(baoSecret *kubebaoiov1alpha1.BaoSecret, metadata map[string]interface{}, sources []sourceData) {
+	versions := make([]string, 0, len(sources)+1)
+	var created *metav1.Time
+	if baoSecret.Spec.SecretPath != "" {
+		versions = append(versions, kvVersion(metadata))
+		created = kvCreatedTime(metadata)
+	}
+	for i, source := range sources {
+		versions = append(versions, kvVersion(source.metadata))
+		if i == 0 && baoSecret.Spec.SecretPath == "" {
+			created = kvCreatedTime(source.metadata)
+		}
+	}
+	baoSecret.Status.SourceVersion = strings.Join(versions, ",")
+	baoSecret.Status.SourceCreatedTime = created
+}
+
+// truncateMessage ограничивает сообщение для статуса.
+func truncateMessage(message string) string {
+	if len(message) <= maxStatusMessage {
+		return message
+	}
+	return message[:maxStatusMessage] + "..."
+}
+
+// recordSyncSuccess сбрасывает счётчик ошибок и добавляет успех в историю.
+func (r *BaoSecretReconciler) recordSyncSuccess(baoSecret *kubebaoiov1alpha1.BaoSecret) {
+	// Динамические секреты и wrapping не читают KV — версия источника не определена
+	if len(kvPaths(baoSecret)) == 0 {
+		baoSecret.Status.SourceVersion = ""
+		baoSecret.Status.SourceCreatedTime = nil
+	}
+	baoSecret.Status.ConsecutiveFailures = 0
+	baoSecret.Status.LastError = ""
+	appendSyncHistory(&baoSecret.Status, kubebaoiov1alpha1.SyncHistoryEntry{
+		Time:          metav1.Now(),
+		Result:        kubebaoiov1alpha1.SyncResultSuccess,
+		SourceVersion: baoSecret.Status.SourceVersion,
+		SecretVersion: baoSecret.Status.SecretVersion,
+	})
+}
+
+// recordSyncFailure увеличивает счётчик ошибок и добавляет ошибку в историю.
+func (r *BaoSecretReconciler) recordSyncFailure(baoSecret *kubebaoiov1alpha1.BaoSecret, err error) {
+	message := truncateMessage(err.Error())
+	baoSecret.Status.ConsecutiveFailures++
+	baoSecret.Status.LastError = message
+	appendSyncHistory(&baoSecret.Status, kubebaoiov1alpha1.SyncHistoryEntry{
+		Time:    metav1.Now(),
+		Result:  kubebaoiov1alpha1.SyncResultFailed,
+		Message: message,
+	})
+}
+
+// appendSyncHistory добавляет запись в начало истории. Повтор того же результата только
+// обновляет время последней записи — периодический опрос не вытесняет историю изменений.
+func appendSyncHistory(status *kubebaoiov1alpha1.BaoSecretStatus, entry kubebaoiov1alpha1.SyncHistoryEntry) {
+	if len(status.SyncHistory) > 0 {
+		last := &status.SyncHistory[0]
+		if last.Result == entry.Result && last.SourceVersion == entry.SourceVersion &&
+			last.SecretVersion == entry.SecretVersion && last.Message == entry.Message {
+			last.Time = entry.Time
+			return
+		}
+	}
+
+	history := append([]kubebaoiov1alpha1.SyncHistoryEntry{entry}, status.SyncHistory...)
+	if len(history) > maxSyncHistory {
+		history = history[:maxSyncHistory]
+	}
+	status.SyncHistory = history
+}
@@ -0,0 +1,108 @@
+// Тесты статуса BaoSecret: история синхронизаций и счётчик ошибок.
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestAppendSyncHistoryCapsEntries(t *testing.T) {
+	status := &kubebaoiov1alpha1.BaoSecretStatus{}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= maxSyncHistory+3; i++ {
+		appendSyncHistory(status, kubebaoiov1alpha1.SyncHistoryEntry{
+			Time:          metav1.NewTime(start.Add(time.Duration(i) * time.Minute)),
+			Result:        kubebaoiov1alpha1.SyncResultSuccess,
+			SourceVersion: fmt.Sprint(i),
+		})
+	}
+
+	// Новые записи — в начале, самые старые вытеснены
+	require.Len(t, status.SyncHistory, maxSyncHistory)
+	assert.Equal(t, fmt.Sprint(maxSyncHistory+3), status.SyncHistory[0].SourceVersion)
+	assert.Equal(t, "4", status.SyncHistory[maxSyncHistory-1].SourceVersion)
+}
+
+func TestAppendSyncHistoryCollapsesRepeats(t *testing.T) {
+	first := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(first.Add(time.Hour))
+	entry := kubebaoiov1alpha1.SyncHistoryEntry{
+		Time:          first,
+		Result:        kubebaoiov1alpha1.SyncResultSuccess,
+		SourceVersion: "3",
+		SecretVersion: "abc",
+	}
+	status := &kubebaoiov1alpha1.BaoSecretStatus{}
+	appendSyncHistory(status, entry)
+
+	// Тот же результат — обновляется только время
+	repeat := entry
+	repeat.Time = later
+	appendSyncHistory(status, repeat)
+	require.Len(t, status.SyncHistory, 1)
+	assert.Equal(t, later, status.SyncHistory[0].Time)
+
+	// Любое отличие — новая запись
+	changes := map[string]func(*kubebaoiov1alpha1.SyncHistoryEntry){
+		"result":         func(e *kubebaoiov1alpha1.SyncHistoryEntry) { e.Result = kubebaoiov1alpha1.SyncResultFailed },
+		"source version": func(e *kubebaoiov1alpha1.SyncHistoryEntry) { e.SourceVersion = "4" },
+		"secret version": func(e *kubebaoiov1alpha1.SyncHistoryEntry) { e.SecretVersion = "def" },
+		"message":        func(e *kubebaoiov1alpha1.SyncHistoryEntry) { e.Message = "permission denied" },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			status := &kubebaoiov1alpha1.BaoSecretStatus{}
+			appendSyncHistory(status, entry)
+			next := entry
+			next.Time = later
+			change(&next)
+			appendSyncHistory(status, next)
+
+			require.Len(t, status.SyncHistory, 2)
+			assert.Equal(t, next, status.SyncHistory[0])
+			assert.Equal(t, entry, status.SyncHistory[1])
+		})
+	}
+
+	// Повтор сравнивается только с последней записью
+	status = &kubebaoiov1alpha1.BaoSecretStatus{}
+	failed := kubebaoiov1alpha1.SyncHistoryEntry{Time: first, Result: kubebaoiov1alpha1.SyncResultFailed, Message: "denied"}
+	appendSyncHistory(status, entry)
+	appendSyncHistory(status, failed)
+	appendSyncHistory(status, repeat)
+	assert.Len(t, status.SyncHistory, 3)
+}
+
+func TestRecordSyncFailureAndSuccess(t *testing.T) {
+	r := &BaoSecretReconciler{Log: logr.Discard()}
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{Spec: kubebaoiov1alpha1.BaoSecretSpec{SecretPath: "app"}}
+
+	r.recordSyncFailure(baoSecret, errors.New("permission denied"))
+	r.recordSyncFailure(baoSecret, errors.New("permission denied"))
+	assert.Equal(t, int32(2), baoSecret.Status.ConsecutiveFailures)
+	assert.Equal(t, "permission denied", baoSecret.Status.LastError)
+	require.Len(t, baoSecret.Status.SyncHistory, 1)
+
+	// Длинное сообщение обрезается
+	r.recordSyncFailure(baoSecret, errors.New(strings.Repeat("x", 2*maxStatusMessage)))
+	assert.Len(t, baoSecret.Status.LastError, maxStatusMessage+len("..."))
+
+	baoSecret.Status.SourceVersion = "7"
+	r.recordSyncSuccess(baoSecret)
+	assert.Zero(t, baoSecret.Status.ConsecutiveFailures)
+	assert.Empty(t, baoSecret.Status.LastError)
+	require.Len(t, baoSecret.Status.SyncHistory, 3)
+	assert.Equal(t, kubebaoiov1alpha1.SyncResultSuccess, baoSecret.Status.SyncHistory[0].Result)
+	assert.Equal(t, "7", baoSecret.Status.SyncHistory[0].SourceVersion)
+}
@@ -0,0 +1,92 @@
+// Очистка целевого Secret по creationPolicy: при удалении BaoSecret и при смене target.
+// Secret в другом namespace не удаляется сборщиком мусора — его находит finalizer по меткам.
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// ownsTarget — метки Secret указывают на этот BaoSecret.
+func ownsTarget(baoSecret *kubebaoiov1alpha1.BaoSecret, secret *corev1.Secret) bool {
+	if secret.Labels[labelManagedBy] != managedByOperator || secret.Labels[labelBaoSecret] != baoSecret.Name {
+		return false
+	}
+	ownerNamespace := secret.Labels[labelBaoSecretNamespace]
+	if ownerNamespace == "" {
+		ownerNamespace = secret.Namespace
+	}
+	return ownerNamespace == baoSecret.Namespace
+}
+
+// syncedTarget — Secret, записанный последним успешным sync (из статуса), иначе target из spec.
+func (r *BaoSecretReconciler) syncedTarget(baoSecret *kubebaoiov1alpha1.BaoSecret) types.NamespacedName {
+	if baoSecret.Status.SyncedSecretName != "" {
+		namespace := baoSecret.Status.SyncedSecretNamespace
+		if namespace == "" {
+			namespace = baoSecret.Namespace
+		}
+		return types.NamespacedName{Name: baoSecret.Status.SyncedSecretName, Namespace: namespace}
+	}
+	return types.NamespacedName{Name: baoSecret.Spec.Target.Name, Namespace: r.targetNamespace(baoSecret)}
+}
+
+// cleanupTarget убирает то, что BaoSecret записал в Secret key: Owner — удаляет Secret,
+// Merge — удаляет свои ключи, метки и аннотации, Orphan и None — ничего не делают.
+// Secret, не помеченный как принадлежащий этому BaoSecret, не трогается.
+func (r *BaoSecretReconciler) cleanupTarget(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret, key types.NamespacedName) error {
+	log := r.Log.WithValues("baosecret", client.ObjectKeyFromObject(baoSecret), "secret", key)
+
+	policy := creationPolicy(baoSecret)
+	switch policy {
+	case kubebaoiov1alpha1.CreationPolicyOrphan:
+		log.Info("Секрет оставляется (Orphan policy)")
+		return nil
+	case kubebaoiov1alpha1.CreationPolicyNone:
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get target secret: %w", err)
+	}
+
+	if !ownsTarget(baoSecret, secret) {
+		log.Info("Секрет не принадлежит BaoSecret, очистка пропущена")
+		return nil
+	}
+
+	if policy == kubebaoiov1alpha1.CreationPolicyMerge {
+		patch := client.MergeFrom(secret.DeepCopy())
+		for _, k := range managedKeys(secret) {
+			delete(secret.Data, k)
+		}
+		delete(secret.Labels, labelManagedBy)
+		delete(secret.Labels, labelBaoSecret)
+		delete(secret.Labels, labelBaoSecretNamespace)
+		delete(secret.Annotations, annotationVersion)
+		delete(secret.Annotations, annotationManagedKeys)
+		delete(secret.Annotations, "kubebao.io/source-path")
+		if err := r.Patch(ctx, secret, patch); err != nil {
+			return fmt.Errorf("failed to remove merged keys from secret: %w", err)
+		}
+		log.Info("Ключи BaoSecret удалены из Secret (Merge policy)")
+		return nil
+	}
+
+	if err := r.Delete(ctx, secret, client.Preconditions{UID: &secret.UID}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete target secret: %w", err)
+	}
+	log.Info("Целевой Secret удалён")
+	return nil
+}
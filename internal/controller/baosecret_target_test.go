@@ -0,0 +1,175 @@
+// Тесты очистки целевого Secret по creationPolicy при удалении BaoSecret и смене target.
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestCleanupTargetOnDeletion(t *testing.T) {
+	synced := map[string][]byte{"username": []byte("admin"), "password": []byte("s3cr3t")}
+
+	tests := []struct {
+		name     string
+		target   kubebaoiov1alpha1.SecretTarget
+		existing map[string][]byte
+		// Данные Secret после удаления BaoSecret; nil — Secret удалён
+		want map[string][]byte
+	}{
+		{name: "Owner", target: kubebaoiov1alpha1.SecretTarget{Name: "app"}},
+		{name: "Owner in another namespace", target: kubebaoiov1alpha1.SecretTarget{Name: "app", Namespace: "billing"}},
+		{name: "Orphan", target: kubebaoiov1alpha1.SecretTarget{Name: "app", CreationPolicy: kubebaoiov1alpha1.CreationPolicyOrphan},
+			want: synced},
+		{name: "Merge", target: kubebaoiov1alpha1.SecretTarget{Name: "app", CreationPolicy: kubebaoiov1alpha1.CreationPolicyMerge},
+			existing: map[string][]byte{"password": []byte("old"), "token": []byte("foreign")},
+			want:     map[string][]byte{"token": []byte("foreign")}},
+		{name: "None", target: kubebaoiov1alpha1.SecretTarget{Name: "app", CreationPolicy: kubebaoiov1alpha1.CreationPolicyNone},
+			existing: map[string][]byte{"token": []byte("foreign")},
+			want:     map[string][]byte{"token": []byte("foreign")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baoSecret := &kubebaoiov1alpha1.BaoSecret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 1},
+				Spec:       kubebaoiov1alpha1.BaoSecretSpec{SecretPath: "app", Target: tt.target},
+			}
+			objs := []client.Object{baoSecret}
+			key := client.ObjectKey{Namespace: "default", Name: "app"}
+			if tt.target.Namespace != "" {
+				key.Namespace = tt.target.Namespace
+			}
+			if tt.existing != nil {
+				objs = append(objs, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, CreationTimestamp: metav1.Now(),
+						Labels: map[string]string{"team": "a"}},
+					Data: tt.existing,
+				})
+			}
+			r, _, _ := newTestBaoSecretReconciler(t, objs...)
+			ctx := context.Background()
+
+			current := reconcileBaoSecret(t, r, baoSecret)
+			require.True(t, meta.IsStatusConditionTrue(current.Status.Conditions, kubebaoiov1alpha1.ConditionTypeReady),
+				"sync failed: %v", current.Status.Conditions)
+
+			require.NoError(t, r.Delete(ctx, current))
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(baoSecret)})
+			require.NoError(t, err)
+			assert.True(t, apierrors.IsNotFound(r.Get(ctx, client.ObjectKeyFromObject(baoSecret), current)),
+				"finalizer must be removed")
+
+			secret := &corev1.Secret{}
+			err = r.Get(ctx, key, secret)
+			if tt.want == nil {
+				assert.True(t, apierrors.IsNotFound(err), "secret must be deleted, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, secret.Data)
+			if tt.existing != nil {
+				// Secret, который был до BaoSecret, возвращается к исходным меткам и аннотациям
+				assert.Equal(t, map[string]string{"team": "a"}, secret.Labels)
+				assert.Empty(t, secret.Annotations)
+			}
+		})
+	}
+}
+
+func TestCleanupTargetSkipsForeignSecret(t *testing.T) {
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec:       kubebaoiov1alpha1.BaoSecretSpec{SecretPath: "app", Target: kubebaoiov1alpha1.SecretTarget{Name: "app"}},
+	}
+	other := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Labels: map[string]string{
+			labelManagedBy: managedByOperator, labelBaoSecret: "other", labelBaoSecretNamespace: "default",
+		}},
+		Data: map[string][]byte{"password": []byte("other")},
+	}
+	merged := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shared", Labels: map[string]string{
+			labelManagedBy: managedByOperator, labelBaoSecret: "other", labelBaoSecretNamespace: "default",
+		}, Annotations: map[string]string{annotationManagedKeys: "password"}},
+		Data: map[string][]byte{"password": []byte("other")},
+	}
+	r, _, _ := newTestBaoSecretReconciler(t, baoSecret, other, merged)
+	ctx := context.Background()
+
+	require.NoError(t, r.cleanupTarget(ctx, baoSecret, client.ObjectKeyFromObject(other)))
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(other), other))
+	assert.Equal(t, []byte("other"), other.Data["password"])
+
+	baoSecret.Spec.Target.CreationPolicy = kubebaoiov1alpha1.CreationPolicyMerge
+	require.NoError(t, r.cleanupTarget(ctx, baoSecret, client.ObjectKeyFromObject(merged)))
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(merged), merged))
+	assert.Equal(t, []byte("other"), merged.Data["password"])
+	assert.Equal(t, "other", merged.Labels[labelBaoSecret])
+
+	// Отсутствующий Secret — не ошибка
+	assert.NoError(t, r.cleanupTarget(ctx, baoSecret, client.ObjectKey{Namespace: "default", Name: "missing"}))
+}
+
+func TestMergeRemovesOnlyOwnKeys(t *testing.T) {
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", CreationTimestamp: metav1.Now()},
+		Data:       map[string][]byte{"token": []byte("foreign")},
+	}
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 1},
+		Spec: kubebaoiov1alpha1.BaoSecretSpec{
+			SecretPath: "app",
+			Target:     kubebaoiov1alpha1.SecretTarget{Name: "app", CreationPolicy: kubebaoiov1alpha1.CreationPolicyMerge},
+		},
+	}
+	r, bao, _ := newTestBaoSecretReconciler(t, baoSecret, existing)
+	ctx := context.Background()
+
+	reconcileBaoSecret(t, r, baoSecret)
+	secret := &corev1.Secret{}
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(existing), secret))
+	assert.Equal(t, "password,username", secret.Annotations[annotationManagedKeys])
+	assert.Len(t, secret.Data, 3)
+
+	// Ключ пропал из OpenBao — удаляется из Secret, чужой ключ остаётся
+	bao.data["secret/data/app"]["data"] = map[string]interface{}{"password": "s3cr3t"}
+	reconcileBaoSecret(t, r, baoSecret)
+	require.NoError(t, r.Get(ctx, client.ObjectKeyFromObject(existing), secret))
+	assert.Equal(t, map[string][]byte{"password": []byte("s3cr3t"), "token": []byte("foreign")}, secret.Data)
+	assert.Equal(t, "password", secret.Annotations[annotationManagedKeys])
+	assert.False(t, r.targetDrifted(baoSecret, secret))
+
+	// Изменение чужого ключа — не дрейф
+	secret.Data["token"] = []byte("rotated")
+	assert.False(t, r.targetDrifted(baoSecret, secret))
+}
+
+func TestCleanupPreviousTargetOnTargetChange(t *testing.T) {
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 1},
+		Spec:       kubebaoiov1alpha1.BaoSecretSpec{SecretPath: "app", Target: kubebaoiov1alpha1.SecretTarget{Name: "app"}},
+	}
+	r, _, _ := newTestBaoSecretReconciler(t, baoSecret)
+	ctx := context.Background()
+
+	current := reconcileBaoSecret(t, r, baoSecret)
+	current.Spec.Target = kubebaoiov1alpha1.SecretTarget{Name: "app-v2", Namespace: "billing"}
+	require.NoError(t, r.Update(ctx, current))
+
+	current = reconcileBaoSecret(t, r, baoSecret)
+	assert.Equal(t, "app-v2", current.Status.SyncedSecretName)
+	assert.Equal(t, "billing", current.Status.SyncedSecretNamespace)
+	assert.True(t, apierrors.IsNotFound(r.Get(ctx, client.ObjectKey{Namespace: "default", Name: "app"}, &corev1.Secret{})))
+	require.NoError(t, r.Get(ctx, client.ObjectKey{Namespace: "billing", Name: "app-v2"}, &corev1.Secret{}))
+}
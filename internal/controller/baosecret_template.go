@@ -0,0 +1,241 @@
+// Шаблоны BaoSecret: text/template с ограниченным набором функций. Доступа к окружению,
+// файлам и сети у шаблона нет — только данные секрета и его метаданные.
+package controller
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+	"software.sslmate.com/src/go-pkcs12"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// templateContext — данные, доступные шаблону: {{ .Data.key }} и {{ .Metadata.version }}.
+// Для KV метаданные берутся из ответа OpenBao (version, created_time, custom_metadata),
+// для динамических движков — lease_id, lease_duration, renewable. Всегда есть path.
+type templateContext struct {
+	Data     map[string]interface{}
+	Metadata map[string]interface{}
+}
+
+// templateError — ошибка разбора или выполнения шаблона для ключа Key.
+type templateError struct {
+	Key string
+	Err error
+}
+
+func (e *templateError) Error() string {
+	return fmt.Sprintf("template for key %q: %v", e.Key, e.Err)
+}
+
+func (e *templateError) Unwrap() error {
+	return e.Err
+}
+
+// templateFuncs — разрешённые функции шаблона.
+var templateFuncs = template.FuncMap{
+	"b64enc":          b64enc,
+	"b64dec":          b64dec,
+	"toJson":          toJSON,
+	"fromJson":        fromJSON,
+	"toYaml":          toYAML,
+	"fromYaml":        fromYAML,
+	"pkcs12":          pkcs12ToPEM,
+	"pkcs12Pass":      pkcs12ToPEMWithPassword,
+	"pemCertificates": pemCertificates,
+	"default":         defaultValue,
+	"upper":           func(v interface{}) string { return strings.ToUpper(toString(v)) },
+	"lower":           func(v interface{}) string { return strings.ToLower(toString(v)) },
+}
+
+// applyTemplate — рендер StringData и Data поверх data. Отсутствующий ключ .Data — ошибка,
+// а не "<no value>" в секрете; для необязательных ключей используйте index и default.
+func (r *BaoSecretReconciler) applyTemplate(data map[string][]byte, tmpl *kubebaoiov1alpha1.SecretTemplate, tctx templateContext) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+
+	// Data, затем StringData — как в Kubernetes, StringData имеет приоритет
+	for _, templates := range []map[string]string{tmpl.Data, tmpl.StringData} {
+		for _, key := range sortedKeys(templates) {
+			value, err := renderTemplate(key, templates[key], tctx)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+		}
+	}
+
+	return result, nil
+}
+
+// renderTemplate разбирает и выполняет один шаблон.
+func renderTemplate(key, text string, tctx templateContext) ([]byte, error) {
+	t, err := template.New(key).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, &templateError{Key: key, Err: err}
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, tctx); err != nil {
+		return nil, &templateError{Key: key, Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toString — строковое представление значения секрета ([]byte как есть, nil — пустая строка).
+func toString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func b64enc(v interface{}) string {
+	return base64.StdEncoding.EncodeToString([]byte(toString(v)))
+}
+
+func b64dec(v interface{}) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(toString(v))
+	if err != nil {
+		return "", fmt.Errorf("b64dec: %w", err)
+	}
+	return string(decoded), nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toJson: %w", err)
+	}
+	return string(encoded), nil
+}
+
+func fromJSON(v interface{}) (interface{}, error) {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(toString(v)), &decoded); err != nil {
+		return nil, fmt.Errorf("fromJson: %w", err)
+	}
+	return decoded, nil
+}
+
+// toYAML — YAML без завершающего перевода строки, чтобы значение можно было вставить в строку.
+func toYAML(v interface{}) (string, error) {
+	encoded, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toYaml: %w", err)
+	}
+	return strings.TrimSuffix(string(encoded), "\n"), nil
+}
+
+// fromYAML разбирает YAML так же, как fromJson — JSON: объекты становятся map[string]interface{}.
+func fromYAML(v interface{}) (interface{}, error) {
+	var decoded interface{}
+	if err := yaml.Unmarshal([]byte(toString(v)), &decoded); err != nil {
+		return nil, fmt.Errorf("fromYaml: %w", err)
+	}
+	return decoded, nil
+}
+
+// defaultValue — def, если value пустое: {{ index .Data "port" | default "5432" }}.
+func defaultValue(def, value interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return def
+		}
+	}
+	return value
+}
+
+// pkcs12ToPEM — PEM (ключ, сертификат и цепочка CA) из PKCS#12 в base64 без пароля.
+func pkcs12ToPEM(v interface{}) (string, error) {
+	return pkcs12ToPEMWithPassword("", v)
+}
+
+// pkcs12ToPEMWithPassword — {{ .Data.keystore | pkcs12Pass .Data.password }}. Ключ выводится
+// первым в PKCS#8 (PRIVATE KEY), затем сертификат и цепочка CA в порядке бандла.
+func pkcs12ToPEMWithPassword(password string, v interface{}) (string, error) {
+	pfx, err := base64.StdEncoding.DecodeString(toString(v))
+	if err != nil {
+		return "", fmt.Errorf("pkcs12: bundle must be base64: %w", err)
+	}
+
+	key, cert, caCerts, err := pkcs12.DecodeChain(pfx, password)
+	if err != nil {
+		return "", fmt.Errorf("pkcs12: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("pkcs12: %w", err)
+	}
+
+	blocks := []*pem.Block{{Type: "PRIVATE KEY", Bytes: der}, {Type: "CERTIFICATE", Bytes: cert.Raw}}
+	for _, ca := range caCerts {
+		blocks = append(blocks, &pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})
+	}
+
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		if err := pem.Encode(&buf, block); err != nil {
+			return "", fmt.Errorf("pkcs12: %w", err)
+		}
+	}
+	return buf.String(), nil
+}
+
+// pemCertificates — только блоки CERTIFICATE из PEM-бандла (например, цепочка без ключа).
+func pemCertificates(v interface{}) (string, error) {
+	rest := []byte(toString(v))
+	var buf bytes.Buffer
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return "", fmt.Errorf("pemCertificates: %w", err)
+		}
+		if err := pem.Encode(&buf, &pem.Block{Type: block.Type, Bytes: block.Bytes}); err != nil {
+			return "", fmt.Errorf("pemCertificates: %w", err)
+		}
+	}
+	if buf.Len() == 0 {
+		return "", fmt.Errorf("pemCertificates: no certificates found")
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,215 @@
+// Тесты шаблонов BaoSecret: функции шаблона, отсутствующие ключи и порядок Data и StringData.
+package controller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// newTestCertificate — самоподписанный сертификат и его ключ.
+func newTestCertificate(t *testing.T, commonName string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// pemBlocks — типы блоков PEM по порядку.
+func pemBlocks(t *testing.T, data string) []*pem.Block {
+	t.Helper()
+
+	var blocks []*pem.Block
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	require.Empty(t, rest)
+	return blocks
+}
+
+func TestRenderTemplate(t *testing.T) {
+	tctx := templateContext{
+		Data: map[string]interface{}{
+			"username": "admin",
+			"password": "s3cr3t",
+			"my-key":   "dashed",
+			"config":   `{"host":"db","port":5432}`,
+			"encoded":  base64.StdEncoding.EncodeToString([]byte("plain")),
+			"yaml":     "db:\n  host: db\nhosts:\n- primary\n- replica\n",
+			"invalid":  "key: [unclosed",
+		},
+		Metadata: map[string]interface{}{"version": 3},
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "data and metadata", text: "{{ .Data.username }}@v{{ .Metadata.version }}", want: "admin@v3"},
+		{name: "b64enc", text: "{{ .Data.username | b64enc }}", want: "YWRtaW4="},
+		{name: "b64dec", text: "{{ .Data.encoded | b64dec }}", want: "plain"},
+		{name: "b64dec of invalid base64", text: "{{ .Data.username | b64dec }}", wantErr: "b64dec"},
+		{name: "fromJson", text: `{{ (.Data.config | fromJson).host }}`, want: "db"},
+		{name: "fromJson of invalid JSON", text: "{{ .Data.username | fromJson }}", wantErr: "fromJson"},
+		{name: "toJson", text: `{{ .Data.username | toJson }}`, want: `"admin"`},
+		{name: "toYaml", text: `{{ .Data.config | fromJson | toYaml }}`, want: "host: db\nport: 5432"},
+		{name: "toYaml of a string", text: `{{ .Data.username | toYaml }}`, want: "admin"},
+		{name: "fromYaml", text: `{{ (.Data.yaml | fromYaml).db.host }}:{{ index (.Data.yaml | fromYaml).hosts 1 }}`, want: "db:replica"},
+		{name: "fromYaml of JSON", text: `{{ (.Data.config | fromYaml).port }}`, want: "5432"},
+		{name: "fromYaml of invalid YAML", text: `{{ .Data.invalid | fromYaml }}`, wantErr: "fromYaml"},
+		{name: "upper and lower", text: "{{ .Data.username | upper }}-{{ .Data.password | lower }}", want: "ADMIN-s3cr3t"},
+		{name: "key with dash via index", text: `{{ index .Data "my-key" }}`, want: "dashed"},
+		// .Data.my-key разбирается как .Data.my минус key — ошибка разбора, а не пустое значение
+		{name: "key with dash via field", text: "{{ .Data.my-key }}", wantErr: "bad character"},
+		{name: "missing key", text: "{{ .Data.missing }}", wantErr: `map has no entry for key "missing"`},
+		{name: "missing key with default", text: `{{ index .Data "missing" | default "none" }}`, want: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := renderTemplate("key", tt.text, tctx)
+			if tt.wantErr != "" {
+				var tmplErr *templateError
+				require.True(t, errors.As(err, &tmplErr), "expected templateError, got %v", err)
+				assert.Equal(t, "key", tmplErr.Key)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Equal(t, kubebaoiov1alpha1.ReasonTemplateError, failureReason(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(value))
+		})
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	r := &BaoSecretReconciler{}
+	data := map[string][]byte{"username": []byte("admin"), "url": []byte("old")}
+	tctx := templateContext{Data: map[string]interface{}{"username": "admin", "host": "db"}}
+
+	result, err := r.applyTemplate(data, &kubebaoiov1alpha1.SecretTemplate{
+		Data: map[string]string{
+			"url":  "postgres://{{ .Data.username }}@{{ .Data.host }}",
+			"dsn":  "from data",
+			"host": "{{ .Data.host | upper }}",
+		},
+		StringData: map[string]string{"dsn": "from stringData"},
+	}, tctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"username": []byte("admin"),
+		"url":      []byte("postgres://admin@db"),
+		"host":     []byte("DB"),
+		// StringData имеет приоритет над Data
+		"dsn": []byte("from stringData"),
+	}, result)
+	// Исходные данные не изменяются
+	assert.Equal(t, []byte("old"), data["url"])
+
+	_, err = r.applyTemplate(data, &kubebaoiov1alpha1.SecretTemplate{
+		StringData: map[string]string{"dsn": "{{ .Data.password }}"},
+	}, tctx)
+	var tmplErr *templateError
+	require.True(t, errors.As(err, &tmplErr))
+	assert.Equal(t, "dsn", tmplErr.Key)
+}
+
+func TestPKCS12ToPEM(t *testing.T) {
+	caCert, _ := newTestCertificate(t, "ca")
+	cert, key := newTestCertificate(t, "leaf")
+
+	pfx, err := pkcs12.Modern.Encode(key, cert, []*x509.Certificate{caCert}, "changeit")
+	require.NoError(t, err)
+	bundle := base64.StdEncoding.EncodeToString(pfx)
+
+	out, err := pkcs12ToPEMWithPassword("changeit", bundle)
+	require.NoError(t, err)
+	blocks := pemBlocks(t, out)
+	require.Len(t, blocks, 3)
+
+	// Ключ в PKCS#8, затем сертификат и цепочка CA
+	assert.Equal(t, "PRIVATE KEY", blocks[0].Type)
+	parsedKey, err := x509.ParsePKCS8PrivateKey(blocks[0].Bytes)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsedKey))
+	assert.Equal(t, "CERTIFICATE", blocks[1].Type)
+	assert.Equal(t, cert.Raw, blocks[1].Bytes)
+	assert.Equal(t, "CERTIFICATE", blocks[2].Type)
+	assert.Equal(t, caCert.Raw, blocks[2].Bytes)
+	for _, block := range blocks {
+		assert.Empty(t, block.Headers)
+	}
+
+	// Через шаблон
+	value, err := renderTemplate("tls.crt", "{{ .Data.keystore | pkcs12Pass .Data.password | pemCertificates }}",
+		templateContext{Data: map[string]interface{}{"keystore": bundle, "password": "changeit"}})
+	require.NoError(t, err)
+	assert.Len(t, pemBlocks(t, string(value)), 2)
+
+	_, err = pkcs12ToPEMWithPassword("wrong", bundle)
+	assert.Error(t, err)
+	_, err = pkcs12ToPEM("not base64!")
+	assert.ErrorContains(t, err, "must be base64")
+
+	// Бандл без пароля
+	pfx, err = pkcs12.Modern.Encode(key, cert, nil, "")
+	require.NoError(t, err)
+	out, err = pkcs12ToPEM(base64.StdEncoding.EncodeToString(pfx))
+	require.NoError(t, err)
+	assert.Len(t, pemBlocks(t, out), 2)
+}
+
+func TestPEMCertificates(t *testing.T) {
+	cert, key := newTestCertificate(t, "leaf")
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	bundle := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Headers: map[string]string{"Name": "leaf"}, Bytes: cert.Raw}))
+
+	out, err := pemCertificates(bundle)
+	require.NoError(t, err)
+	blocks := pemBlocks(t, out)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, "CERTIFICATE", blocks[0].Type)
+	assert.Empty(t, blocks[0].Headers)
+	assert.Equal(t, cert.Raw, blocks[0].Bytes)
+
+	_, err = pemCertificates(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})))
+	assert.ErrorContains(t, err, "no certificates found")
+	_, err = pemCertificates(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")})))
+	assert.Error(t, err)
+}
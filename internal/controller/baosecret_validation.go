@@ -0,0 +1,301 @@
+// Проверка и значения по умолчанию spec BaoSecret: интервалы, тип целевого Secret, шаблоны и
+// источники. Одни и те же правила применяет admission webhook и reconcile, поэтому ошибка видна
+// при apply, а объект, созданный без webhook, получает Ready=False с причиной InvalidSpec
+// вместо молчаливой подмены.
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// secretTypeKeys — типы Secret, которые может записать оператор, и обязательные для них ключи.
+var secretTypeKeys = map[corev1.SecretType][]string{
+	corev1.SecretTypeOpaque:           nil,
+	corev1.SecretTypeTLS:              {corev1.TLSCertKey, corev1.TLSPrivateKeyKey},
+	corev1.SecretTypeDockerConfigJson: {corev1.DockerConfigJsonKey},
+	corev1.SecretTypeDockercfg:        {corev1.DockerConfigKey},
+	corev1.SecretTypeBasicAuth:        nil,
+	corev1.SecretTypeSSHAuth:          {corev1.SSHAuthPrivateKey},
+	corev1.SecretTypeBootstrapToken:   nil,
+}
+
+// ValidateBaoSecret — ошибки spec BaoSecret из namespace namespace с путями полей.
+func ValidateBaoSecret(namespace string, spec *kubebaoiov1alpha1.BaoSecretSpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if err := validateSources(spec); err != nil {
+		errs = append(errs, field.Invalid(specPath, spec.SecretPath, err.Error()))
+	}
+
+	errs = append(errs, validateDuration(specPath.Child("refreshInterval"), spec.RefreshInterval)...)
+	if spec.Wrapping != nil {
+		errs = append(errs, validateDuration(specPath.Child("wrapping", "ttl"), spec.Wrapping.TTL)...)
+	}
+	if spec.Rollout != nil {
+		errs = append(errs, validateDuration(specPath.Child("rollout", "minInterval"), spec.Rollout.MinInterval)...)
+		// Права оператора на patch workload'ов — во всём кластере: перезапуск ограничен namespace
+		// BaoSecret, иначе через target.namespace можно было бы перезапускать чужие workload'ы
+		if spec.Target.Namespace != "" && spec.Target.Namespace != namespace {
+			errs = append(errs, field.Forbidden(specPath.Child("rollout"),
+				"rollout restarts workloads only in the namespace of the BaoSecret, target.namespace must be empty or equal to it"))
+		}
+	}
+
+	errs = append(errs, validateTargetType(spec, specPath.Child("target", "type"))...)
+
+	if spec.Template != nil && spec.Wrapping == nil {
+		dataKeys, known := templateDataKeys(spec)
+		templatePath := specPath.Child("template")
+		for _, templates := range []struct {
+			path      *field.Path
+			templates map[string]string
+		}{
+			{templatePath.Child("data"), spec.Template.Data},
+			{templatePath.Child("stringData"), spec.Template.StringData},
+		} {
+			for _, key := range sortedKeys(templates.templates) {
+				if err := validateTemplate(key, templates.templates[key], dataKeys, known); err != nil {
+					errs = append(errs, field.Invalid(templates.path.Key(key), templates.templates[key], err.Error()))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// DefaultBaoSecret заполняет пустые поля spec значениями по умолчанию CRD (те же, что берёт
+// reconcile), чтобы сохранённый объект показывал, с какими настройками он синхронизируется.
+func DefaultBaoSecret(spec *kubebaoiov1alpha1.BaoSecretSpec) {
+	if spec.SecretEngine == "" {
+		spec.SecretEngine = "kv"
+	}
+	if spec.RefreshInterval == "" {
+		spec.RefreshInterval = "1h"
+	}
+	if spec.ConflictPolicy == "" {
+		spec.ConflictPolicy = kubebaoiov1alpha1.ConflictPolicyError
+	}
+	if spec.Target.Type == "" {
+		spec.Target.Type = string(corev1.SecretTypeOpaque)
+	}
+	if spec.Target.CreationPolicy == "" {
+		spec.Target.CreationPolicy = kubebaoiov1alpha1.CreationPolicyOwner
+	}
+	if spec.OpenBaoRef != nil && spec.OpenBaoRef.AuthMethod == "" {
+		spec.OpenBaoRef.AuthMethod = kubebaoiov1alpha1.AuthMethodKubernetes
+	}
+	if spec.Wrapping != nil {
+		if spec.Wrapping.TTL == "" {
+			spec.Wrapping.TTL = "5m"
+		}
+		if spec.Wrapping.TokenKey == "" {
+			spec.Wrapping.TokenKey = defaultWrappingTokenKey
+		}
+	}
+	if spec.Rollout != nil && spec.Rollout.MinInterval == "" {
+		spec.Rollout.MinInterval = "1m"
+	}
+}
+
+// validateDuration — непустое значение должно быть положительной длительностью Go ("30s", "5m", "1h").
+func validateDuration(fldPath *field.Path, value string) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be a duration such as 30s, 5m or 1h")}
+	}
+	if d <= 0 {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be positive")}
+	}
+	return nil
+}
+
+// validateTargetType — тип Secret, который API-сервер примет от оператора. Свои типы допустимы
+// в виде <домен>/<имя> вне kubernetes.io; обязательные ключи проверяются, если набор ключей известен.
+func validateTargetType(spec *kubebaoiov1alpha1.BaoSecretSpec, fldPath *field.Path) field.ErrorList {
+	secretType := corev1.SecretType(spec.Target.Type)
+	if secretType == "" {
+		return nil
+	}
+
+	required, ok := secretTypeKeys[secretType]
+	if !ok {
+		known := make([]string, 0, len(secretTypeKeys))
+		for t := range secretTypeKeys {
+			known = append(known, string(t))
+		}
+		sort.Strings(known)
+		domain, _, custom := strings.Cut(string(secretType), "/")
+		domain = strings.ToLower(domain)
+		if !custom || domain == "kubernetes.io" || strings.HasSuffix(domain, ".kubernetes.io") {
+			return field.ErrorList{field.NotSupported(fldPath, spec.Target.Type,
+				append(known, "<domain>/<name> for a custom type"))}
+		}
+		return nil
+	}
+
+	keys, known := targetKeys(spec)
+	var missing []string
+	for _, key := range required {
+		if !keys[key] {
+			missing = append(missing, key)
+		}
+	}
+	if known && len(missing) > 0 {
+		return field.ErrorList{field.Invalid(fldPath, spec.Target.Type,
+			fmt.Sprintf("type %s requires keys %s, the Secret would only contain %s",
+				secretType, strings.Join(missing, ", "), strings.Join(sortedSet(keys), ", ")))}
+	}
+	return nil
+}
+
+// templateDataKeys — ключи .Data шаблона. Известны, только если secretPath не задан, а у всех
+// источников dataFrom перечислены keys: ключи секрета OpenBao до чтения неизвестны.
+func templateDataKeys(spec *kubebaoiov1alpha1.BaoSecretSpec) (map[string]bool, bool) {
+	keys := make(map[string]bool)
+	known := spec.SecretPath == "" && !isDynamicEngine(spec.SecretEngine)
+	for _, source := range spec.DataFrom {
+		if len(source.Keys) == 0 {
+			known = false
+		}
+		for _, key := range source.Keys {
+			if renamed, ok := source.Rename[key]; ok {
+				key = renamed
+			}
+			keys[source.Prefix+key] = true
+		}
+	}
+	return keys, known
+}
+
+// targetKeys — ключи целевого Secret: wrapping-токен, либо secretKey и ключи источников вместе с
+// ключами шаблона. Ключи шаблона учитываются, даже если остальные неизвестны.
+func targetKeys(spec *kubebaoiov1alpha1.BaoSecretSpec) (map[string]bool, bool) {
+	if spec.Wrapping != nil {
+		tokenKey := spec.Wrapping.TokenKey
+		if tokenKey == "" {
+			tokenKey = defaultWrappingTokenKey
+		}
+		return map[string]bool{tokenKey: true}, true
+	}
+
+	keys, known := templateDataKeys(spec)
+	if spec.SecretKey != "" && !isDynamicEngine(spec.SecretEngine) {
+		keys[spec.SecretKey] = true
+		known = !slices.ContainsFunc(spec.DataFrom, func(s kubebaoiov1alpha1.SecretSource) bool { return len(s.Keys) == 0 })
+	}
+	if spec.Template != nil {
+		for key := range spec.Template.Data {
+			keys[key] = true
+		}
+		for key := range spec.Template.StringData {
+			keys[key] = true
+		}
+	}
+	return keys, known
+}
+
+// validateTemplate разбирает шаблон с функциями reconcile и проверяет обращения к полям: корень —
+// только .Data и .Metadata, а .Data.<key> — существующий ключ, если набор ключей известен.
+// Необязательные ключи читаются через index и default и не проверяются.
+func validateTemplate(key, text string, dataKeys map[string]bool, known bool) error {
+	t, err := template.New(key).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	var walkErr error
+	var walk func(node parse.Node, topLevel bool)
+	checkFields := func(idents []string) {
+		if walkErr != nil || len(idents) == 0 {
+			return
+		}
+		switch idents[0] {
+		case "Data":
+			if len(idents) > 1 && known && !dataKeys[idents[1]] {
+				walkErr = fmt.Errorf("references undefined key .Data.%s, available keys: %s",
+					idents[1], strings.Join(sortedSet(dataKeys), ", "))
+			}
+		case "Metadata":
+		default:
+			walkErr = fmt.Errorf("references unknown field .%s, only .Data and .Metadata are available", idents[0])
+		}
+	}
+	walk = func(node parse.Node, topLevel bool) {
+		if node == nil || walkErr != nil {
+			return
+		}
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, topLevel)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, topLevel)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd, topLevel)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, topLevel)
+			}
+		case *parse.FieldNode:
+			// Внутри range и with точка указывает на другой объект
+			if topLevel {
+				checkFields(n.Ident)
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				checkFields(n.Ident[1:])
+			}
+		case *parse.ChainNode:
+			walk(n.Node, topLevel)
+		case *parse.IfNode:
+			walk(n.Pipe, topLevel)
+			walk(n.List, topLevel)
+			walk(n.ElseList, topLevel)
+		case *parse.RangeNode:
+			walk(n.Pipe, topLevel)
+			walk(n.List, false)
+			walk(n.ElseList, topLevel)
+		case *parse.WithNode:
+			walk(n.Pipe, topLevel)
+			walk(n.List, false)
+			walk(n.ElseList, topLevel)
+		case *parse.TemplateNode:
+			walk(n.Pipe, topLevel)
+		}
+	}
+	walk(t.Tree.Root, true)
+	return walkErr
+}
+
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,189 @@
+// Secret-zero доставка: вместо данных BaoSecret кладёт в Secret одноразовый wrapping-токен,
+// который workload извлекает сам через sys/wrapping/unwrap. Итоговый секрет в etcd не попадает.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+const (
+	// Ключ целевого Secret с wrapping-токеном по умолчанию
+	defaultWrappingTokenKey = "token"
+
+	// Аннотации целевого Secret для отслеживания выданного токена
+	annotationSourceVersion    = "kubebao.io/source-version"
+	annotationWrappingAccessor = "kubebao.io/wrapping-accessor"
+	annotationWrappingExpiry   = "kubebao.io/wrapping-expiry"
+	annotationWrappingConsumed = "kubebao.io/wrapping-consumed"
+)
+
+// syncWrappedSecret выдаёт wrapping-токен на чтение KV-секрета, если его ещё нет, исходная версия
+// изменилась или прежний токен истёк неиспользованным. Использованный токен повторно не выдаётся:
+// новый появится при изменении секрета в OpenBao или после удаления целевого Secret.
+func (r *BaoSecretReconciler) syncWrappedSecret(ctx context.Context, baoSecret *kubebaoiov1alpha1.BaoSecret, baoClient *openbao.Client) error {
+	log := r.Log.WithValues("baosecret", types.NamespacedName{
+		Name:      baoSecret.Name,
+		Namespace: baoSecret.Namespace,
+	})
+
+	tokenKey := baoSecret.Spec.Wrapping.TokenKey
+	if tokenKey == "" {
+		tokenKey = defaultWrappingTokenKey
+	}
+
+	// Метаданные KV дают текущую версию без чтения самого секрета
+	meta, err := baoClient.KVReadMetadata(ctx, baoSecret.Spec.SecretPath)
+	if err != nil {
+		r.forgetClientOnDenied(baoSecret, err)
+		return fmt.Errorf("failed to read secret metadata from OpenBao: %w", err)
+	}
+	sourceVersion := strconv.Itoa(meta.CurrentVersion)
+
+	existing := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Name: baoSecret.Spec.Target.Name, Namespace: r.targetNamespace(baoSecret)}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		existing = nil
+	case err != nil:
+		return fmt.Errorf("failed to get target secret: %w", err)
+	}
+
+	if existing != nil && existing.Annotations[annotationSourceVersion] == sourceVersion && len(existing.Data[tokenKey]) > 0 &&
+		!r.targetDrifted(baoSecret, existing) {
+		reissue, err := r.checkWrappingToken(ctx, baoClient, existing, tokenKey)
+		if err != nil {
+			return err
+		}
+		if !reissue {
+			return nil
+		}
+	} else if existing != nil && existing.Annotations[annotationWrappingAccessor] != "" {
+		// Неиспользованный токен прежней версии больше не нужен
+		r.revokeWrappingToken(ctx, baoClient, existing)
+	}
+
+	ttl := r.parseWrapTTL(baoSecret.Spec.Wrapping.TTL)
+	info, err := baoClient.KVReadWrapped(ctx, baoSecret.Spec.SecretPath, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to read wrapped secret from OpenBao: %w", err)
+	}
+
+	created := info.CreationTime
+	if created.IsZero() {
+		created = time.Now()
+	}
+	expiry := metav1.NewTime(created.Add(time.Duration(info.TTL) * time.Second))
+
+	data := map[string][]byte{tokenKey: []byte(info.Token)}
+	err = r.writeTargetSecret(ctx, baoSecret, data, func(secret *corev1.Secret) {
+		secret.Annotations[annotationSourceVersion] = sourceVersion
+		secret.Annotations[annotationWrappingAccessor] = info.Accessor
+		secret.Annotations[annotationWrappingExpiry] = expiry.UTC().Format(time.RFC3339)
+		delete(secret.Annotations, annotationWrappingConsumed)
+	})
+	if err != nil {
+		return err
+	}
+
+	baoSecret.Status.WrappingTokenExpiry = &expiry
+	log.Info("Wrapping-токен доставлен", "sourceVersion", sourceVersion, "expiry", expiry.Time)
+	return nil
+}
+
+// checkWrappingToken решает, нужен ли новый токен для текущей версии. Действующий токен
+// остаётся на месте; недействительный до истечения TTL означает, что workload его использовал.
+func (r *BaoSecretReconciler) checkWrappingToken(ctx context.Context, baoClient *openbao.Client, secret *corev1.Secret, tokenKey string) (bool, error) {
+	if secret.Annotations[annotationWrappingConsumed] != "" {
+		return false, nil
+	}
+
+	expiry, err := time.Parse(time.RFC3339, secret.Annotations[annotationWrappingExpiry])
+	if err != nil {
+		return true, nil
+	}
+
+	_, err = baoClient.WrappingLookup(ctx, string(secret.Data[tokenKey]))
+	switch {
+	case err == nil:
+		return false, nil
+	case isInvalidWrappingToken(err) && time.Now().Before(expiry):
+		r.Log.Info("Wrapping-токен использован workload'ом",
+			"secret", secret.Name,
+			"namespace", secret.Namespace,
+		)
+		patch := client.MergeFrom(secret.DeepCopy())
+		secret.Annotations[annotationWrappingConsumed] = time.Now().UTC().Format(time.RFC3339)
+		if err := r.Patch(ctx, secret, patch); err != nil {
+			return false, fmt.Errorf("failed to mark wrapping token as consumed: %w", err)
+		}
+		return false, nil
+	case isInvalidWrappingToken(err):
+		// Токен истёк неиспользованным — выдаём новый
+		return true, nil
+	default:
+		return false, fmt.Errorf("failed to lookup wrapping token: %w", err)
+	}
+}
+
+// revokeWrappingToken отзывает прежний токен по accessor; ошибка не критична —
+// токен всё равно истечёт по TTL.
+func (r *BaoSecretReconciler) revokeWrappingToken(ctx context.Context, baoClient *openbao.Client, secret *corev1.Secret) {
+	if secret.Annotations[annotationWrappingConsumed] != "" {
+		return
+	}
+	if err := baoClient.RevokeWrappingAccessor(ctx, secret.Annotations[annotationWrappingAccessor]); err != nil {
+		r.Log.V(1).Info("Не удалось отозвать прежний wrapping-токен", "secret", secret.Name, "error", err.Error())
+	}
+}
+
+// isInvalidWrappingToken — OpenBao отвечает 400 на lookup использованного или истёкшего токена.
+func isInvalidWrappingToken(err error) bool {
+	return openbao.StatusCode(err) == 400
+}
+
+// parseWrapTTL — TTL wrapping-токена ("5m"); по умолчанию openbao.DefaultWrapTTL.
+func (r *BaoSecretReconciler) parseWrapTTL(ttl string) time.Duration {
+	if ttl == "" {
+		return openbao.DefaultWrapTTL
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		return openbao.DefaultWrapTTL
+	}
+	return d
+}
+
+// wrappingRecheckInterval — проверка токена незадолго до истечения, чтобы отличить
+// использованный токен от истёкшего и вовремя выдать замену.
+func (r *BaoSecretReconciler) wrappingRecheckInterval(baoSecret *kubebaoiov1alpha1.BaoSecret, refreshInterval time.Duration) time.Duration {
+	expiry := baoSecret.Status.WrappingTokenExpiry
+	if expiry == nil {
+		return refreshInterval
+	}
+
+	ttl := r.parseWrapTTL(baoSecret.Spec.Wrapping.TTL)
+	until := time.Until(expiry.Time) - ttl/10
+	switch {
+	case until <= 0:
+		// После проверки перед истечением — следующая сразу после него
+		until = time.Until(expiry.Time) + time.Second
+		if until <= 0 {
+			return refreshInterval
+		}
+	case until > refreshInterval:
+		return refreshInterval
+	}
+	return until
+}
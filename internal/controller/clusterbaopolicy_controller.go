@@ -0,0 +1,33 @@
+// Контроллер ClusterBaoPolicy — кластерные политики OpenBao без префикса namespace.
+package controller
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// ClusterBaoPolicyReconciler — контроллер ClusterBaoPolicy; синхронизация, дрейф и удаление
+// общие с BaoPolicyReconciler.
+type ClusterBaoPolicyReconciler struct {
+	BaoPolicyReconciler
+}
+
+// +kubebuilder:rbac:groups=kubebao.io,resources=clusterbaopolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kubebao.io,resources=clusterbaopolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kubebao.io,resources=clusterbaopolicies/finalizers,verbs=update
+
+// Reconcile — цикл согласования ClusterBaoPolicy.
+func (r *ClusterBaoPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.reconcilePolicy(ctx, req, &kubebaoiov1alpha1.ClusterBaoPolicy{})
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *ClusterBaoPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kubebaoiov1alpha1.ClusterBaoPolicy{}, builder.WithPredicates(specChanged)).
+		Complete(r)
+}
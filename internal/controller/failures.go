@@ -0,0 +1,103 @@
+// Ошибки reconcile: причина для условий и событий и экспоненциальная задержка повтора.
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+const (
+	// Задержка повтора после первой ошибки; удваивается с каждой следующей
+	minFailureBackoff = 5 * time.Second
+	// Предел задержки повтора
+	maxFailureBackoff = 5 * time.Minute
+
+	// Причина события Kubernetes после успешной синхронизации с изменением данных
+	eventReasonSynced = "Synced"
+)
+
+// specChanged — фильтр For: reconcile по созданию, изменению spec (generation) и началу удаления,
+// но не по записи собственного status. Каждая ошибка меняет consecutiveFailures, и без фильтра
+// запись status сразу ставила бы объект в очередь в обход failureBackoff.
+var specChanged = predicate.Or[client.Object](
+	predicate.GenerationChangedPredicate{},
+	predicate.Funcs{UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.ObjectOld.GetDeletionTimestamp() == nil && e.ObjectNew.GetDeletionTimestamp() != nil
+	}},
+)
+
+// authenticationError — не удалось получить клиент OpenBao (вход или конфигурация подключения).
+type authenticationError struct {
+	Err error
+}
+
+func (e *authenticationError) Error() string {
+	return "failed to authenticate to OpenBao: " + e.Err.Error()
+}
+
+func (e *authenticationError) Unwrap() error {
+	return e.Err
+}
+
+// invalidSpecError — spec отклонён проверкой до обращения к OpenBao; повтор без правки не поможет.
+type invalidSpecError struct {
+	errs field.ErrorList
+}
+
+func (e *invalidSpecError) Error() string {
+	return "invalid spec: " + e.errs.ToAggregate().Error()
+}
+
+// failureReason — причина условия и события для ошибки reconcile. Недоступность OpenBao
+// проверяется раньше аутентификации: вход при запечатанном или недоступном сервере тоже не удаётся.
+func failureReason(err error) string {
+	var tmplErr *templateError
+	var unmanaged *unmanagedTargetError
+	var authErr *authenticationError
+	var constraintErr *constraintViolationError
+	var specErr *invalidSpecError
+	switch {
+	case errors.As(err, &tmplErr):
+		return kubebaoiov1alpha1.ReasonTemplateError
+	case errors.As(err, &unmanaged):
+		return kubebaoiov1alpha1.ReasonUnmanagedTarget
+	case isOwnershipConflict(err):
+		return kubebaoiov1alpha1.ReasonOwnershipConflict
+	case errors.As(err, &constraintErr):
+		return kubebaoiov1alpha1.ReasonConstraintViolation
+	case errors.As(err, &specErr):
+		return kubebaoiov1alpha1.ReasonInvalidSpec
+	case openbao.IsUnavailable(err) || openbao.IsRetryable(err):
+		return kubebaoiov1alpha1.ReasonOpenBaoUnavailable
+	case errors.As(err, &authErr):
+		return kubebaoiov1alpha1.ReasonAuthenticationFailed
+	case openbao.IsPermissionDenied(err):
+		return kubebaoiov1alpha1.ReasonPermissionDenied
+	case openbao.IsNotFound(err):
+		return kubebaoiov1alpha1.ReasonSecretNotFound
+	}
+	return kubebaoiov1alpha1.ReasonFailed
+}
+
+// failureBackoff — задержка повтора после failures ошибок подряд: 5s, 10s, 20s… до 5m.
+func failureBackoff(failures int32) time.Duration {
+	if failures < 1 {
+		failures = 1
+	}
+	d := minFailureBackoff
+	for i := int32(1); i < failures && d < maxFailureBackoff; i++ {
+		d *= 2
+	}
+	if d > maxFailureBackoff {
+		d = maxFailureBackoff
+	}
+	return withJitter(d)
+}
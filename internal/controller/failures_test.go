@@ -0,0 +1,168 @@
+// Тесты причин ошибок reconcile и задержки повтора.
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+func TestFailureReason(t *testing.T) {
+	status := func(code int, messages ...string) error {
+		return &openbao.Error{Op: "KVRead", Path: "secret/data/app", StatusCode: code, Errors: messages}
+	}
+	sealed := status(http.StatusServiceUnavailable, "Vault is sealed")
+	forbidden := status(http.StatusForbidden, "permission denied")
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "sealed", err: sealed, want: kubebaoiov1alpha1.ReasonOpenBaoUnavailable},
+		{name: "503", err: status(http.StatusServiceUnavailable), want: kubebaoiov1alpha1.ReasonOpenBaoUnavailable},
+		{name: "502", err: status(http.StatusBadGateway), want: kubebaoiov1alpha1.ReasonOpenBaoUnavailable},
+		// 429 повторяем, как недоступность, — отдельной причины для rate limit нет
+		{name: "429", err: status(http.StatusTooManyRequests), want: kubebaoiov1alpha1.ReasonOpenBaoUnavailable},
+		{name: "connection refused", err: &openbao.Error{Op: "KVRead", Err: syscall.ECONNREFUSED},
+			want: kubebaoiov1alpha1.ReasonOpenBaoUnavailable},
+		// Ошибка сертификата не временная
+		{name: "unknown CA", err: &openbao.Error{Op: "KVRead", Err: x509.UnknownAuthorityError{}},
+			want: kubebaoiov1alpha1.ReasonFailed},
+		{name: "403", err: forbidden, want: kubebaoiov1alpha1.ReasonPermissionDenied},
+		{name: "401", err: status(http.StatusUnauthorized), want: kubebaoiov1alpha1.ReasonPermissionDenied},
+		{name: "404", err: status(http.StatusNotFound), want: kubebaoiov1alpha1.ReasonSecretNotFound},
+		{name: "wrapped 404", err: fmt.Errorf("failed to read secret: %w", status(http.StatusNotFound)),
+			want: kubebaoiov1alpha1.ReasonSecretNotFound},
+		{name: "400", err: status(http.StatusBadRequest, "invalid request"), want: kubebaoiov1alpha1.ReasonFailed},
+		{name: "auth error", err: &authenticationError{Err: errors.New("no token")},
+			want: kubebaoiov1alpha1.ReasonAuthenticationFailed},
+		// Отказ во входе — ошибка аутентификации, а не прав на путь
+		{name: "auth error with 403", err: &authenticationError{Err: forbidden},
+			want: kubebaoiov1alpha1.ReasonAuthenticationFailed},
+		{name: "wrapped auth error", err: fmt.Errorf("failed to get client: %w", &authenticationError{Err: forbidden}),
+			want: kubebaoiov1alpha1.ReasonAuthenticationFailed},
+		// Вход при запечатанном OpenBao — недоступность, а не ошибка аутентификации
+		{name: "auth error while sealed", err: &authenticationError{Err: sealed},
+			want: kubebaoiov1alpha1.ReasonOpenBaoUnavailable},
+		{name: "template error", err: &templateError{Key: "dsn", Err: status(http.StatusNotFound)},
+			want: kubebaoiov1alpha1.ReasonTemplateError},
+		{name: "unmanaged target", err: fmt.Errorf("sync: %w", &unmanagedTargetError{namespace: "default", name: "app"}),
+			want: kubebaoiov1alpha1.ReasonUnmanagedTarget},
+		{name: "ownership conflict", err: &ownershipConflictError{kind: "secret", path: "secret/data/app"},
+			want: kubebaoiov1alpha1.ReasonOwnershipConflict},
+		{name: "constraint violation", err: &constraintViolationError{errs: field.ErrorList{field.Forbidden(field.NewPath("spec", "rules"), "forbidden")}},
+			want: kubebaoiov1alpha1.ReasonConstraintViolation},
+		{name: "invalid spec", err: &invalidSpecError{errs: field.ErrorList{field.Required(field.NewPath("spec", "roleName"), "required")}},
+			want: kubebaoiov1alpha1.ReasonInvalidSpec},
+		{name: "unknown", err: errors.New("boom"), want: kubebaoiov1alpha1.ReasonFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, failureReason(tt.err))
+		})
+	}
+}
+
+func TestFailureBackoff(t *testing.T) {
+	tests := []struct {
+		failures int32
+		want     time.Duration
+	}{
+		{failures: 0, want: 5 * time.Second},
+		{failures: 1, want: 5 * time.Second},
+		{failures: 2, want: 10 * time.Second},
+		{failures: 3, want: 20 * time.Second},
+		{failures: 6, want: 160 * time.Second},
+		// 5s * 2^6 = 320s — ограничено 5m
+		{failures: 7, want: maxFailureBackoff},
+		{failures: 100, want: maxFailureBackoff},
+		{failures: math.MaxInt32, want: maxFailureBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.failures), func(t *testing.T) {
+			// Джиттер только увеличивает задержку, не более чем на requeueJitter
+			for i := 0; i < 20; i++ {
+				got := failureBackoff(tt.failures)
+				assert.GreaterOrEqual(t, got, tt.want)
+				assert.LessOrEqual(t, got, tt.want+time.Duration(requeueJitter*float64(tt.want)))
+			}
+		})
+	}
+}
+
+// assertStatusWriteFiltered — запись status между before и after не ставит объект в очередь,
+// а изменение spec и начало удаления ставят.
+func assertStatusWriteFiltered(t *testing.T, before, after client.Object) {
+	t.Helper()
+
+	assert.False(t, specChanged.Update(event.UpdateEvent{ObjectOld: before, ObjectNew: after}),
+		"status write must not requeue the object")
+
+	specUpdate := after.DeepCopyObject().(client.Object)
+	specUpdate.SetGeneration(after.GetGeneration() + 1)
+	assert.True(t, specChanged.Update(event.UpdateEvent{ObjectOld: after, ObjectNew: specUpdate}))
+
+	deleting := after.DeepCopyObject().(client.Object)
+	now := metav1.Now()
+	deleting.SetDeletionTimestamp(&now)
+	assert.True(t, specChanged.Update(event.UpdateEvent{ObjectOld: after, ObjectNew: deleting}))
+}
+
+func TestBaoSecretFailuresDoNotRequeueImmediately(t *testing.T) {
+	_, baoClient := newFakeOpenBao(t)
+	baoSecret := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 1, Finalizers: []string{baoSecretFinalizer}},
+		Spec: kubebaoiov1alpha1.BaoSecretSpec{
+			SecretPath: "app",
+			Target:     kubebaoiov1alpha1.SecretTarget{Name: "app"},
+		},
+	}
+	scheme := newTestScheme(t)
+	require.NoError(t, corev1.AddToScheme(scheme))
+	r := &BaoSecretReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(baoSecret).WithStatusSubresource(baoSecret).Build(),
+		Log:           logr.Discard(),
+		OpenBaoClient: baoClient,
+	}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(baoSecret)}
+
+	// Путь отсутствует в OpenBao: каждая ошибка меняет status, задержка растёт
+	previous := &kubebaoiov1alpha1.BaoSecret{}
+	require.NoError(t, r.Get(ctx, req.NamespacedName, previous))
+	for failures := int32(1); failures <= 3; failures++ {
+		result, err := r.Reconcile(ctx, req)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, result.RequeueAfter, minFailureBackoff<<(failures-1))
+
+		current := &kubebaoiov1alpha1.BaoSecret{}
+		require.NoError(t, r.Get(ctx, req.NamespacedName, current))
+		assert.Equal(t, failures, current.Status.ConsecutiveFailures)
+		assert.NotEqual(t, previous.ResourceVersion, current.ResourceVersion)
+		assertStatusWriteFiltered(t, previous, current)
+		previous = current
+	}
+}
@@ -0,0 +1,267 @@
+// Кеш клиентов OpenBao для ресурсов с собственным OpenBaoRef — каждый tenant читает секреты
+// под своей identity (ServiceAccount своего namespace), а не под токеном оператора.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+const (
+	// ServiceAccount для входа, если в OpenBaoRef не задан serviceAccountRef
+	defaultServiceAccountName = "default"
+	// Срок жизни JWT из TokenRequest — нужен только на время login
+	serviceAccountTokenTTL = 10 * time.Minute
+	// Неиспользуемые клиенты удаляются из кеша через clientIdleTTL
+	clientIdleTTL = time.Hour
+	// DefaultTenantAudience — audience JWT tenant'а, если OpenBaoClientsOptions.Audience не задан
+	DefaultTenantAudience = "openbao"
+)
+
+// OpenBaoClientsOptions — ограничения входа tenant'ов.
+type OpenBaoClientsOptions struct {
+	// Audience JWT из TokenRequest. Токен с audience API-сервера годился бы и для входа в
+	// Kubernetes API от имени ServiceAccount, поэтому роль OpenBao tenant'а должна требовать
+	// этот audience (audience в BaoRole kubernetes, boundAudiences в BaoRole jwt).
+	Audience string
+	// AllowedAddresses — адреса, допустимые в openbaoRef.address, помимо адресов оператора.
+	// JWT tenant'а отправляется только на эти серверы.
+	AllowedAddresses []string
+}
+
+// OpenBaoClients — потокобезопасный кеш аутентифицированных клиентов по ключу
+// (адрес, namespace OpenBao, auth mount, ServiceAccount, роль).
+type OpenBaoClients struct {
+	k8s      client.Client
+	base     *openbao.Config
+	audience string
+	allowed  []string
+	logger   hclog.Logger
+
+	mu      sync.Mutex
+	entries map[string]*cachedClient
+}
+
+type cachedClient struct {
+	client   *openbao.Client
+	lastUsed time.Time
+}
+
+// NewOpenBaoClients — base задаёт адрес, TLS и таймауты по умолчанию; токен и Kubernetes auth
+// оператора в клиенты tenant'ов не переносятся. Адреса base всегда допустимы в openbaoRef.address.
+func NewOpenBaoClients(k8s client.Client, base *openbao.Config, opts OpenBaoClientsOptions, logger hclog.Logger) *OpenBaoClients {
+	if base == nil {
+		base = &openbao.Config{}
+	}
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+	audience := opts.Audience
+	if audience == "" {
+		audience = DefaultTenantAudience
+	}
+
+	var allowed []string
+	for _, address := range append(base.AllAddresses(), opts.AllowedAddresses...) {
+		if address = normalizeAddress(address); address != "" {
+			allowed = append(allowed, address)
+		}
+	}
+
+	return &OpenBaoClients{
+		k8s:      k8s,
+		base:     base,
+		audience: audience,
+		allowed:  allowed,
+		logger:   logger,
+		entries:  make(map[string]*cachedClient),
+	}
+}
+
+// AllowedAddresses — адреса, допустимые в openbaoRef.address (для admission webhook).
+func (c *OpenBaoClients) AllowedAddresses() []string {
+	return append([]string(nil), c.allowed...)
+}
+
+// ValidateOpenBaoAddress отклоняет openbaoRef.address вне списка allowed: иначе tenant получил бы
+// JWT своего ServiceAccount на собственный сервер.
+func ValidateOpenBaoAddress(path *field.Path, ref *kubebaoiov1alpha1.OpenBaoReference, allowed []string) field.ErrorList {
+	if ref == nil || ref.Address == "" {
+		return nil
+	}
+	address := normalizeAddress(ref.Address)
+	for _, a := range allowed {
+		if normalizeAddress(a) == address {
+			return nil
+		}
+	}
+	return field.ErrorList{field.Forbidden(path.Child("address"), fmt.Sprintf(
+		"address %s is not allowed: add it to the operator flag --openbao-allowed-addresses", ref.Address))}
+}
+
+// normalizeAddress — адрес без пробелов и завершающего "/", схема и хост без учёта регистра.
+func normalizeAddress(address string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(address), "/"))
+}
+
+// Get возвращает клиент для ресурса из namespace с OpenBaoRef ref (может быть nil) и ролью role.
+// ServiceAccount берётся только из namespace ресурса.
+func (c *OpenBaoClients) Get(ctx context.Context, namespace string, ref *kubebaoiov1alpha1.OpenBaoReference, role string) (*openbao.Client, error) {
+	cfg, key, err := c.configFor(namespace, ref, role)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.evictIdle()
+	if entry, ok := c.entries[key]; ok {
+		entry.lastUsed = time.Now()
+		c.mu.Unlock()
+		return entry.client, nil
+	}
+	c.mu.Unlock()
+
+	// Login выполняется вне блокировки — он ходит в OpenBao и Kubernetes API
+	baoClient, err := openbao.NewClient(cfg, c.logger.With("namespace", namespace, "role", cfg.KubernetesAuth.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		entry.lastUsed = time.Now()
+		return entry.client, nil
+	}
+	c.entries[key] = &cachedClient{client: baoClient, lastUsed: time.Now()}
+	return baoClient, nil
+}
+
+// Forget удаляет клиент из кеша (например, после 403: роль или политики изменились).
+func (c *OpenBaoClients) Forget(namespace string, ref *kubebaoiov1alpha1.OpenBaoReference, role string) {
+	_, key, err := c.configFor(namespace, ref, role)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+// evictIdle вызывается под c.mu.
+func (c *OpenBaoClients) evictIdle() {
+	for key, entry := range c.entries {
+		if time.Since(entry.lastUsed) > clientIdleTTL {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// configFor строит конфигурацию клиента tenant'а поверх base и ключ кеша: клиенты различаются
+// адресом, namespace OpenBao, auth mount, ролью и ServiceAccount.
+func (c *OpenBaoClients) configFor(namespace string, ref *kubebaoiov1alpha1.OpenBaoReference, role string) (*openbao.Config, string, error) {
+	if ref == nil {
+		ref = &kubebaoiov1alpha1.OpenBaoReference{}
+	}
+
+	if errs := ValidateOpenBaoAddress(field.NewPath("spec", "openbaoRef"), ref, c.allowed); len(errs) > 0 {
+		return nil, "", &invalidSpecError{errs: errs}
+	}
+
+	switch ref.AuthMethod {
+	case "", "kubernetes", "jwt":
+	default:
+		return nil, "", fmt.Errorf("unsupported authMethod %q: only kubernetes and jwt are supported", ref.AuthMethod)
+	}
+
+	saName := defaultServiceAccountName
+	if sa := ref.ServiceAccountRef; sa != nil {
+		if sa.Namespace != "" && sa.Namespace != namespace {
+			return nil, "", fmt.Errorf("serviceAccountRef must be in namespace %s, got %s", namespace, sa.Namespace)
+		}
+		if sa.Name != "" {
+			saName = sa.Name
+		}
+	}
+
+	if role == "" && c.base.KubernetesAuth != nil {
+		role = c.base.KubernetesAuth.Role
+	}
+	if role == "" {
+		return nil, "", fmt.Errorf("roleName is required for a per-resource OpenBao connection")
+	}
+
+	mountPath := ref.AuthMountPath
+	if mountPath == "" {
+		mountPath = ref.AuthMethod
+	}
+	if mountPath == "" {
+		mountPath = "kubernetes"
+	}
+
+	cfg := *c.base
+	cfg.Token = ""
+	if ref.Address != "" {
+		cfg.Address = ref.Address
+		cfg.Addresses = nil
+	}
+	if ref.Namespace != "" {
+		cfg.Namespace = ref.Namespace
+	}
+	cfg.KubernetesAuth = &openbao.KubernetesAuthConfig{
+		Role:        role,
+		MountPath:   mountPath,
+		JWTProvider: c.tokenProvider(namespace, saName),
+	}
+
+	if len(cfg.AllAddresses()) == 0 {
+		return nil, "", fmt.Errorf("OpenBao address is not configured: set openbaoRef.address")
+	}
+
+	key := strings.Join([]string{
+		strings.Join(cfg.AllAddresses(), ","),
+		cfg.Namespace,
+		mountPath,
+		role,
+		namespace + "/" + saName,
+	}, "|")
+
+	return &cfg, key, nil
+}
+
+// tokenProvider выпускает краткоживущий JWT ServiceAccount через TokenRequest с audience OpenBao:
+// такой токен не принимается Kubernetes API.
+func (c *OpenBaoClients) tokenProvider(namespace, name string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		expiration := int64(serviceAccountTokenTTL.Seconds())
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		request := &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				Audiences:         []string{c.audience},
+				ExpirationSeconds: &expiration,
+			},
+		}
+
+		if err := c.k8s.SubResource("token").Create(ctx, sa, request); err != nil {
+			return "", fmt.Errorf("token request for serviceaccount %s/%s: %w", namespace, name, err)
+		}
+		return request.Status.Token, nil
+	}
+}
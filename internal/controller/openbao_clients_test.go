@@ -0,0 +1,200 @@
+// Тесты клиентов OpenBao tenant'ов: ключ кеша, ограничения openbaoRef и вход через TokenRequest.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/openbao"
+)
+
+// loginOpenBao — OpenBao, принимающий любой вход через auth/<mount>/login.
+type loginOpenBao struct {
+	mu     sync.Mutex
+	logins []map[string]string
+}
+
+func (b *loginOpenBao) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var body map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	body["path"] = r.URL.Path
+	b.logins = append(b.logins, body)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"auth": map[string]interface{}{
+			"client_token":   fmt.Sprintf("token-%d", len(b.logins)),
+			"lease_duration": 3600,
+		},
+	})
+}
+
+// tokenRequests — fake-клиент Kubernetes, выдающий JWT через TokenRequest и запоминающий запросы.
+type tokenRequests struct {
+	mu       sync.Mutex
+	requests []string
+	specs    []authenticationv1.TokenRequestSpec
+}
+
+func (r *tokenRequests) client(t *testing.T) client.Client {
+	return fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithInterceptorFuncs(interceptor.Funcs{
+		SubResourceCreate: func(_ context.Context, _ client.Client, subResource string, obj client.Object, sub client.Object, _ ...client.SubResourceCreateOption) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			request := sub.(*authenticationv1.TokenRequest)
+			name := obj.GetNamespace() + "/" + obj.GetName()
+			r.requests = append(r.requests, subResource+" "+name)
+			r.specs = append(r.specs, request.Spec)
+			request.Status.Token = "jwt-" + name
+			return nil
+		},
+	}).Build()
+}
+
+func TestOpenBaoClientsConfigFor(t *testing.T) {
+	clients := NewOpenBaoClients(nil, &openbao.Config{
+		Address:        "https://openbao.kubebao-system:8200/",
+		KubernetesAuth: &openbao.KubernetesAuthConfig{Role: "kubebao-operator"},
+	}, OpenBaoClientsOptions{AllowedAddresses: []string{"https://openbao.prod.example.com:8200"}}, nil)
+
+	base := &kubebaoiov1alpha1.OpenBaoReference{}
+	_, baseKey, err := clients.configFor("apps", base, "apps")
+	require.NoError(t, err)
+
+	// Тот же ref — тот же клиент, в том числе с явными значениями по умолчанию
+	_, key, err := clients.configFor("apps", nil, "apps")
+	require.NoError(t, err)
+	assert.Equal(t, baseKey, key)
+	_, key, err = clients.configFor("apps", &kubebaoiov1alpha1.OpenBaoReference{
+		AuthMethod:        "kubernetes",
+		AuthMountPath:     "kubernetes",
+		ServiceAccountRef: &kubebaoiov1alpha1.ServiceAccountReference{Name: "default", Namespace: "apps"},
+	}, "apps")
+	require.NoError(t, err)
+	assert.Equal(t, baseKey, key)
+
+	// Каждое поле ключа даёт отдельный клиент
+	distinct := map[string]struct {
+		namespace string
+		ref       *kubebaoiov1alpha1.OpenBaoReference
+		role      string
+	}{
+		"address":         {"apps", &kubebaoiov1alpha1.OpenBaoReference{Address: "https://openbao.prod.example.com:8200"}, "apps"},
+		"namespace":       {"apps", &kubebaoiov1alpha1.OpenBaoReference{Namespace: "prod"}, "apps"},
+		"mount":           {"apps", &kubebaoiov1alpha1.OpenBaoReference{AuthMethod: "jwt"}, "apps"},
+		"role":            {"apps", base, "apps-writer"},
+		"service account": {"apps", &kubebaoiov1alpha1.OpenBaoReference{ServiceAccountRef: &kubebaoiov1alpha1.ServiceAccountReference{Name: "reader"}}, "apps"},
+		"tenant":          {"billing", base, "apps"},
+	}
+	keys := map[string]string{baseKey: "base"}
+	for name, tt := range distinct {
+		cfg, key, err := clients.configFor(tt.namespace, tt.ref, tt.role)
+		require.NoError(t, err, name)
+		assert.Empty(t, cfg.Token, name)
+		if other, ok := keys[key]; ok {
+			t.Errorf("%s and %s share the cache key %q", name, other, key)
+		}
+		keys[key] = name
+	}
+
+	// Роль оператора — значение по умолчанию, токен оператора не переносится
+	cfg, _, err := clients.configFor("apps", base, "")
+	require.NoError(t, err)
+	assert.Equal(t, "kubebao-operator", cfg.KubernetesAuth.Role)
+}
+
+func TestOpenBaoClientsConfigForRejects(t *testing.T) {
+	clients := NewOpenBaoClients(nil, &openbao.Config{Address: "https://openbao.kubebao-system:8200"}, OpenBaoClientsOptions{}, nil)
+
+	tests := []struct {
+		name        string
+		ref         *kubebaoiov1alpha1.OpenBaoReference
+		role        string
+		wantErr     string
+		invalidSpec bool
+	}{
+		{name: "foreign address", ref: &kubebaoiov1alpha1.OpenBaoReference{Address: "https://attacker.example.com"},
+			role: "apps", wantErr: "address https://attacker.example.com is not allowed", invalidSpec: true},
+		{name: "service account in another namespace", ref: &kubebaoiov1alpha1.OpenBaoReference{
+			ServiceAccountRef: &kubebaoiov1alpha1.ServiceAccountReference{Name: "kubebao-operator", Namespace: "kubebao-system"},
+		}, role: "apps", wantErr: "serviceAccountRef must be in namespace apps"},
+		{name: "unsupported auth method", ref: &kubebaoiov1alpha1.OpenBaoReference{AuthMethod: "approle"},
+			role: "apps", wantErr: `unsupported authMethod "approle"`},
+		{name: "no role", ref: &kubebaoiov1alpha1.OpenBaoReference{}, wantErr: "roleName is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := clients.configFor("apps", tt.ref, tt.role)
+			require.ErrorContains(t, err, tt.wantErr)
+			var specErr *invalidSpecError
+			assert.Equal(t, tt.invalidSpec, errors.As(err, &specErr))
+		})
+	}
+}
+
+func TestOpenBaoClientsGet(t *testing.T) {
+	bao := &loginOpenBao{}
+	server := httptest.NewServer(http.HandlerFunc(bao.serveHTTP))
+	t.Cleanup(server.Close)
+
+	tokens := &tokenRequests{}
+	clients := NewOpenBaoClients(tokens.client(t), &openbao.Config{Address: server.URL, Token: "operator-token", MaxRetries: 1},
+		OpenBaoClientsOptions{Audience: "openbao.example.com"}, nil)
+	ctx := context.Background()
+	ref := &kubebaoiov1alpha1.OpenBaoReference{ServiceAccountRef: &kubebaoiov1alpha1.ServiceAccountReference{Name: "reader"}}
+
+	first, err := clients.Get(ctx, "apps", ref, "apps")
+	require.NoError(t, err)
+
+	// JWT выпущен для ServiceAccount tenant'а с audience OpenBao и коротким сроком
+	require.Equal(t, []string{"token apps/reader"}, tokens.requests)
+	assert.Equal(t, []string{"openbao.example.com"}, tokens.specs[0].Audiences)
+	require.NotNil(t, tokens.specs[0].ExpirationSeconds)
+	assert.Equal(t, int64(serviceAccountTokenTTL.Seconds()), *tokens.specs[0].ExpirationSeconds)
+	require.Len(t, bao.logins, 1)
+	assert.Equal(t, map[string]string{"path": "/v1/auth/kubernetes/login", "role": "apps", "jwt": "jwt-apps/reader"}, bao.logins[0])
+
+	// Повторный Get — клиент из кеша, без нового входа
+	again, err := clients.Get(ctx, "apps", ref, "apps")
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+	assert.Len(t, bao.logins, 1)
+
+	// Другой tenant с тем же ref входит под своим ServiceAccount
+	other, err := clients.Get(ctx, "billing", ref, "apps")
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, []string{"token apps/reader", "token billing/reader"}, tokens.requests)
+
+	// После Forget — новый вход
+	clients.Forget("apps", ref, "apps")
+	renewed, err := clients.Get(ctx, "apps", ref, "apps")
+	require.NoError(t, err)
+	assert.NotSame(t, first, renewed)
+	assert.Len(t, bao.logins, 3)
+
+	// Запрещённый адрес отклоняется до TokenRequest
+	_, err = clients.Get(ctx, "apps", &kubebaoiov1alpha1.OpenBaoReference{Address: "https://attacker.example.com"}, "apps")
+	require.Error(t, err)
+	assert.Equal(t, kubebaoiov1alpha1.ReasonInvalidSpec, failureReason(err))
+	assert.Len(t, tokens.requests, 3)
+}
@@ -271,6 +271,24 @@ func (c *AuthenticatedClient) WriteSecret(ctx context.Context, path string, data
 	return c.client.Logical().WriteWithContext(ctx, path, data)
 }
 
+// RenewLease renews a dynamic secret lease (implements openbao.LeaseClient)
+func (c *AuthenticatedClient) RenewLease(ctx context.Context, leaseID string, increment time.Duration) (*api.Secret, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("failed to refresh token", "error", err)
+	}
+
+	return c.client.Sys().RenewWithContext(ctx, leaseID, int(increment.Seconds()))
+}
+
+// RevokeLease revokes a dynamic secret lease (implements openbao.LeaseClient)
+func (c *AuthenticatedClient) RevokeLease(ctx context.Context, leaseID string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("failed to refresh token", "error", err)
+	}
+
+	return c.client.Sys().RevokeWithContext(ctx, leaseID)
+}
+
 // GetClient returns the underlying API client
 func (c *AuthenticatedClient) GetClient() *api.Client {
 	return c.client
@@ -135,7 +135,7 @@ func (p *Provider) Mount(ctx context.Context, req *pb.MountRequest) (*pb.MountRe
 	}
 
 	// Fetch secrets from OpenBao
-	fetchedSecrets, err := p.secretsFetcher.FetchSecrets(ctx, authClient, params.Objects)
+	fetchedSecrets, err := p.secretsFetcher.FetchSecrets(ctx, authClient, req.GetTargetPath(), params.Objects)
 	if err != nil {
 		p.logger.Error("Ошибка получения секретов из OpenBao", "error", err)
 		return &pb.MountResponse{
@@ -336,6 +336,11 @@ func (p *Provider) Run(ctx context.Context) error {
 
 	p.logger.Info("Запуск CSI провайдера", "socket", p.config.SocketPath)
 
+	// Продление аренд динамических секретов до остановки провайдера
+	leaseCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go p.secretsFetcher.Start(leaseCtx)
+
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
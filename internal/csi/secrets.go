@@ -7,18 +7,39 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/kubebao/kubebao/internal/openbao"
+	"github.com/openbao/openbao/api/v2"
 )
 
-// SecretsFetcher — получает секреты из OpenBao с кэшированием по CacheTTL
+const (
+	// mountCheckInterval — период проверки, что тома с динамическими секретами ещё смонтированы.
+	mountCheckInterval = time.Minute
+)
+
+// SecretsFetcher — получает секреты из OpenBao с кэшированием по CacheTTL. Динамические секреты
+// (secretArgs) не кэшируются между подами: выдаются на каждый том, аренды продлеваются leases.
 type SecretsFetcher struct {
 	config *Config
 	logger hclog.Logger
 	cache  *secretsCache
+	leases *openbao.LeaseManager
+
+	mu     sync.Mutex
+	issued map[string]*issuedSecret // Ключ — issuedKey(targetPath, obj)
+}
+
+// issuedSecret — динамический секрет, выданный для тома targetPath.
+type issuedSecret struct {
+	targetPath string
+	secret     *FetchedSecret
+	leaseID    string    // Пусто, если OpenBao не вернул аренду
+	expiresAt  time.Time // Для секретов без аренды — по CacheTTL
 }
 
 // secretsCache provides caching for fetched secrets
@@ -44,16 +65,42 @@ func NewSecretsFetcher(config *Config, logger hclog.Logger) (*SecretsFetcher, er
 		config: config,
 		logger: logger,
 		cache:  cache,
+		// Клиента у менеджера нет: аренды продлеваются и отзываются клиентом пода (TrackFor)
+		leases: openbao.NewLeaseManager(nil, logger.Named("leases")),
+		issued: make(map[string]*issuedSecret),
 	}, nil
 }
 
-// FetchSecrets fetches multiple secrets from OpenBao
-func (f *SecretsFetcher) FetchSecrets(ctx context.Context, client *AuthenticatedClient, objects []SecretObject) ([]*FetchedSecret, error) {
+// Start — продление аренд и отзыв аренд размонтированных томов до отмены ctx.
+func (f *SecretsFetcher) Start(ctx context.Context) {
+	go func() { _ = f.leases.Start(ctx) }()
+
+	ticker := time.NewTicker(mountCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.releaseUnmounted(ctx)
+		}
+	}
+}
+
+// FetchSecrets fetches multiple secrets from OpenBao for the volume at targetPath
+func (f *SecretsFetcher) FetchSecrets(ctx context.Context, client *AuthenticatedClient, targetPath string, objects []SecretObject) ([]*FetchedSecret, error) {
 	var secrets []*FetchedSecret
 	var fetchErrors []error
 
 	for _, obj := range objects {
-		secret, err := f.fetchSecret(ctx, client, obj)
+		var secret *FetchedSecret
+		var err error
+		if len(obj.SecretArgs) > 0 {
+			secret, err = f.fetchDynamicSecret(ctx, client, targetPath, obj)
+		} else {
+			secret, err = f.fetchSecret(ctx, client, obj)
+		}
 		if err != nil {
 			fetchErrors = append(fetchErrors, fmt.Errorf("failed to fetch %s: %w", obj.ObjectName, err))
 			continue
@@ -85,7 +132,7 @@ func (f *SecretsFetcher) fetchSecret(ctx context.Context, client *AuthenticatedC
 	f.logger.Debug("Получение секрета из OpenBao", "objectName", obj.ObjectName, "path", obj.SecretPath)
 
 	// Determine the secret engine type from path
-	secret, version, err := f.readFromOpenBao(ctx, client, obj)
+	secret, version, _, err := f.readFromOpenBao(ctx, client, obj)
 	if err != nil {
 		return nil, err
 	}
@@ -106,16 +153,104 @@ func (f *SecretsFetcher) fetchSecret(ctx context.Context, client *AuthenticatedC
 	return fetchedSecret, nil
 }
 
-// readFromOpenBao — читает секрет по path. Поддерживает KV v2 и динамические секреты (SecretArgs для write).
-func (f *SecretsFetcher) readFromOpenBao(ctx context.Context, client *AuthenticatedClient, obj SecretObject) ([]byte, string, error) {
+// fetchDynamicSecret — секрет с secretArgs для тома targetPath. Выданный ранее секрет переиспользуется,
+// пока его аренда на учёте и может продлеваться; иначе выдаётся новый, а старая аренда снимается
+// с учёта и истекает сама — под может ещё использовать старые учётные данные.
+func (f *SecretsFetcher) fetchDynamicSecret(ctx context.Context, client *AuthenticatedClient, targetPath string, obj SecretObject) (*FetchedSecret, error) {
+	key := issuedKey(targetPath, obj)
+
+	f.mu.Lock()
+	previous := f.issued[key]
+	f.mu.Unlock()
+
+	if previous != nil && f.isValid(previous) {
+		f.logger.Debug("Используется выданный динамический секрет", "objectName", obj.ObjectName, "leaseID", previous.leaseID)
+		return previous.secret, nil
+	}
+
+	content, version, resp, err := f.readFromOpenBao(ctx, client, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := &issuedSecret{
+		targetPath: targetPath,
+		secret: &FetchedSecret{
+			ObjectName: obj.ObjectName,
+			Content:    content,
+			Version:    version,
+			Mode:       parseFilePermission(obj.FilePermission),
+		},
+		expiresAt: time.Now().Add(f.config.CacheTTL),
+	}
+	if lease := f.leases.TrackFor(client, targetPath, obj.SecretPath, resp); lease != nil {
+		issued.leaseID = lease.ID
+	}
+
+	f.mu.Lock()
+	f.issued[key] = issued
+	f.mu.Unlock()
+
+	if previous != nil && previous.leaseID != "" && previous.leaseID != issued.leaseID {
+		f.leases.Untrack(previous.leaseID)
+	}
+
+	return issued.secret, nil
+}
+
+// isValid — выданный секрет можно отдать повторно.
+func (f *SecretsFetcher) isValid(issued *issuedSecret) bool {
+	if issued.leaseID == "" {
+		return time.Now().Before(issued.expiresAt)
+	}
+	_, tracked := f.leases.Get(issued.leaseID)
+	return tracked && !f.leases.IsExpiring(issued.leaseID)
+}
+
+// releaseUnmounted отзывает аренды томов, каталог которых удалён kubelet (под удалён). Если отозвать
+// не удалось (нет прав на sys/leases/revoke, токен пода истёк), аренды снимаются с учёта и истекают по TTL.
+func (f *SecretsFetcher) releaseUnmounted(ctx context.Context) {
+	f.mu.Lock()
+	unmounted := make(map[string]bool)
+	for key, issued := range f.issued {
+		if _, err := os.Stat(issued.targetPath); os.IsNotExist(err) {
+			unmounted[issued.targetPath] = true
+			delete(f.issued, key)
+		}
+	}
+	f.mu.Unlock()
+
+	for targetPath := range unmounted {
+		if err := f.leases.RevokeOwner(ctx, targetPath); err != nil {
+			f.logger.Warn("Не удалось отозвать аренды размонтированного тома, они истекут по TTL", "targetPath", targetPath, "error", err)
+			for _, lease := range f.leases.Leases(targetPath) {
+				f.leases.Untrack(lease.ID)
+			}
+			continue
+		}
+		f.logger.Info("Аренды размонтированного тома отозваны", "targetPath", targetPath)
+	}
+}
+
+// issuedKey — ключ выданного секрета: targetPath:objectName
+func issuedKey(targetPath string, obj SecretObject) string {
+	return fmt.Sprintf("%s:%s", targetPath, obj.ObjectName)
+}
+
+// readFromOpenBao — читает секрет по path. Поддерживает KV v2 и динамические секреты (SecretArgs для write);
+// для динамических секретов возвращает и ответ OpenBao с арендой.
+func (f *SecretsFetcher) readFromOpenBao(ctx context.Context, client *AuthenticatedClient, obj SecretObject) ([]byte, string, *api.Secret, error) {
 	path := obj.SecretPath
 
-	// Handle KV v2 paths
+	// Handle KV v2 paths; with SecretArgs the path is the full engine path (database/creds/<role>)
 	// If path doesn't start with a mount prefix, assume it's under "secret/" mount
-	if !strings.HasPrefix(path, "secret/") && !strings.HasPrefix(path, "kv/") {
+	switch {
+	case len(obj.SecretArgs) > 0:
+		// Dynamic secret — path is used as is
+	case !strings.HasPrefix(path, "secret/") && !strings.HasPrefix(path, "kv/"):
 		// Add default mount and data path
 		path = fmt.Sprintf("secret/data/%s", path)
-	} else if !strings.Contains(path, "/data/") {
+	case !strings.Contains(path, "/data/"):
 		// Path has mount prefix but missing /data/
 		parts := strings.SplitN(path, "/", 2)
 		if len(parts) == 2 {
@@ -126,6 +261,7 @@ func (f *SecretsFetcher) readFromOpenBao(ctx context.Context, client *Authentica
 	// Read the secret
 	var secret interface{}
 	var version string
+	var leased *api.Secret
 
 	if len(obj.SecretArgs) > 0 {
 		// Write request for dynamic secrets (database, pki, etc.)
@@ -136,24 +272,25 @@ func (f *SecretsFetcher) readFromOpenBao(ctx context.Context, client *Authentica
 
 		resp, err := client.WriteSecret(ctx, path, data)
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to write to path: %w", err)
+			return nil, "", nil, fmt.Errorf("failed to write to path: %w", err)
 		}
 
 		if resp == nil || resp.Data == nil {
-			return nil, "", fmt.Errorf("no data returned from path: %s", path)
+			return nil, "", nil, fmt.Errorf("no data returned from path: %s", path)
 		}
 
 		secret = resp.Data
+		leased = resp
 		version = resp.RequestID[:8] // Use request ID as version for dynamic secrets
 	} else {
 		// Read request for static secrets
 		resp, err := client.ReadSecret(ctx, path)
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to read path: %w", err)
+			return nil, "", nil, fmt.Errorf("failed to read path: %w", err)
 		}
 
 		if resp == nil || resp.Data == nil {
-			return nil, "", fmt.Errorf("no data found at path: %s", path)
+			return nil, "", nil, fmt.Errorf("no data found at path: %s", path)
 		}
 
 		// For KV v2, data is nested under "data"
@@ -177,10 +314,10 @@ func (f *SecretsFetcher) readFromOpenBao(ctx context.Context, client *Authentica
 	// Extract specific key if requested
 	content, err := f.extractContent(secret, obj)
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
 	}
 
-	return content, version, nil
+	return content, version, leased, nil
 }
 
 // extractContent — извлекает SecretKey или весь JSON, применяет encoding (base64/text).
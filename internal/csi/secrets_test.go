@@ -0,0 +1,138 @@
+// Тесты динамических секретов CSI: выдача на том, переиспользование по аренде и отзыв после размонтирования.
+package csi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leasingOpenBao — OpenBao, выдающий учётные данные с арендой на database/creds и отзывающий аренды.
+type leasingOpenBao struct {
+	mu      sync.Mutex
+	issued  int
+	revoked []string
+}
+
+func (b *leasingOpenBao) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v1/sys/leases/revoke":
+		var body struct {
+			LeaseID string `json:"lease_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		b.revoked = append(b.revoked, body.LeaseID)
+		w.WriteHeader(http.StatusNoContent)
+	case "/v1/database/creds/app":
+		b.issued++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"request_id":     fmt.Sprintf("request-%d", b.issued),
+			"lease_id":       fmt.Sprintf("database/creds/app/%d", b.issued),
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]interface{}{"username": fmt.Sprintf("user-%d", b.issued)},
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestFetcher(t *testing.T) (*SecretsFetcher, *AuthenticatedClient, *leasingOpenBao) {
+	t.Helper()
+
+	bao := &leasingOpenBao{}
+	server := httptest.NewServer(http.HandlerFunc(bao.serveHTTP))
+	t.Cleanup(server.Close)
+
+	t.Setenv("OPENBAO_TOKEN", "test-token")
+	client, err := NewAuthenticatedClient(context.Background(), &AuthConfig{
+		OpenBaoAddress: server.URL,
+		AuthMethod:     "token",
+	}, hclog.NewNullLogger())
+	require.NoError(t, err)
+
+	fetcher, err := NewSecretsFetcher(&Config{CacheTTL: time.Minute}, hclog.NewNullLogger())
+	require.NoError(t, err)
+	return fetcher, client, bao
+}
+
+func TestFetchDynamicSecretPerVolume(t *testing.T) {
+	fetcher, client, bao := newTestFetcher(t)
+	ctx := context.Background()
+	obj := SecretObject{
+		ObjectName: "username",
+		SecretPath: "database/creds/app",
+		SecretKey:  "username",
+		SecretArgs: map[string]string{"ttl": "1h"},
+	}
+
+	podA := filepath.Join(t.TempDir(), "pod-a")
+	podB := filepath.Join(t.TempDir(), "pod-b")
+	require.NoError(t, os.Mkdir(podA, 0o755))
+	require.NoError(t, os.Mkdir(podB, 0o755))
+
+	first, err := fetcher.FetchSecrets(ctx, client, podA, []SecretObject{obj})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", string(first[0].Content))
+
+	// Повторный Mount того же тома — та же аренда, новые учётные данные не выдаются
+	again, err := fetcher.FetchSecrets(ctx, client, podA, []SecretObject{obj})
+	require.NoError(t, err)
+	assert.Equal(t, first[0], again[0])
+
+	// Другой под получает свои учётные данные
+	other, err := fetcher.FetchSecrets(ctx, client, podB, []SecretObject{obj})
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", string(other[0].Content))
+	assert.Len(t, fetcher.leases.Leases(podA), 1)
+	assert.Len(t, fetcher.leases.Leases(podB), 1)
+
+	// Под A удалён — его аренда отзывается, аренда пода B остаётся
+	require.NoError(t, os.Remove(podA))
+	fetcher.releaseUnmounted(ctx)
+	assert.Equal(t, []string{"database/creds/app/1"}, bao.revoked)
+	assert.Empty(t, fetcher.leases.Leases(podA))
+	assert.Len(t, fetcher.leases.Leases(podB), 1)
+}
+
+func TestFetchDynamicSecretReissuedAfterLeaseEnds(t *testing.T) {
+	fetcher, client, _ := newTestFetcher(t)
+	ctx := context.Background()
+	obj := SecretObject{
+		ObjectName: "username",
+		SecretPath: "database/creds/app",
+		SecretKey:  "username",
+		SecretArgs: map[string]string{"ttl": "1h"},
+	}
+	targetPath := t.TempDir()
+
+	first, err := fetcher.FetchSecrets(ctx, client, targetPath, []SecretObject{obj})
+	require.NoError(t, err)
+
+	// Истёкшая аренда снимается менеджером с учёта — следующий Mount выдаёт новый секрет
+	fetcher.leases.Untrack("database/creds/app/1")
+
+	second, err := fetcher.FetchSecrets(ctx, client, targetPath, []SecretObject{obj})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", string(first[0].Content))
+	assert.Equal(t, "user-2", string(second[0].Content))
+
+	leases := fetcher.leases.Leases(targetPath)
+	require.Len(t, leases, 1)
+	assert.Equal(t, "database/creds/app/2", leases[0].ID)
+}
@@ -99,6 +99,12 @@ func (km *KeyManager) GetOrCreateKey(ctx context.Context) ([]byte, int, error) {
 		return keyCopy, version, nil
 	}
 
+	// Генерировать новый ключ допустимо только при подтверждённом отсутствии записи (404).
+	// При 403/503/сетевой ошибке новый ключ сделал бы нечитаемыми все ранее зашифрованные DEK.
+	if !openbao.IsNotFound(err) {
+		return nil, 0, fmt.Errorf("read key from OpenBao: %w", err)
+	}
+
 	// Записи нет: либо создаём новый ключ (crypto/rand), либо возвращаем ошибку политики.
 	if !km.createIfNotExists {
 		return nil, 0, fmt.Errorf("key not found and createKeyIfNotExists is false")
@@ -162,10 +168,14 @@ func (km *KeyManager) parseKeyData(data map[string]interface{}) ([]byte, int, er
 }
 
 // GetKeyInfo читает KV напрямую (без обновления in-memory кеша) — для health и отображения версии.
+// Exists=false возвращается только при 404; прочие ошибки OpenBao пробрасываются вызывающему.
 func (km *KeyManager) GetKeyInfo(ctx context.Context) (*KeyInfo, error) {
 	data, err := km.client.KVRead(ctx, km.kvPath)
 	if err != nil {
-		return &KeyInfo{Exists: false}, nil
+		if openbao.IsNotFound(err) {
+			return &KeyInfo{Exists: false}, nil
+		}
+		return nil, fmt.Errorf("read key info: %w", err)
 	}
 
 	_, version, err := km.parseKeyData(data)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/kubebao/kubebao/internal/crypto"
+	"github.com/kubebao/kubebao/internal/openbao"
 )
 
 // KuznyechikProvider — AEAD на базе ГОСТ Р 34.12-2015 (блок 128 бит) и режима из ГОСТ Р 34.13-2015;
@@ -101,7 +102,7 @@ func (p *KuznyechikProvider) GetKeyInfo(ctx context.Context, keyName string) (*T
 	}
 
 	if !info.Exists {
-		return nil, fmt.Errorf("key not found: %s", keyName)
+		return nil, fmt.Errorf("key not found: %s: %w", keyName, openbao.ErrNotFound)
 	}
 
 	return &TransitKeyInfo{
@@ -125,6 +125,16 @@ func (s *Server) initialize(ctx context.Context) error {
 	s.logger.Info("Инициализация KMS сервера", "keyName", s.config.KeyName, "provider", s.config.EncryptionProvider)
 
 	keyInfo, err := s.provider.GetKeyInfo(ctx, s.config.KeyName)
+	if err != nil && !openbao.IsNotFound(err) {
+		// Временная недоступность OpenBao не означает отсутствие ключа: создавать ключ нельзя.
+		// Стартуем в состоянии unhealthy — healthCheckLoop выставит keyID после восстановления.
+		if openbao.IsRetryable(err) {
+			s.logger.Warn("OpenBao временно недоступен при инициализации, сервер запущен в состоянии unhealthy", "error", err)
+			return nil
+		}
+		return fmt.Errorf("failed to get key info: %w", err)
+	}
+
 	if err != nil {
 		if s.config.EncryptionProvider == ProviderKuznyechik {
 			// Для Kuznyechik запись в KV появляется при первом Encrypt — GetKeyInfo до этого пустой.
@@ -361,8 +371,8 @@ func (s *Server) performHealthCheck(ctx context.Context) {
 	defer s.mu.Unlock()
 
 	if err != nil {
-		// Пока ключа нет в KV, GetKeyInfo даёт ошибку — для уже «зелёного» Kuznyechik не деградируем.
-		if s.config.EncryptionProvider == ProviderKuznyechik && s.healthy {
+		// Пока ключа нет в KV, GetKeyInfo даёт ErrNotFound — для уже «зелёного» Kuznyechik не деградируем.
+		if openbao.IsNotFound(err) && s.config.EncryptionProvider == ProviderKuznyechik && s.healthy {
 			return
 		}
 		s.logger.Warn("Проверка здоровья не пройдена", "error", err)
@@ -19,6 +19,9 @@ type TransitKeyInfo struct {
 	Exportable    bool
 }
 
+// rewrapBatchSize — максимальный размер batch_input для одного запроса rewrap.
+const rewrapBatchSize = 256
+
 // TransitClient держит общий openbao.Client и использует его методы Transit* для KMS-операций.
 type TransitClient struct {
 	client *openbao.Client
@@ -94,27 +97,52 @@ func (t *TransitClient) CreateKey(ctx context.Context, keyName string, keyType s
 
 // RotateKey выполняет POST rotate: новая версия ключа, старые ciphertext остаются читаемыми.
 func (t *TransitClient) RotateKey(ctx context.Context, keyName string) error {
-	path := fmt.Sprintf("transit/keys/%s/rotate", keyName)
-	_, err := t.client.WriteSecret(ctx, path, nil)
-	if err != nil {
+	if err := t.client.TransitRotateKey(ctx, keyName); err != nil {
 		return fmt.Errorf("failed to rotate key: %w", err)
 	}
 
-	t.logger.Info("Transit ключ повёрнут", "keyName", keyName)
 	return nil
 }
 
-// UpdateKeyConfig пишет произвольные параметры ключа (min_decryption_version, deletion_allowed и т.д.).
-func (t *TransitClient) UpdateKeyConfig(ctx context.Context, keyName string, config map[string]interface{}) error {
-	path := fmt.Sprintf("transit/keys/%s/config", keyName)
-	_, err := t.client.WriteSecret(ctx, path, config)
-	if err != nil {
+// UpdateKeyConfig применяет параметры ключа (min_decryption_version, deletion_allowed, auto_rotate_period и т.д.).
+func (t *TransitClient) UpdateKeyConfig(ctx context.Context, keyName string, config *openbao.TransitKeyConfig) error {
+	if err := t.client.TransitUpdateKeyConfig(ctx, keyName, config); err != nil {
 		return fmt.Errorf("failed to update key config: %w", err)
 	}
 
 	return nil
 }
 
+// Rewrap перешифровывает набор ciphertext последней версией ключа пакетами по rewrapBatchSize.
+// Используется после ротации, чтобы затем поднять min_decryption_version без потери данных.
+// Возвращает ciphertext в исходном порядке; первая ошибка элемента прерывает операцию.
+func (t *TransitClient) Rewrap(ctx context.Context, keyName string, ciphertexts []string) ([]string, error) {
+	start := time.Now()
+	result := make([]string, 0, len(ciphertexts))
+
+	for offset := 0; offset < len(ciphertexts); offset += rewrapBatchSize {
+		end := offset + rewrapBatchSize
+		if end > len(ciphertexts) {
+			end = len(ciphertexts)
+		}
+
+		batch, err := t.client.TransitBatchRewrap(ctx, keyName, ciphertexts[offset:end])
+		if err != nil {
+			return nil, fmt.Errorf("transit rewrap failed: %w", err)
+		}
+
+		for i, item := range batch {
+			if item.Error != "" {
+				return nil, fmt.Errorf("transit rewrap failed for item %d: %s", offset+i, item.Error)
+			}
+			result = append(result, item.Ciphertext)
+		}
+	}
+
+	t.logger.Info("Transit rewrap завершён", "keyName", keyName, "count", len(result), "duration", time.Since(start))
+	return result, nil
+}
+
 // Health делегирует в общий health OpenBao (доступность API), не привязан строго к одному ключу.
 func (t *TransitClient) Health(ctx context.Context) error {
 	_, err := t.client.Health(ctx)
@@ -0,0 +1,68 @@
+// Роли методов аутентификации OpenBao (auth/<mount>/role/<name>): kubernetes и jwt.
+package openbao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+// authRolePath — путь роли метода аутентификации.
+func authRolePath(mount, name string) string {
+	return "auth/" + strings.Trim(mount, "/") + "/role/" + name
+}
+
+// ReadAuthRole — параметры роли метода аутентификации. Отсутствующая роль — ошибка IsNotFound.
+func (c *Client) ReadAuthRole(ctx context.Context, mount, name string) (map[string]interface{}, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при ReadAuthRole", "error", err)
+	}
+
+	path := authRolePath(mount, name)
+	secret, err := c.retry(ctx, "ReadAuthRole", path, func() (*api.Secret, error) {
+		return c.client.Logical().ReadWithContext(ctx, path)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth role: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("auth role not found: %s: %w", name, newNotFoundError("ReadAuthRole", path))
+	}
+	return secret.Data, nil
+}
+
+// WriteAuthRole — создание или замена роли метода аутентификации.
+func (c *Client) WriteAuthRole(ctx context.Context, mount, name string, data map[string]interface{}) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при WriteAuthRole", "error", err)
+	}
+
+	path := authRolePath(mount, name)
+	c.logger.Debug("WriteAuthRole", "path", path)
+	_, err := c.retry(ctx, "WriteAuthRole", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write auth role: %w", err)
+	}
+	return nil
+}
+
+// DeleteAuthRole — удаление роли метода аутентификации; отсутствующая роль считается удалённой.
+func (c *Client) DeleteAuthRole(ctx context.Context, mount, name string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при DeleteAuthRole", "error", err)
+	}
+
+	path := authRolePath(mount, name)
+	c.logger.Debug("DeleteAuthRole", "path", path)
+	_, err := c.retry(ctx, "DeleteAuthRole", path, func() (*api.Secret, error) {
+		return c.client.Logical().DeleteWithContext(ctx, path)
+	})
+	if err != nil && !IsNotFound(err) {
+		return fmt.Errorf("failed to delete auth role: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Тесты ролей методов аутентификации OpenBao.
+package openbao
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRoleLifecycle(t *testing.T) {
+	roles := map[string]map[string]interface{}{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			role, ok := roles[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				writeJSON(t, w, map[string]interface{}{"errors": []string{}})
+				return
+			}
+			writeJSON(t, w, map[string]interface{}{"data": role})
+		case http.MethodPut, http.MethodPost:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			roles[r.URL.Path] = body
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			delete(roles, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	ctx := context.Background()
+
+	_, err := client.ReadAuthRole(ctx, "kubernetes", "app")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+
+	require.NoError(t, client.WriteAuthRole(ctx, "/kubernetes/", "app", map[string]interface{}{
+		"bound_service_account_names": []string{"app"},
+		"token_policies":              []string{"default-app"},
+	}))
+	assert.Contains(t, roles, "/v1/auth/kubernetes/role/app")
+
+	role, err := client.ReadAuthRole(ctx, "kubernetes", "app")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"default-app"}, role["token_policies"])
+
+	require.NoError(t, client.DeleteAuthRole(ctx, "kubernetes", "app"))
+	_, err = client.ReadAuthRole(ctx, "kubernetes", "app")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestDeleteAuthRoleNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/v1/auth/jwt/role/missing", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(t, w, map[string]interface{}{"errors": []string{}})
+	})
+
+	assert.NoError(t, client.DeleteAuthRole(context.Background(), "jwt", "missing"))
+}
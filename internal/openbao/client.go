@@ -6,7 +6,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
@@ -17,6 +19,10 @@ import (
 type Config struct {
 	Address string `yaml:"address"` // URL сервера OpenBao (например http://openbao:8200)
 
+	Addresses []string `yaml:"addresses,omitempty"` // Узлы HA-кластера; дополняют Address, порядок задаёт приоритет
+
+	NodeCheckInterval time.Duration `yaml:"nodeCheckInterval"` // Период перепроверки узлов (<0 — только при ошибках)
+
 	Token string `yaml:"token"` // Root/статический токен (если не используется Kubernetes auth)
 
 	TLSConfig *TLSConfig `yaml:"tls,omitempty"` // Сертификаты, CA, небезопасный режим
@@ -29,7 +35,11 @@ type Config struct {
 
 	Namespace string `yaml:"namespace,omitempty"` // Namespace OpenBao (Enterprise)
 
-	MaxRetries int `yaml:"maxRetries"` // Повторы при сетевых ошибках
+	MaxRetries int `yaml:"maxRetries"` // Повторы при временных ошибках (сеть, 412, 429, 502–504, sealed)
+
+	RetryWaitMin time.Duration `yaml:"retryWaitMin"` // Задержка перед первым повтором (далее экспоненциально)
+
+	RetryWaitMax time.Duration `yaml:"retryWaitMax"` // Верхняя граница задержки между повторами
 
 	Timeout time.Duration `yaml:"timeout"` // Таймаут HTTP-запросов
 }
@@ -49,6 +59,10 @@ type KubernetesAuthConfig struct {
 	Role      string `yaml:"role"`      // Роль OpenBao для входа
 	MountPath string `yaml:"mountPath"`  // Путь auth (по умолчанию "kubernetes")
 	TokenPath string `yaml:"tokenPath"`  // Путь к файлу JWT (обычно /var/run/secrets/.../token)
+
+	// JWTProvider выдаёт JWT для входа вместо чтения TokenPath (например, TokenRequest
+	// для чужого ServiceAccount). Вызывается при каждой повторной аутентификации.
+	JWTProvider func(ctx context.Context) (string, error) `yaml:"-"`
 }
 
 // Client — обёртка над api.Client с автоматическим обновлением токена и методами KV/Transit.
@@ -58,6 +72,13 @@ type Client struct {
 	logger     hclog.Logger
 	mu         sync.RWMutex
 	tokenExpiry time.Time
+
+	addresses        []string          // Узлы кластера (Config.AllAddresses)
+	index            *consistencyIndex // Последний X-Vault-Index для read-after-write
+	failoverMu       sync.Mutex        // Сериализует выбор узла
+	nodeMu           sync.Mutex        // Защита lastNodeCheck
+	lastNodeCheck    time.Time
+	nodeCheckRunning atomic.Bool
 }
 
 // NewClient — создаёт клиент, подключается к OpenBao и выполняет аутентификацию.
@@ -66,7 +87,8 @@ func NewClient(cfg *Config, logger hclog.Logger) (*Client, error) {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
 
-	if cfg.Address == "" {
+	addresses := cfg.AllAddresses()
+	if len(addresses) == 0 {
 		return nil, fmt.Errorf("OpenBao address is required")
 	}
 
@@ -83,11 +105,22 @@ func NewClient(cfg *Config, logger hclog.Logger) (*Client, error) {
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 30 * time.Second
 	}
+	if cfg.RetryWaitMin == 0 {
+		cfg.RetryWaitMin = DefaultRetryWaitMin
+	}
+	if cfg.RetryWaitMax == 0 {
+		cfg.RetryWaitMax = DefaultRetryWaitMax
+	}
+	if cfg.NodeCheckInterval == 0 {
+		cfg.NodeCheckInterval = DefaultNodeCheckInterval
+	}
 
 	// Create API config
 	apiConfig := api.DefaultConfig()
-	apiConfig.Address = cfg.Address
-	apiConfig.MaxRetries = cfg.MaxRetries
+	apiConfig.Address = addresses[0]
+	// Повторы выполняет Client.retry с классификацией ошибок — встроенные повторы retryablehttp отключены,
+	// иначе 4xx/5xx повторялись бы дважды и без учёта типа ошибки.
+	apiConfig.MaxRetries = 0
 	apiConfig.Timeout = cfg.Timeout
 
 	// Configure TLS if provided
@@ -116,10 +149,26 @@ func NewClient(cfg *Config, logger hclog.Logger) (*Client, error) {
 		client.SetNamespace(cfg.Namespace)
 	}
 
+	// Standby-узлы перенаправляют запросы на активный (307, обрабатывается api-клиентом);
+	// X-Vault-Index гарантирует, что чтение после записи не вернёт устаревшие данные.
+	index := &consistencyIndex{}
+	client = client.WithRequestCallbacks(index.require).WithResponseCallbacks(index.record)
+
 	c := &Client{
-		client: client,
-		config: cfg,
-		logger: logger,
+		client:    client,
+		config:    cfg,
+		logger:    logger,
+		addresses: addresses,
+		index:     index,
+	}
+
+	if len(addresses) > 1 {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		err := c.selectNode(ctx)
+		cancel()
+		if err != nil {
+			c.logger.Warn("Активный узел OpenBao не найден, используется первый адрес", "address", addresses[0], "error", err)
+		}
 	}
 
 	// Authenticate
@@ -174,9 +223,19 @@ func (c *Client) authenticateKubernetes() error {
 	}
 
 	// Read the service account token
-	jwt, err := os.ReadFile(tokenPath)
-	if err != nil {
-		return fmt.Errorf("failed to read service account token: %w", err)
+	var jwt []byte
+	if k8sAuth.JWTProvider != nil {
+		token, err := k8sAuth.JWTProvider(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to obtain service account token: %w", err)
+		}
+		jwt = []byte(token)
+	} else {
+		token, err := os.ReadFile(tokenPath)
+		if err != nil {
+			return fmt.Errorf("failed to read service account token: %w", err)
+		}
+		jwt = token
 	}
 
 	// Login with Kubernetes auth
@@ -186,7 +245,9 @@ func (c *Client) authenticateKubernetes() error {
 		"jwt":  string(jwt),
 	}
 
-	secret, err := c.client.Logical().Write(loginPath, loginData)
+	secret, err := c.retryUnprocessed(context.Background(), "KubernetesLogin", loginPath, func() (*api.Secret, error) {
+		return c.client.Logical().Write(loginPath, loginData)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to login with Kubernetes auth: %w", err)
 	}
@@ -252,7 +313,9 @@ func (c *Client) TransitEncrypt(ctx context.Context, keyName string, plaintext [
 		"plaintext": base64.StdEncoding.EncodeToString(plaintext),
 	}
 
-	secret, err := c.client.Logical().WriteWithContext(ctx, path, data)
+	secret, err := c.retry(ctx, "TransitEncrypt", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to encrypt data: %w", err)
 	}
@@ -282,7 +345,9 @@ func (c *Client) TransitDecrypt(ctx context.Context, keyName string, ciphertext
 		"ciphertext": ciphertext,
 	}
 
-	secret, err := c.client.Logical().WriteWithContext(ctx, path, data)
+	secret, err := c.retry(ctx, "TransitDecrypt", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt data: %w", err)
 	}
@@ -304,7 +369,7 @@ func (c *Client) TransitDecrypt(ctx context.Context, keyName string, ciphertext
 	return plaintext, nil
 }
 
-// TransitGetKeyInfo — читает transit/keys/{keyName}, возвращает latest_version, type.
+// TransitGetKeyInfo — читает transit/keys/{keyName}: версии ключа, тип и параметры конфигурации.
 func (c *Client) TransitGetKeyInfo(ctx context.Context, keyName string) (*TransitKeyInfo, error) {
 	if err := c.RefreshToken(ctx); err != nil {
 		c.logger.Warn("Не удалось обновить токен при GetKeyInfo", "error", err)
@@ -313,22 +378,26 @@ func (c *Client) TransitGetKeyInfo(ctx context.Context, keyName string) (*Transi
 	path := fmt.Sprintf("%s/keys/%s", c.config.TransitMount, keyName)
 	c.logger.Debug("Transit GetKeyInfo", "path", path)
 
-	secret, err := c.client.Logical().ReadWithContext(ctx, path)
+	secret, err := c.retry(ctx, "TransitGetKeyInfo", path, func() (*api.Secret, error) {
+		return c.client.Logical().ReadWithContext(ctx, path)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to read key info: %w", err)
 	}
 
 	if secret == nil || secret.Data == nil {
-		return nil, fmt.Errorf("key not found: %s", keyName)
+		return nil, fmt.Errorf("key not found: %s: %w", keyName, newNotFoundError("TransitGetKeyInfo", path))
 	}
 
 	info := &TransitKeyInfo{
 		Name: keyName,
 	}
 
-	if latestVersion, ok := secret.Data["latest_version"].(float64); ok {
-		info.LatestVersion = int(latestVersion)
-	}
+	// Числа приходят как json.Number (api.ParseSecret использует UseNumber)
+	info.LatestVersion = intFromData(secret.Data["latest_version"])
+	info.MinDecryptionVersion = intFromData(secret.Data["min_decryption_version"])
+	info.MinEncryptionVersion = intFromData(secret.Data["min_encryption_version"])
+	info.AutoRotatePeriod = durationFromData(secret.Data["auto_rotate_period"])
 
 	if keyType, ok := secret.Data["type"].(string); ok {
 		info.Type = keyType
@@ -338,18 +407,26 @@ func (c *Client) TransitGetKeyInfo(ctx context.Context, keyName string) (*Transi
 		info.Exportable = exportable
 	}
 
+	if deletionAllowed, ok := secret.Data["deletion_allowed"].(bool); ok {
+		info.DeletionAllowed = deletionAllowed
+	}
+
 	return info, nil
 }
 
 // TransitKeyInfo holds information about a transit key
 type TransitKeyInfo struct {
-	Name          string
-	LatestVersion int
-	Type          string
-	Exportable    bool
+	Name                 string
+	LatestVersion        int
+	MinDecryptionVersion int
+	MinEncryptionVersion int
+	Type                 string
+	Exportable           bool
+	DeletionAllowed      bool
+	AutoRotatePeriod     time.Duration
 }
 
-// TransitCreateKey creates a new transit encryption key
+// TransitCreateKey creates a new transit encryption key (не повторяется после 502/504 и обрыва)
 func (c *Client) TransitCreateKey(ctx context.Context, keyName string, keyType string) error {
 	if err := c.RefreshToken(ctx); err != nil {
 		c.logger.Warn("Не удалось обновить токен при создании ключа", "error", err)
@@ -362,7 +439,9 @@ func (c *Client) TransitCreateKey(ctx context.Context, keyName string, keyType s
 		data["type"] = keyType
 	}
 
-	_, err := c.client.Logical().WriteWithContext(ctx, path, data)
+	_, err := c.retryUnprocessed(ctx, "TransitCreateKey", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create transit key: %w", err)
 	}
@@ -373,28 +452,42 @@ func (c *Client) TransitCreateKey(ctx context.Context, keyName string, keyType s
 
 // KVRead — читает секрет из KV v2 по пути {kvMount}/data/{path}, возвращает data (без metadata).
 func (c *Client) KVRead(ctx context.Context, path string) (map[string]interface{}, error) {
+	data, _, err := c.KVReadWithMetadata(ctx, path)
+	return data, err
+}
+
+// KVReadWithMetadata читает секрет KV v2 вместе с метаданными версии
+// (version, created_time, custom_metadata) из того же ответа.
+func (c *Client) KVReadWithMetadata(ctx context.Context, path string) (map[string]interface{}, map[string]interface{}, error) {
 	if err := c.RefreshToken(ctx); err != nil {
 		c.logger.Warn("Не удалось обновить токен при KVRead", "error", err)
 	}
 
 	fullPath := fmt.Sprintf("%s/data/%s", c.config.KVMount, path)
 	c.logger.Debug("KVRead", "path", fullPath)
-	secret, err := c.client.Logical().ReadWithContext(ctx, fullPath)
+	secret, err := c.retry(ctx, "KVRead", fullPath, func() (*api.Secret, error) {
+		return c.client.Logical().ReadWithContext(ctx, fullPath)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read secret: %w", err)
+		return nil, nil, fmt.Errorf("failed to read secret: %w", err)
 	}
 
 	if secret == nil || secret.Data == nil {
-		return nil, fmt.Errorf("secret not found: %s", path)
+		return nil, nil, fmt.Errorf("secret not found: %s: %w", path, newNotFoundError("KVRead", fullPath))
 	}
 
 	// KV v2 returns data nested under "data" key
 	data, ok := secret.Data["data"].(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid secret format")
+		return nil, nil, fmt.Errorf("invalid secret format")
 	}
 
-	return data, nil
+	metadata, _ := secret.Data["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	return data, metadata, nil
 }
 
 // KVReadWithVersion reads a specific version of a secret from the KV secrets engine (v2)
@@ -403,15 +496,18 @@ func (c *Client) KVReadWithVersion(ctx context.Context, path string, version int
 		c.logger.Warn("failed to refresh token", "error", err)
 	}
 
-	fullPath := fmt.Sprintf("%s/data/%s?version=%d", c.config.KVMount, path, version)
+	fullPath := fmt.Sprintf("%s/data/%s", c.config.KVMount, path)
+	params := map[string][]string{"version": {strconv.Itoa(version)}}
 
-	secret, err := c.client.Logical().ReadWithContext(ctx, fullPath)
+	secret, err := c.retry(ctx, "KVReadWithVersion", fullPath, func() (*api.Secret, error) {
+		return c.client.Logical().ReadWithDataWithContext(ctx, fullPath, params)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to read secret: %w", err)
 	}
 
 	if secret == nil || secret.Data == nil {
-		return nil, fmt.Errorf("secret not found: %s", path)
+		return nil, fmt.Errorf("secret not found: %s: %w", path, newNotFoundError("KVReadWithVersion", fullPath))
 	}
 
 	data, ok := secret.Data["data"].(map[string]interface{})
@@ -422,7 +518,54 @@ func (c *Client) KVReadWithVersion(ctx context.Context, path string, version int
 	return data, nil
 }
 
-// KVWrite — записывает секрет в KV v2 по пути {kvMount}/data/{path}.
+// KVMetadata — метаданные секрета KV v2 без его содержимого.
+type KVMetadata struct {
+	CurrentVersion int
+	CreatedTime    time.Time
+	UpdatedTime    time.Time
+	CustomMetadata map[string]string
+}
+
+// KVReadMetadata — читает {kvMount}/metadata/{path}: текущую версию и custom_metadata.
+func (c *Client) KVReadMetadata(ctx context.Context, path string) (*KVMetadata, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при KVReadMetadata", "error", err)
+	}
+
+	fullPath := fmt.Sprintf("%s/metadata/%s", c.config.KVMount, path)
+	c.logger.Debug("KVReadMetadata", "path", fullPath)
+	secret, err := c.retry(ctx, "KVReadMetadata", fullPath, func() (*api.Secret, error) {
+		return c.client.Logical().ReadWithContext(ctx, fullPath)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret metadata: %w", err)
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("secret not found: %s: %w", path, newNotFoundError("KVReadMetadata", fullPath))
+	}
+
+	meta := &KVMetadata{
+		CurrentVersion: intFromData(secret.Data["current_version"]),
+		CustomMetadata: make(map[string]string),
+	}
+	if created, ok := secret.Data["created_time"].(string); ok {
+		meta.CreatedTime, _ = time.Parse(time.RFC3339Nano, created)
+	}
+	if updated, ok := secret.Data["updated_time"].(string); ok {
+		meta.UpdatedTime, _ = time.Parse(time.RFC3339Nano, updated)
+	}
+	if custom, ok := secret.Data["custom_metadata"].(map[string]interface{}); ok {
+		for k, v := range custom {
+			meta.CustomMetadata[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	return meta, nil
+}
+
+// KVWrite — записывает секрет в KV v2 по пути {kvMount}/data/{path}. Каждая запись создаёт
+// новую версию, поэтому запрос повторяется, только если не выполнен.
 func (c *Client) KVWrite(ctx context.Context, path string, data map[string]interface{}) error {
 	if err := c.RefreshToken(ctx); err != nil {
 		c.logger.Warn("Не удалось обновить токен при KVWrite", "error", err)
@@ -434,7 +577,9 @@ func (c *Client) KVWrite(ctx context.Context, path string, data map[string]inter
 		"data": data,
 	}
 
-	_, err := c.client.Logical().WriteWithContext(ctx, fullPath, writeData)
+	_, err := c.retryUnprocessed(ctx, "KVWrite", fullPath, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, fullPath, writeData)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to write secret: %w", err)
 	}
@@ -442,13 +587,85 @@ func (c *Client) KVWrite(ctx context.Context, path string, data map[string]inter
 	return nil
 }
 
+// KVWriteCAS — запись в KV v2 с check-and-set: cas=0 создаёт секрет только если его нет,
+// cas=N — только если текущая версия N. Возвращает номер записанной версии.
+// Несовпадение версии распознаётся через IsCASMismatch.
+func (c *Client) KVWriteCAS(ctx context.Context, path string, data map[string]interface{}, cas int) (int, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при KVWriteCAS", "error", err)
+	}
+
+	fullPath := fmt.Sprintf("%s/data/%s", c.config.KVMount, path)
+	c.logger.Debug("KVWriteCAS", "path", fullPath, "cas", cas)
+	writeData := map[string]interface{}{
+		"data":    data,
+		"options": map[string]interface{}{"cas": cas},
+	}
+
+	secret, err := c.retry(ctx, "KVWriteCAS", fullPath, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, fullPath, writeData)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to write secret: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return 0, nil
+	}
+
+	return intFromData(secret.Data["version"]), nil
+}
+
+// KVWriteMetadata — заменяет custom_metadata секрета KV v2 ({kvMount}/metadata/{path}).
+// Для отсутствующего пути создаёт метаданные без версий.
+func (c *Client) KVWriteMetadata(ctx context.Context, path string, customMetadata map[string]string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при KVWriteMetadata", "error", err)
+	}
+
+	fullPath := fmt.Sprintf("%s/metadata/%s", c.config.KVMount, path)
+	c.logger.Debug("KVWriteMetadata", "path", fullPath)
+	custom := make(map[string]interface{}, len(customMetadata))
+	for k, v := range customMetadata {
+		custom[k] = v
+	}
+
+	_, err := c.retry(ctx, "KVWriteMetadata", fullPath, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, fullPath, map[string]interface{}{"custom_metadata": custom})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write secret metadata: %w", err)
+	}
+
+	return nil
+}
+
+// KVDeleteMetadata — безвозвратно удаляет секрет KV v2 со всеми версиями и метаданными.
+func (c *Client) KVDeleteMetadata(ctx context.Context, path string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при KVDeleteMetadata", "error", err)
+	}
+
+	fullPath := fmt.Sprintf("%s/metadata/%s", c.config.KVMount, path)
+	c.logger.Debug("KVDeleteMetadata", "path", fullPath)
+	_, err := c.retry(ctx, "KVDeleteMetadata", fullPath, func() (*api.Secret, error) {
+		return c.client.Logical().DeleteWithContext(ctx, fullPath)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+
+	return nil
+}
+
 // ReadSecret reads a secret from any path (generic)
 func (c *Client) ReadSecret(ctx context.Context, path string) (*api.Secret, error) {
 	if err := c.RefreshToken(ctx); err != nil {
 		c.logger.Warn("failed to refresh token", "error", err)
 	}
 
-	secret, err := c.client.Logical().ReadWithContext(ctx, path)
+	secret, err := c.retry(ctx, "ReadSecret", path, func() (*api.Secret, error) {
+		return c.client.Logical().ReadWithContext(ctx, path)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to read secret: %w", err)
 	}
@@ -456,13 +673,33 @@ func (c *Client) ReadSecret(ctx context.Context, path string) (*api.Secret, erro
 	return secret, nil
 }
 
-// WriteSecret writes data to any path (generic)
+// ReadCredentials — ReadSecret для путей, выпускающих креды при чтении (database/creds/<role>
+// и т.п.): повторяется, только если запрос не выполнен, иначе повтор выпустил бы вторые креды.
+func (c *Client) ReadCredentials(ctx context.Context, path string) (*api.Secret, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("failed to refresh token", "error", err)
+	}
+
+	secret, err := c.retryUnprocessed(ctx, "ReadCredentials", path, func() (*api.Secret, error) {
+		return c.client.Logical().ReadWithContext(ctx, path)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	return secret, nil
+}
+
+// WriteSecret writes data to any path (generic). Запись может выпускать креды (pki/issue,
+// ssh/sign), поэтому повторяется, только если запрос не выполнен.
 func (c *Client) WriteSecret(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
 	if err := c.RefreshToken(ctx); err != nil {
 		c.logger.Warn("failed to refresh token", "error", err)
 	}
 
-	secret, err := c.client.Logical().WriteWithContext(ctx, path, data)
+	secret, err := c.retryUnprocessed(ctx, "WriteSecret", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to write secret: %w", err)
 	}
@@ -470,11 +707,20 @@ func (c *Client) WriteSecret(ctx context.Context, path string, data map[string]i
 	return secret, nil
 }
 
-// Health checks the health of the OpenBao server
+// Health checks the health of the OpenBao server.
+// При нескольких адресах недоступный или запечатанный узел заменяется другим.
 func (c *Client) Health(ctx context.Context) (*api.HealthResponse, error) {
+	addr := c.client.Address()
 	health, err := c.client.Sys().HealthWithContext(ctx)
+	if err == nil && !health.Sealed {
+		return health, nil
+	}
+
+	if c.failover(ctx, addr) {
+		health, err = c.client.Sys().HealthWithContext(ctx)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("health check failed: %w", err)
+		return nil, fmt.Errorf("health check failed: %w", newError("Health", "sys/health", err))
 	}
 	return health, nil
 }
@@ -2,6 +2,11 @@
 package openbao
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -174,3 +179,49 @@ func TestGetEnvDefault(t *testing.T) {
 	defer os.Unsetenv("EXISTENT_VAR")
 	assert.Equal(t, "actual", getEnvDefault("EXISTENT_VAR", "default"))
 }
+
+func TestKVReadWithMetadata(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/app", r.URL.Path)
+		writeJSON(t, w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     map[string]interface{}{"key": "value"},
+				"metadata": map[string]interface{}{"version": 7, "created_time": "2026-01-02T03:04:05Z"},
+			},
+		})
+	})
+
+	data, metadata, err := client.KVReadWithMetadata(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Equal(t, "value", data["key"])
+	assert.Equal(t, "7", fmt.Sprint(metadata["version"]))
+	assert.Equal(t, "2026-01-02T03:04:05Z", metadata["created_time"])
+}
+
+func TestKVWriteCAS(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/app", r.URL.Path)
+
+		var body struct {
+			Data    map[string]interface{} `json:"data"`
+			Options map[string]interface{} `json:"options"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if body.Options["cas"] != float64(3) {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(t, w, map[string]interface{}{"errors": []string{"check-and-set parameter did not match the current version"}})
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{"data": map[string]interface{}{"version": 4}})
+	})
+
+	version, err := client.KVWriteCAS(context.Background(), "app", map[string]interface{}{"key": "value"}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, version)
+
+	_, err = client.KVWriteCAS(context.Background(), "app", map[string]interface{}{"key": "value"}, 1)
+	require.Error(t, err)
+	assert.True(t, IsCASMismatch(err))
+	assert.False(t, IsCASMismatch(errors.New("check-and-set")))
+}
@@ -9,7 +9,7 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// LoadConfig — загружает конфигурацию из YAML-файла (address обязателен).
+// LoadConfig — загружает конфигурацию из YAML-файла (address или addresses обязателен).
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -22,7 +22,7 @@ func LoadConfig(path string) (*Config, error) {
 	}
 
 	// Validate required fields
-	if config.Address == "" {
+	if len(config.AllAddresses()) == 0 {
 		return nil, fmt.Errorf("address is required")
 	}
 
@@ -30,9 +30,11 @@ func LoadConfig(path string) (*Config, error) {
 }
 
 // LoadConfigFromEnv — читает OPENBAO_*, VAULT_*, KUBEBAO_* переменные окружения.
+// OPENBAO_ADDRESSES — узлы HA-кластера через запятую.
 func LoadConfigFromEnv() *Config {
 	config := &Config{
 		Address:      getEnv("OPENBAO_ADDR", "VAULT_ADDR"),
+		Addresses:    parseAddresses(os.Getenv("OPENBAO_ADDRESSES")),
 		Token:        getEnv("OPENBAO_TOKEN", "VAULT_TOKEN"),
 		TransitMount: getEnvDefault("KUBEBAO_TRANSIT_MOUNT", "transit"),
 		KVMount:      getEnvDefault("KUBEBAO_KV_MOUNT", "secret"),
@@ -104,7 +106,7 @@ func DefaultConfig() *Config {
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.Address == "" {
+	if len(c.AllAddresses()) == 0 {
 		return fmt.Errorf("address is required")
 	}
 
@@ -130,6 +132,10 @@ func (c *Config) MergeWithEnv() {
 		c.Address = addr
 	}
 
+	if addresses := parseAddresses(os.Getenv("OPENBAO_ADDRESSES")); len(addresses) > 0 {
+		c.Addresses = addresses
+	}
+
 	if token := getEnv("OPENBAO_TOKEN", "VAULT_TOKEN"); token != "" {
 		c.Token = token
 	}
@@ -0,0 +1,277 @@
+// Классификация ошибок OpenBao — sentinel-ошибки, код ответа, признак повторяемости.
+package openbao
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+// Sentinel-ошибки для errors.Is. *Error сопоставляется с ними по коду ответа.
+var (
+	ErrNotFound         = errors.New("openbao: not found")
+	ErrPermissionDenied = errors.New("openbao: permission denied")
+	ErrSealed           = errors.New("openbao: sealed")
+	ErrUnavailable      = errors.New("openbao: unavailable")
+	ErrRateLimited      = errors.New("openbao: rate limited")
+	ErrInvalidRequest   = errors.New("openbao: invalid request")
+)
+
+// Error — ошибка запроса к OpenBao с HTTP-кодом и признаком повторяемости.
+// StatusCode == 0 означает, что ответ не получен (сетевая ошибка).
+type Error struct {
+	Op         string   // Операция клиента (KVRead, TransitEncrypt, ...)
+	Path       string   // Путь API без /v1/
+	StatusCode int      // HTTP-код ответа OpenBao
+	Errors     []string // Сообщения из тела ответа
+	Err        error    // Исходная ошибка (api.ResponseError, net.Error и т.д.)
+}
+
+// Error формирует сообщение вида "KVRead secret/data/app: 403 permission denied".
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if e.Path != "" {
+		b.WriteString(" ")
+		b.WriteString(e.Path)
+	}
+	b.WriteString(": ")
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, "%d ", e.StatusCode)
+	}
+	switch {
+	case len(e.Errors) > 0:
+		b.WriteString(strings.Join(e.Errors, "; "))
+	case e.Err != nil:
+		b.WriteString(e.Err.Error())
+	case e.kind() != nil:
+		b.WriteString(e.kind().Error())
+	default:
+		b.WriteString("request failed")
+	}
+	return b.String()
+}
+
+// Unwrap отдаёт исходную ошибку для errors.As (например, *api.ResponseError).
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is сопоставляет ошибку с sentinel по коду ответа.
+func (e *Error) Is(target error) bool {
+	kind := e.kind()
+	return kind != nil && kind == target
+}
+
+// Retryable — имеет ли смысл повторить запрос: сетевые ошибки, 412, 429, 502–504 и sealed.
+func (e *Error) Retryable() bool {
+	switch e.StatusCode {
+	case 0:
+		return isNetworkError(e.Err)
+	case http.StatusPreconditionFailed, http.StatusTooManyRequests,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// kind — sentinel, соответствующий коду ответа.
+func (e *Error) kind() error {
+	switch e.StatusCode {
+	case 0:
+		if isNetworkError(e.Err) {
+			return ErrUnavailable
+		}
+		return nil
+	case http.StatusBadRequest:
+		return ErrInvalidRequest
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return ErrPermissionDenied
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusServiceUnavailable:
+		if e.isSealed() {
+			return ErrSealed
+		}
+		return ErrUnavailable
+	case http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusPreconditionFailed:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// isSealed — OpenBao отвечает 503 "Vault is sealed" (сообщение унаследовано от Vault).
+func (e *Error) isSealed() bool {
+	for _, msg := range e.Errors {
+		if strings.Contains(strings.ToLower(msg), "sealed") {
+			return true
+		}
+	}
+	return false
+}
+
+// newError классифицирует ошибку api-клиента. Уже классифицированные ошибки возвращаются как есть.
+func newError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var baoErr *Error
+	if errors.As(err, &baoErr) {
+		return err
+	}
+
+	e := &Error{Op: op, Path: path, Err: err}
+
+	var respErr *api.ResponseError
+	if errors.As(err, &respErr) {
+		e.StatusCode = respErr.StatusCode
+		e.Errors = respErr.Errors
+	}
+
+	return e
+}
+
+// newNotFoundError — ответ без данных (api.Logical возвращает nil, nil на 404).
+func newNotFoundError(op, path string) error {
+	return &Error{Op: op, Path: path, StatusCode: http.StatusNotFound}
+}
+
+// isContextError — отмена или таймаут вызывающего контекста не повторяются.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsNotFound — путь или ключ отсутствует в OpenBao.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsPermissionDenied — токен не имеет прав на путь (403) или недействителен.
+func IsPermissionDenied(err error) bool {
+	return errors.Is(err, ErrPermissionDenied)
+}
+
+// IsSealed — OpenBao запечатан.
+func IsSealed(err error) bool {
+	return errors.Is(err, ErrSealed)
+}
+
+// IsUnavailable — OpenBao недоступен: сетевые ошибки, 502–504, sealed.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrSealed)
+}
+
+// IsRetryable — ошибка временная и запрос можно повторить.
+func IsRetryable(err error) bool {
+	var baoErr *Error
+	if errors.As(err, &baoErr) {
+		return baoErr.Retryable()
+	}
+	return isNetworkError(err)
+}
+
+// IsCASMismatch — запись KV v2 отклонена: версия не совпала с options.cas (400 check-and-set).
+func IsCASMismatch(err error) bool {
+	var baoErr *Error
+	if !errors.As(err, &baoErr) || baoErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	for _, msg := range baoErr.Errors {
+		if strings.Contains(msg, "check-and-set") {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusCode — HTTP-код ответа OpenBao или 0, если ответ не получен.
+func StatusCode(err error) int {
+	var baoErr *Error
+	if errors.As(err, &baoErr) {
+		return baoErr.StatusCode
+	}
+	var respErr *api.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode
+	}
+	return 0
+}
+
+// isNetworkError — ошибки соединения без ответа сервера. Ошибки TLS-сертификата — это
+// неверная настройка (CA, имя сервера), а не временная недоступность: они не повторяются.
+func isNetworkError(err error) bool {
+	if err == nil || isContextError(err) || isCertificateError(err) {
+		return false
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) ||
+		errors.As(err, &urlErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isCertificateError — сертификат сервера не прошёл проверку или ответ не является TLS
+// (http:// адрес на https-порту и наоборот).
+func isCertificateError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		unknownCA    x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		systemRoots  x509.SystemRootsError
+		recordHeader tls.RecordHeaderError
+	)
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &unknownCA) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &systemRoots) ||
+		errors.As(err, &recordHeader)
+}
+
+// isUnprocessed — запрос точно не выполнен сервером: соединение не установлено, OpenBao
+// запечатан или узел не активен (503), превышен лимит запросов (429) или узел ещё не догнал
+// X-Vault-Index (412). Такую ошибку можно повторить и для неидемпотентной операции.
+func isUnprocessed(err error) bool {
+	var baoErr *Error
+	if !errors.As(err, &baoErr) {
+		return isDialError(err)
+	}
+	switch baoErr.StatusCode {
+	case 0:
+		return isDialError(baoErr.Err)
+	case http.StatusPreconditionFailed, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+// isDialError — соединение с сервером не установлено, запрос не отправлен.
+func isDialError(err error) bool {
+	if err == nil || isContextError(err) || isCertificateError(err) {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) || errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,155 @@
+// Тесты классификации ошибок и повторов запросов.
+package openbao
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		errors    []string
+		sentinel  error
+		retryable bool
+	}{
+		{name: "not found", status: 404, sentinel: ErrNotFound},
+		{name: "permission denied", status: 403, errors: []string{"permission denied"}, sentinel: ErrPermissionDenied},
+		{name: "sealed", status: 503, errors: []string{"Vault is sealed"}, sentinel: ErrSealed, retryable: true},
+		{name: "standby unavailable", status: 503, errors: []string{"node is not active"}, sentinel: ErrUnavailable, retryable: true},
+		{name: "rate limited", status: 429, sentinel: ErrRateLimited, retryable: true},
+		{name: "bad request", status: 400, sentinel: ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newError("KVRead", "secret/data/app", &api.ResponseError{StatusCode: tt.status, Errors: tt.errors})
+
+			assert.True(t, errors.Is(err, tt.sentinel))
+			assert.Equal(t, tt.retryable, IsRetryable(err))
+			assert.Equal(t, tt.status, StatusCode(err))
+		})
+	}
+}
+
+func TestErrorClassificationWrapped(t *testing.T) {
+	err := newNotFoundError("KVRead", "secret/data/app")
+	wrapped := errors.Join(errors.New("context"), err)
+
+	assert.True(t, IsNotFound(wrapped))
+	assert.False(t, IsPermissionDenied(wrapped))
+	assert.False(t, IsRetryable(context.Canceled))
+}
+
+func TestClientRetriesOnlyRetryableErrors(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			writeJSON(t, w, map[string]interface{}{"errors": []string{"Vault is sealed"}})
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{
+			"data": map[string]interface{}{"data": map[string]interface{}{"key": "value"}},
+		})
+	})
+	client.config.RetryWaitMin = time.Millisecond
+	client.config.RetryWaitMax = time.Millisecond
+
+	data, err := client.KVRead(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Equal(t, "value", data["key"])
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClientDoesNotRetryPermissionDenied(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+		writeJSON(t, w, map[string]interface{}{"errors": []string{"permission denied"}})
+	})
+	client.config.RetryWaitMin = time.Millisecond
+
+	_, err := client.KVRead(context.Background(), "app")
+	require.Error(t, err)
+	assert.True(t, IsPermissionDenied(err))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestKVReadMissingSecretIsNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(t, w, map[string]interface{}{"errors": []string{}})
+	})
+
+	_, err := client.KVRead(context.Background(), "missing")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsRetryable(err))
+}
+
+func TestBackoffIsBounded(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		wait := backoff(100*time.Millisecond, time.Second, attempt)
+		assert.LessOrEqual(t, wait, time.Second)
+		assert.GreaterOrEqual(t, wait, 50*time.Millisecond)
+	}
+}
+
+func TestClientDoesNotRetryProcessedWrites(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	client.config.RetryWaitMin = time.Millisecond
+	client.config.RetryWaitMax = time.Millisecond
+
+	err := client.KVWrite(context.Background(), "app", map[string]interface{}{"key": "value"})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	_, err = client.ReadCredentials(context.Background(), "database/creds/app")
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClientRetriesUnprocessedWrites(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			writeJSON(t, w, map[string]interface{}{"errors": []string{"Vault is sealed"}})
+			return
+		}
+		writeJSON(t, w, map[string]interface{}{"data": map[string]interface{}{"version": 1}})
+	})
+	client.config.RetryWaitMin = time.Millisecond
+	client.config.RetryWaitMax = time.Millisecond
+
+	_, err := client.WriteSecret(context.Background(), "pki/issue/app", map[string]interface{}{"common_name": "app"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCertificateErrorsAreNotRetryable(t *testing.T) {
+	certErr := &url.Error{Op: "Get", URL: "https://openbao:8200", Err: x509.UnknownAuthorityError{}}
+	err := newError("KVRead", "secret/data/app", certErr)
+
+	assert.False(t, IsRetryable(err))
+	assert.False(t, IsUnavailable(err))
+	assert.False(t, isUnprocessed(err))
+}
@@ -0,0 +1,293 @@
+// Подписка на события OpenBao (sys/events/subscribe) — websocket с CloudEvents в JSON.
+// Используется, чтобы реагировать на запись в KV без опроса.
+package openbao
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// KVDataWriteEvent — запись новой версии секрета KV v2.
+	KVDataWriteEvent = "kv-v2/data-write"
+
+	// DefaultEventReconnectMin и DefaultEventReconnectMax — границы задержки переподключения EventWatcher.
+	DefaultEventReconnectMin = time.Second
+	DefaultEventReconnectMax = time.Minute
+)
+
+// KVEvent — событие записи в KV v2.
+type KVEvent struct {
+	Type    string // Тип события, например kv-v2/data-write
+	Mount   string // Путь монтирования без завершающего "/" (secret)
+	Path    string // Путь секрета относительно mount без "data/" (myapp/config)
+	Version int    // Записанная версия, 0 — если неизвестна
+}
+
+// eventMessage — CloudEvent из sys/events/subscribe?json=true.
+type eventMessage struct {
+	Data struct {
+		EventType string `json:"event_type"`
+		Event     struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"event"`
+		PluginInfo struct {
+			MountPath string `json:"mount_path"`
+		} `json:"plugin_info"`
+	} `json:"data"`
+}
+
+// parseKVEvent извлекает mount и путь из CloudEvent. data_path содержит полный путь
+// вида secret/data/myapp/config; события без него (например, metadata-only) пропускаются.
+func parseKVEvent(raw []byte) (KVEvent, bool, error) {
+	var msg eventMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return KVEvent{}, false, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	meta := msg.Data.Event.Metadata
+	dataPath, _ := meta["data_path"].(string)
+	mount := strings.TrimSuffix(msg.Data.PluginInfo.MountPath, "/")
+	if dataPath == "" || mount == "" {
+		return KVEvent{}, false, nil
+	}
+
+	path := strings.TrimPrefix(dataPath, mount+"/")
+	path = strings.TrimPrefix(path, "data/")
+
+	event := KVEvent{Type: msg.Data.EventType, Mount: mount, Path: path}
+	if v, ok := meta["current_version"].(string); ok {
+		event.Version, _ = strconv.Atoi(v)
+	}
+	return event, true, nil
+}
+
+// eventsConfig — параметры websocket для sys/events/subscribe/<eventType> на текущем узле.
+func (c *Client) eventsConfig(eventType string) (*websocket.Config, error) {
+	location, err := url.Parse(c.client.Address())
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenBao address: %w", err)
+	}
+	origin := *location
+
+	switch location.Scheme {
+	case "https":
+		location.Scheme = "wss"
+	default:
+		location.Scheme = "ws"
+	}
+	location.Path = strings.TrimSuffix(location.Path, "/") + "/v1/sys/events/subscribe/" + eventType
+	location.RawQuery = url.Values{"json": []string{"true"}}.Encode()
+
+	config, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+	config.Header = http.Header{}
+	config.Header.Set("X-Vault-Token", c.client.Token())
+	if ns := c.client.Namespace(); ns != "" {
+		config.Header.Set("X-Vault-Namespace", ns)
+	}
+	if transport, ok := c.client.CloneConfig().HttpClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+		config.TlsConfig = transport.TLSClientConfig.Clone()
+	} else if location.Scheme == "wss" {
+		config.TlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return config, nil
+}
+
+// SubscribeKVEvents подписывается на eventType и вызывает handle для каждого события KV
+// до отмены ctx или разрыва соединения. onConnect вызывается после успешного рукопожатия.
+func (c *Client) SubscribeKVEvents(ctx context.Context, eventType string, onConnect func(), handle func(KVEvent)) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при SubscribeKVEvents", "error", err)
+	}
+
+	config, err := c.eventsConfig(eventType)
+	if err != nil {
+		return err
+	}
+
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", eventType, err)
+	}
+	defer conn.Close()
+
+	// Чтение блокируется — закрываем соединение при отмене ctx
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c.logger.Info("Подписка на события OpenBao", "eventType", eventType, "address", c.client.Address())
+	if onConnect != nil {
+		onConnect()
+	}
+
+	for {
+		var raw []byte
+		if err := websocket.Message.Receive(conn, &raw); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("event stream %s closed: %w", eventType, err)
+		}
+
+		event, ok, err := parseKVEvent(raw)
+		if err != nil {
+			c.logger.Warn("Некорректное событие OpenBao", "error", err)
+			continue
+		}
+		if ok {
+			handle(event)
+		}
+	}
+}
+
+// EventWatcher держит подписку на события KV и переподключается с экспоненциальной задержкой.
+// Start совместим с manager.Runnable controller-runtime.
+type EventWatcher struct {
+	client    *Client
+	eventType string
+	logger    hclog.Logger
+
+	reconnectMin time.Duration
+	reconnectMax time.Duration
+
+	connected  atomic.Bool
+	mu         sync.RWMutex
+	handlers   []func(KVEvent)
+	reconnects []func()
+}
+
+// NewEventWatcher создаёт подписчика на eventType (обычно KVDataWriteEvent).
+func NewEventWatcher(client *Client, eventType string, logger hclog.Logger) *EventWatcher {
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+
+	return &EventWatcher{
+		client:       client,
+		eventType:    eventType,
+		logger:       logger,
+		reconnectMin: DefaultEventReconnectMin,
+		reconnectMax: DefaultEventReconnectMax,
+	}
+}
+
+// OnEvent регистрирует обработчик; вызывается синхронно из цикла чтения.
+func (w *EventWatcher) OnEvent(handler func(KVEvent)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, handler)
+}
+
+// OnReconnect регистрирует обработчик восстановления подписки после разрыва: события,
+// пришедшие за время разрыва, потеряны, и потребителю нужно перечитать данные.
+func (w *EventWatcher) OnReconnect(handler func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.reconnects = append(w.reconnects, handler)
+}
+
+// Connected — подписка активна. Пока её нет, потребители должны опрашивать OpenBao сами.
+func (w *EventWatcher) Connected() bool {
+	return w.connected.Load()
+}
+
+// Mount — KV mount клиента; события других mount'ов потребителям не интересны.
+func (w *EventWatcher) Mount() string {
+	return w.client.config.KVMount
+}
+
+// Serves — события подписки относятся к серверу address и namespace OpenBao namespace: пустое
+// значение означает сервер и namespace клиента подписки. Ресурсам другого сервера или namespace
+// события не приходят, и они должны опрашивать OpenBao сами.
+func (w *EventWatcher) Serves(address, namespace string) bool {
+	if namespace != "" && strings.Trim(namespace, "/") != strings.Trim(w.client.config.Namespace, "/") {
+		return false
+	}
+	if address == "" {
+		return true
+	}
+	for _, addr := range w.client.addresses {
+		if strings.TrimRight(addr, "/") == strings.TrimRight(address, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Start — подписка с переподключением до отмены ctx.
+func (w *EventWatcher) Start(ctx context.Context) error {
+	delay := w.reconnectMin
+	wasConnected := false
+	for {
+		started := time.Now()
+		err := w.client.SubscribeKVEvents(ctx, w.eventType, func() {
+			w.connected.Store(true)
+			if wasConnected {
+				w.emitReconnect()
+			}
+			wasConnected = true
+		}, w.emit)
+		w.connected.Store(false)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Соединение, прожившее дольше максимальной задержки, считается успешным
+		if time.Since(started) > w.reconnectMax {
+			delay = w.reconnectMin
+		}
+		w.logger.Warn("Подписка на события OpenBao прервана, переход на опрос", "error", err, "retryIn", delay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > w.reconnectMax {
+			delay = w.reconnectMax
+		}
+	}
+}
+
+// emit вызывает зарегистрированные обработчики.
+func (w *EventWatcher) emit(event KVEvent) {
+	w.mu.RLock()
+	handlers := make([]func(KVEvent), len(w.handlers))
+	copy(handlers, w.handlers)
+	w.mu.RUnlock()
+
+	w.logger.Debug("Событие OpenBao", "type", event.Type, "mount", event.Mount, "path", event.Path, "version", event.Version)
+	for _, h := range handlers {
+		h(event)
+	}
+}
+
+// emitReconnect вызывает обработчики восстановления подписки.
+func (w *EventWatcher) emitReconnect() {
+	w.mu.RLock()
+	handlers := make([]func(), len(w.reconnects))
+	copy(handlers, w.reconnects)
+	w.mu.RUnlock()
+
+	w.logger.Info("Подписка на события OpenBao восстановлена")
+	for _, h := range handlers {
+		h()
+	}
+}
@@ -0,0 +1,125 @@
+// Тесты подписки на события OpenBao через фиктивный websocket-сервер.
+package openbao
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+const testDataWriteEvent = `{
+  "id": "a3be9fb1-b514-519f-5b25-b6f144a8c1ce",
+  "source": "https://openbao.example:8200/",
+  "specversion": "1.0",
+  "type": "*",
+  "data": {
+    "event": {
+      "id": "a3be9fb1-b514-519f-5b25-b6f144a8c1ce",
+      "metadata": {
+        "current_version": "3",
+        "data_path": "secret/data/myapp/config",
+        "modified": "true",
+        "oldest_version": "0",
+        "operation": "data-write",
+        "path": "secret/data/myapp/config"
+      }
+    },
+    "event_type": "kv-v2/data-write",
+    "plugin_info": {"mount_class": "secret", "mount_path": "secret/", "plugin": "kv"}
+  },
+  "datacontentype": "application/cloudevents",
+  "time": "2026-01-01T00:00:00Z"
+}`
+
+func TestParseKVEvent(t *testing.T) {
+	event, ok, err := parseKVEvent([]byte(testDataWriteEvent))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, KVEvent{Type: KVDataWriteEvent, Mount: "secret", Path: "myapp/config", Version: 3}, event)
+
+	_, ok, err = parseKVEvent([]byte(`{"data": {"event_type": "kv-v2/metadata-write", "event": {"metadata": {}}}}`))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = parseKVEvent([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestEventWatcherDeliversEvents(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/sys/events/subscribe/kv-v2/data-write", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("json"))
+		assert.Equal(t, "test-token", r.Header.Get("X-Vault-Token"))
+
+		websocket.Handler(func(conn *websocket.Conn) {
+			require.NoError(t, websocket.Message.Send(conn, testDataWriteEvent))
+			// Держим соединение до закрытия клиентом
+			var discard []byte
+			_ = websocket.Message.Receive(conn, &discard)
+		}).ServeHTTP(w, r)
+	})
+
+	watcher := NewEventWatcher(client, KVDataWriteEvent, hclog.NewNullLogger())
+	events := make(chan KVEvent, 1)
+	watcher.OnEvent(func(event KVEvent) { events <- event })
+	assert.Equal(t, "secret", watcher.Mount())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- watcher.Start(ctx) }()
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "myapp/config", event.Path)
+		assert.Equal(t, 3, event.Version)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+	assert.True(t, watcher.Connected())
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not stop")
+	}
+	assert.False(t, watcher.Connected())
+}
+
+func TestEventWatcherServes(t *testing.T) {
+	client, err := NewClient(&Config{
+		Address:   "https://openbao-0:8200",
+		Addresses: []string{"https://openbao-1:8200/"},
+		Namespace: "team-a/",
+		Token:     "test-token",
+	}, hclog.NewNullLogger())
+	require.NoError(t, err)
+	watcher := NewEventWatcher(client, KVDataWriteEvent, nil)
+
+	tests := []struct {
+		name      string
+		address   string
+		namespace string
+		serves    bool
+	}{
+		{name: "operator server", serves: true},
+		{name: "same address", address: "https://openbao-0:8200/", serves: true},
+		{name: "another cluster node", address: "https://openbao-1:8200", serves: true},
+		{name: "same namespace", namespace: "team-a", serves: true},
+		{name: "another server", address: "https://other:8200"},
+		{name: "another namespace", namespace: "team-b"},
+		{name: "same address, another namespace", address: "https://openbao-0:8200", namespace: "team-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.serves, watcher.Serves(tt.address, tt.namespace))
+		})
+	}
+}
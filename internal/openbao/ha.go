@@ -0,0 +1,271 @@
+// HA-кластер OpenBao (Raft): несколько адресов, выбор активного узла, переключение при сбоях
+// и согласованность read-after-write через заголовок X-Vault-Index.
+package openbao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	// IndexHeaderName — состояние WAL узла; standby с отставшим WAL отвечает 412 до догоняющей репликации.
+	IndexHeaderName = "X-Vault-Index"
+	// DefaultNodeCheckInterval — период фоновой перепроверки узлов при нескольких адресах.
+	DefaultNodeCheckInterval = 30 * time.Second
+	// nodeProbeTimeout ограничивает sys/health одного узла, чтобы «зависший» pod не тормозил выбор.
+	nodeProbeTimeout = 5 * time.Second
+)
+
+// NodeState — роль узла по ответу sys/health.
+type NodeState string
+
+const (
+	NodeActive        NodeState = "active"
+	NodeStandby       NodeState = "standby"
+	NodeSealed        NodeState = "sealed"
+	NodeUninitialized NodeState = "uninitialized"
+	NodeUnreachable   NodeState = "unreachable"
+)
+
+// NodeStatus — результат проверки одного адреса.
+type NodeStatus struct {
+	Address string
+	State   NodeState
+	Version string
+	Err     error
+}
+
+// AllAddresses — Address и Addresses без пустых значений и повторов; первый адрес — предпочтительный.
+func (c *Config) AllAddresses() []string {
+	seen := make(map[string]bool)
+	var addresses []string
+	for _, addr := range append([]string{c.Address}, c.Addresses...) {
+		addr = strings.TrimRight(strings.TrimSpace(addr), "/")
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		addresses = append(addresses, addr)
+	}
+	return addresses
+}
+
+// parseAddresses разбирает список адресов через запятую (OPENBAO_ADDRESSES).
+func parseAddresses(value string) []string {
+	var addresses []string
+	for _, addr := range strings.Split(value, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addresses = append(addresses, addr)
+		}
+	}
+	return addresses
+}
+
+// consistencyIndex запоминает последний X-Vault-Index из ответов и передаёт его в следующих запросах,
+// чтобы чтение после записи на standby-узле видело записанные данные.
+type consistencyIndex struct {
+	mu    sync.RWMutex
+	value string
+}
+
+// record — ResponseCallback api-клиента.
+func (i *consistencyIndex) record(resp *api.Response) {
+	if resp == nil || resp.Response == nil {
+		return
+	}
+	if value := resp.Header.Get(IndexHeaderName); value != "" {
+		i.mu.Lock()
+		i.value = value
+		i.mu.Unlock()
+	}
+}
+
+// require — RequestCallback api-клиента.
+func (i *consistencyIndex) require(req *api.Request) {
+	i.mu.RLock()
+	value := i.value
+	i.mu.RUnlock()
+
+	if value == "" {
+		return
+	}
+	if req.Headers == nil {
+		req.Headers = make(http.Header)
+	}
+	req.Headers.Set(IndexHeaderName, value)
+}
+
+// Address — адрес узла, на который сейчас отправляются запросы.
+func (c *Client) Address() string {
+	return c.client.Address()
+}
+
+// Nodes проверяет sys/health всех настроенных адресов.
+func (c *Client) Nodes(ctx context.Context) []NodeStatus {
+	statuses := make([]NodeStatus, len(c.addresses))
+
+	var wg sync.WaitGroup
+	for i, addr := range c.addresses {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			statuses[i] = c.probeNode(ctx, addr)
+		}(i, addr)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// probeNode запрашивает sys/health у одного узла отдельным клоном api-клиента.
+func (c *Client) probeNode(ctx context.Context, addr string) NodeStatus {
+	status := NodeStatus{Address: addr, State: NodeUnreachable}
+
+	probe, err := c.client.Clone()
+	if err == nil {
+		err = probe.SetAddress(addr)
+	}
+	if err != nil {
+		status.Err = err
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, nodeProbeTimeout)
+	defer cancel()
+
+	health, err := probe.Sys().HealthWithContext(ctx)
+	if err != nil {
+		status.Err = newError("Health", "sys/health", err)
+		return status
+	}
+
+	status.Version = health.Version
+	switch {
+	case !health.Initialized:
+		status.State = NodeUninitialized
+	case health.Sealed:
+		status.State = NodeSealed
+	case health.Standby:
+		status.State = NodeStandby
+	default:
+		status.State = NodeActive
+	}
+	return status
+}
+
+// selectNode переключает клиент на активный узел, при его отсутствии — на распечатанный standby
+// (standby перенаправляет запросы на активный узел). Порядок адресов задаёт приоритет.
+func (c *Client) selectNode(ctx context.Context) error {
+	c.nodeMu.Lock()
+	c.lastNodeCheck = time.Now()
+	c.nodeMu.Unlock()
+
+	statuses := c.Nodes(ctx)
+
+	var chosen *NodeStatus
+	for i := range statuses {
+		if statuses[i].State == NodeActive {
+			chosen = &statuses[i]
+			break
+		}
+		if statuses[i].State == NodeStandby && chosen == nil {
+			chosen = &statuses[i]
+		}
+	}
+
+	if chosen == nil {
+		var errs []error
+		for _, s := range statuses {
+			errs = append(errs, fmt.Errorf("%s: %s", s.Address, s.State))
+		}
+		return &Error{Op: "SelectNode", StatusCode: http.StatusServiceUnavailable,
+			Err: fmt.Errorf("no healthy OpenBao node: %w", errors.Join(errs...))}
+	}
+
+	current := c.client.Address()
+	if chosen.Address == current {
+		return nil
+	}
+
+	if err := c.client.SetAddress(chosen.Address); err != nil {
+		return fmt.Errorf("failed to switch OpenBao node: %w", err)
+	}
+
+	c.logger.Info("Переключение на узел OpenBao",
+		"from", current,
+		"to", chosen.Address,
+		"state", chosen.State,
+	)
+	return nil
+}
+
+// failover вызывается после ошибки запроса к узлу failedAddr. Возвращает true, если запрос
+// имеет смысл повторить на другом узле. Параллельные вызовы не перебирают узлы повторно:
+// если адрес уже сменён другим запросом, переключение считается выполненным.
+func (c *Client) failover(ctx context.Context, failedAddr string) bool {
+	if len(c.addresses) < 2 {
+		return false
+	}
+
+	c.failoverMu.Lock()
+	defer c.failoverMu.Unlock()
+
+	if c.client.Address() != failedAddr {
+		return true
+	}
+
+	if err := c.selectNode(ctx); err != nil {
+		c.logger.Warn("Не удалось переключиться на другой узел OpenBao", "failed", failedAddr, "error", err)
+		return false
+	}
+	return c.client.Address() != failedAddr
+}
+
+// maybeCheckNodes периодически перепроверяет узлы в фоне: после восстановления кластера
+// клиент возвращается с standby на активный узел.
+func (c *Client) maybeCheckNodes() {
+	if len(c.addresses) < 2 || c.config.NodeCheckInterval < 0 {
+		return
+	}
+
+	c.nodeMu.Lock()
+	due := time.Since(c.lastNodeCheck) >= c.config.NodeCheckInterval
+	c.nodeMu.Unlock()
+
+	if !due || !c.nodeCheckRunning.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer c.nodeCheckRunning.Store(false)
+
+		c.failoverMu.Lock()
+		defer c.failoverMu.Unlock()
+
+		if err := c.selectNode(context.Background()); err != nil {
+			c.logger.Warn("Проверка узлов OpenBao не удалась", "error", err)
+		}
+	}()
+}
+
+// shouldFailover — ошибка указывает на недоступность конкретного узла, а не на ошибку запроса.
+func shouldFailover(err error) bool {
+	var baoErr *Error
+	if !errors.As(err, &baoErr) {
+		return false
+	}
+	switch baoErr.StatusCode {
+	case 0:
+		return isNetworkError(baoErr.Err)
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+// Тесты выбора узла HA-кластера, переключения и X-Vault-Index.
+package openbao
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNode — узел OpenBao: sys/health с ролью и KV-чтение с X-Vault-Index.
+type fakeNode struct {
+	server  *httptest.Server
+	mu      sync.Mutex
+	standby bool
+	sealed  bool
+	reads   int
+	index   []string // X-Vault-Index из входящих запросов
+}
+
+func newFakeNode(t *testing.T, standby bool) *fakeNode {
+	t.Helper()
+
+	n := &fakeNode{standby: standby}
+	n.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		n.index = append(n.index, r.Header.Get(IndexHeaderName))
+
+		if r.URL.Path == "/v1/sys/health" {
+			writeJSON(t, w, map[string]interface{}{
+				"initialized": true,
+				"sealed":      n.sealed,
+				"standby":     n.standby,
+			})
+			return
+		}
+
+		n.reads++
+		w.Header().Set(IndexHeaderName, "wal-42")
+		writeJSON(t, w, map[string]interface{}{
+			"data": map[string]interface{}{"data": map[string]interface{}{"key": "value"}},
+		})
+	}))
+	t.Cleanup(n.server.Close)
+	return n
+}
+
+func newHAClient(t *testing.T, addresses ...string) *Client {
+	t.Helper()
+
+	client, err := NewClient(&Config{
+		Addresses:         addresses,
+		Token:             "test-token",
+		NodeCheckInterval: -1,
+	}, hclog.NewNullLogger())
+	require.NoError(t, err)
+	return client
+}
+
+func TestConfigAllAddresses(t *testing.T) {
+	cfg := &Config{
+		Address:   "http://bao-0:8200/",
+		Addresses: []string{"http://bao-0:8200", " http://bao-1:8200", ""},
+	}
+
+	assert.Equal(t, []string{"http://bao-0:8200", "http://bao-1:8200"}, cfg.AllAddresses())
+	assert.Equal(t, []string{"a", "b"}, parseAddresses("a, b,,"))
+}
+
+func TestClientPrefersActiveNode(t *testing.T) {
+	standby := newFakeNode(t, true)
+	active := newFakeNode(t, false)
+
+	client := newHAClient(t, standby.server.URL, active.server.URL)
+
+	assert.Equal(t, active.server.URL, client.Address())
+}
+
+func TestClientFailsOverOnConnectionError(t *testing.T) {
+	active := newFakeNode(t, false)
+	standby := newFakeNode(t, true)
+
+	client := newHAClient(t, active.server.URL, standby.server.URL)
+	require.Equal(t, active.server.URL, client.Address())
+
+	active.server.Close()
+
+	data, err := client.KVRead(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Equal(t, "value", data["key"])
+	assert.Equal(t, standby.server.URL, client.Address())
+	assert.Equal(t, 1, standby.reads)
+}
+
+func TestClientSendsConsistencyIndex(t *testing.T) {
+	node := newFakeNode(t, false)
+	client := newHAClient(t, node.server.URL)
+
+	_, err := client.KVRead(context.Background(), "app")
+	require.NoError(t, err)
+	_, err = client.KVRead(context.Background(), "app")
+	require.NoError(t, err)
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	require.NotEmpty(t, node.index)
+	assert.Equal(t, "wal-42", node.index[len(node.index)-1])
+}
+
+func TestClientNoHealthyNode(t *testing.T) {
+	a := newFakeNode(t, false)
+	b := newFakeNode(t, true)
+	a.sealed, b.sealed = true, true
+
+	client := newHAClient(t, a.server.URL, b.server.URL)
+
+	err := client.selectNode(context.Background())
+	require.Error(t, err)
+	assert.True(t, IsUnavailable(err))
+	assert.Equal(t, a.server.URL, client.Address())
+}
@@ -0,0 +1,441 @@
+// Менеджер аренд (lease) динамических секретов — учёт, продление до истечения, отзыв, события.
+package openbao
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	// DefaultLeaseCheckInterval — период проверки аренд в LeaseManager.Start.
+	DefaultLeaseCheckInterval = 10 * time.Second
+
+	// leaseRenewFraction — доля TTL, после которой аренда продлевается (2/3, как у api.LifetimeWatcher).
+	leaseRenewFraction = 2.0 / 3.0
+
+	// leaseRenewMaxBackoff — предельная задержка между повторными попытками продления.
+	leaseRenewMaxBackoff = 5 * time.Minute
+)
+
+// LeaseClient — операции с арендами, общие для openbao.Client и csi.AuthenticatedClient.
+type LeaseClient interface {
+	RenewLease(ctx context.Context, leaseID string, increment time.Duration) (*api.Secret, error)
+	RevokeLease(ctx context.Context, leaseID string) error
+}
+
+// Lease — отслеживаемая аренда динамического секрета.
+type Lease struct {
+	ID        string
+	Owner     string // Идентификатор владельца: "BaoSecret/ns/name" или путь тома CSI
+	Path      string // Путь, по которому выдан секрет (database/creds/app, pki/issue/web)
+	Renewable bool
+	Duration  time.Duration // TTL, полученный при выдаче или последнем продлении
+	IssuedAt  time.Time     // Момент выдачи или последнего продления
+	ExpiresAt time.Time
+	// RenewFailures — неудачные продления подряд; сбрасывается успешным продлением
+	RenewFailures int
+	// RetryAt — следующая попытка продления после неудачи (экспоненциальная задержка)
+	RetryAt time.Time
+
+	client LeaseClient // Клиент, выдавший аренду (TrackFor); nil — клиент менеджера
+}
+
+// renewAt — момент, после которого аренду пора продлевать.
+func (l *Lease) renewAt() time.Time {
+	return l.IssuedAt.Add(time.Duration(float64(l.Duration) * leaseRenewFraction))
+}
+
+// LeaseEventType — тип события менеджера аренд.
+type LeaseEventType string
+
+const (
+	// LeaseRenewed — аренда успешно продлена.
+	LeaseRenewed LeaseEventType = "Renewed"
+	// LeaseRenewFailed — продление не удалось; аренда ещё действует, попытка будет повторена.
+	// Отправляется один раз на серию неудач, повторные попытки идут с задержкой (Lease.RetryAt).
+	LeaseRenewFailed LeaseEventType = "RenewFailed"
+	// LeaseExpiring — аренду нельзя продлить дальше (не renewable или достигнут max_ttl), нужен новый секрет.
+	LeaseExpiring LeaseEventType = "Expiring"
+	// LeaseExpired — аренда истекла и снята с учёта.
+	LeaseExpired LeaseEventType = "Expired"
+	// LeaseRevoked — аренда отозвана в OpenBao.
+	LeaseRevoked LeaseEventType = "Revoked"
+)
+
+// LeaseEvent — событие по аренде для владельца (контроллер, CSI-провайдер).
+type LeaseEvent struct {
+	Type  LeaseEventType
+	Lease Lease
+	Err   error
+}
+
+// LeaseManager — учёт аренд по владельцам и фоновое продление до истечения TTL.
+// Start совместим с manager.Runnable controller-runtime.
+type LeaseManager struct {
+	client        LeaseClient
+	logger        hclog.Logger
+	checkInterval time.Duration
+
+	mu       sync.RWMutex
+	leases   map[string]*Lease
+	expiring map[string]bool // Аренды, по которым LeaseExpiring уже отправлено
+	handlers []func(LeaseEvent)
+}
+
+// NewLeaseManager создаёт менеджер аренд поверх client.
+func NewLeaseManager(client LeaseClient, logger hclog.Logger) *LeaseManager {
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+
+	return &LeaseManager{
+		client:        client,
+		logger:        logger,
+		checkInterval: DefaultLeaseCheckInterval,
+		leases:        make(map[string]*Lease),
+		expiring:      make(map[string]bool),
+	}
+}
+
+// OnEvent регистрирует обработчик событий; вызывается синхронно из цикла продления.
+func (m *LeaseManager) OnEvent(handler func(LeaseEvent)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// Track ставит на учёт аренду из ответа OpenBao. Секреты без lease_id (KV) игнорируются — возвращается nil.
+func (m *LeaseManager) Track(owner, path string, secret *api.Secret) *Lease {
+	return m.TrackFor(nil, owner, path, secret)
+}
+
+// TrackFor — Track для аренды, выданной другим клиентом (например, под identity tenant'а):
+// продление и отзыв выполняются через client, а не через клиент менеджера.
+func (m *LeaseManager) TrackFor(client LeaseClient, owner, path string, secret *api.Secret) *Lease {
+	if secret == nil || secret.LeaseID == "" {
+		return nil
+	}
+
+	now := time.Now()
+	duration := time.Duration(secret.LeaseDuration) * time.Second
+	lease := &Lease{
+		ID:        secret.LeaseID,
+		Owner:     owner,
+		Path:      path,
+		Renewable: secret.Renewable,
+		Duration:  duration,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(duration),
+		client:    client,
+	}
+
+	m.mu.Lock()
+	m.leases[lease.ID] = lease
+	delete(m.expiring, lease.ID)
+	m.mu.Unlock()
+
+	m.logger.Debug("Аренда поставлена на учёт", "leaseID", lease.ID, "owner", owner, "ttl", duration, "renewable", lease.Renewable)
+
+	leaseCopy := *lease
+	return &leaseCopy
+}
+
+// Untrack снимает аренду с учёта без отзыва в OpenBao.
+func (m *LeaseManager) Untrack(leaseID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.leases, leaseID)
+	delete(m.expiring, leaseID)
+}
+
+// Get возвращает копию аренды по ID.
+func (m *LeaseManager) Get(leaseID string) (Lease, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lease, ok := m.leases[leaseID]
+	if !ok {
+		return Lease{}, false
+	}
+	return *lease, true
+}
+
+// IsExpiring — аренда не может быть продлена дальше (отправлено LeaseExpiring).
+func (m *LeaseManager) IsExpiring(leaseID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.expiring[leaseID]
+}
+
+// Leases возвращает копии аренд владельца, отсортированные по времени истечения.
+func (m *LeaseManager) Leases(owner string) []Lease {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []Lease
+	for _, lease := range m.leases {
+		if lease.Owner == owner {
+			result = append(result, *lease)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ExpiresAt.Before(result[j].ExpiresAt)
+	})
+	return result
+}
+
+// Revoke отзывает аренду в OpenBao и снимает её с учёта.
+func (m *LeaseManager) Revoke(ctx context.Context, leaseID string) error {
+	client := m.client
+	if lease, ok := m.Get(leaseID); ok && lease.client != nil {
+		client = lease.client
+	}
+	if client == nil {
+		return fmt.Errorf("failed to revoke lease %s: no OpenBao client", leaseID)
+	}
+
+	if err := client.RevokeLease(ctx, leaseID); err != nil {
+		return fmt.Errorf("failed to revoke lease %s: %w", leaseID, err)
+	}
+
+	m.mu.Lock()
+	lease, ok := m.leases[leaseID]
+	delete(m.leases, leaseID)
+	delete(m.expiring, leaseID)
+	m.mu.Unlock()
+
+	event := LeaseEvent{Type: LeaseRevoked, Lease: Lease{ID: leaseID}}
+	if ok {
+		event.Lease = *lease
+	}
+	m.emit(event)
+
+	m.logger.Info("Аренда отозвана", "leaseID", leaseID)
+	return nil
+}
+
+// RevokeOwner отзывает все аренды владельца (например, при удалении BaoSecret).
+// Продолжает после ошибок и возвращает первую из них.
+func (m *LeaseManager) RevokeOwner(ctx context.Context, owner string) error {
+	var firstErr error
+	for _, lease := range m.Leases(owner) {
+		if err := m.Revoke(ctx, lease.ID); err != nil {
+			m.logger.Warn("Не удалось отозвать аренду", "leaseID", lease.ID, "owner", owner, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Start — цикл продления до отмены ctx.
+func (m *LeaseManager) Start(ctx context.Context) error {
+	ticker := time.NewTicker(m.checkInterval)
+	defer ticker.Stop()
+
+	m.logger.Info("Менеджер аренд запущен", "checkInterval", m.checkInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.renewDue(ctx, time.Now())
+		}
+	}
+}
+
+// renewDue продлевает аренды, у которых прошло 2/3 TTL, и снимает с учёта истёкшие.
+// После неудачного продления следующая попытка — не раньше RetryAt.
+func (m *LeaseManager) renewDue(ctx context.Context, now time.Time) {
+	m.mu.RLock()
+	var due []Lease
+	for _, lease := range m.leases {
+		expired := !now.Before(lease.ExpiresAt)
+		if expired || (!now.Before(lease.renewAt()) && !now.Before(lease.RetryAt)) {
+			due = append(due, *lease)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, lease := range due {
+		m.renew(ctx, lease, now)
+	}
+}
+
+// renew продлевает одну аренду и отправляет соответствующее событие.
+func (m *LeaseManager) renew(ctx context.Context, lease Lease, now time.Time) {
+	if !now.Before(lease.ExpiresAt) {
+		m.Untrack(lease.ID)
+		m.logger.Warn("Аренда истекла", "leaseID", lease.ID, "owner", lease.Owner)
+		m.emit(LeaseEvent{Type: LeaseExpired, Lease: lease})
+		return
+	}
+
+	if !lease.Renewable {
+		m.markExpiring(lease)
+		return
+	}
+
+	client := m.client
+	if lease.client != nil {
+		client = lease.client
+	}
+	if client == nil {
+		return
+	}
+
+	secret, err := client.RenewLease(ctx, lease.ID, lease.Duration)
+	if err != nil {
+		m.mu.Lock()
+		if tracked, ok := m.leases[lease.ID]; ok {
+			tracked.RenewFailures++
+			tracked.RetryAt = now.Add(m.renewBackoff(tracked.RenewFailures))
+			lease = *tracked
+		}
+		m.mu.Unlock()
+
+		if lease.RenewFailures > 1 {
+			m.logger.Debug("Повторное продление аренды не удалось", "leaseID", lease.ID, "failures", lease.RenewFailures, "retryAt", lease.RetryAt, "error", err)
+			return
+		}
+		m.logger.Warn("Не удалось продлить аренду", "leaseID", lease.ID, "owner", lease.Owner, "retryAt", lease.RetryAt, "error", err)
+		m.emit(LeaseEvent{Type: LeaseRenewFailed, Lease: lease, Err: err})
+		return
+	}
+
+	newDuration := lease.Duration
+	if secret != nil && secret.LeaseDuration > 0 {
+		newDuration = time.Duration(secret.LeaseDuration) * time.Second
+	}
+
+	m.mu.Lock()
+	tracked, ok := m.leases[lease.ID]
+	if ok {
+		tracked.IssuedAt = now
+		tracked.ExpiresAt = now.Add(newDuration)
+		tracked.RenewFailures = 0
+		tracked.RetryAt = time.Time{}
+		// Сохраняем исходный TTL для расчёта следующего продления: укороченный ответ означает max_ttl.
+		lease = *tracked
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	// OpenBao урезал TTL — аренда упирается в max_ttl и следующее продление не поможет.
+	if newDuration < lease.Duration {
+		m.markExpiring(lease)
+		return
+	}
+
+	m.logger.Debug("Аренда продлена", "leaseID", lease.ID, "ttl", newDuration)
+	m.emit(LeaseEvent{Type: LeaseRenewed, Lease: lease})
+}
+
+// renewBackoff — задержка перед следующей попыткой после failures неудач подряд:
+// checkInterval, удваиваемый с каждой неудачей, но не больше leaseRenewMaxBackoff.
+func (m *LeaseManager) renewBackoff(failures int) time.Duration {
+	backoff := m.checkInterval
+	for i := 1; i < failures && backoff < leaseRenewMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, leaseRenewMaxBackoff)
+}
+
+// markExpiring однократно отправляет LeaseExpiring для аренды.
+func (m *LeaseManager) markExpiring(lease Lease) {
+	m.mu.Lock()
+	already := m.expiring[lease.ID]
+	m.expiring[lease.ID] = true
+	m.mu.Unlock()
+
+	if already {
+		return
+	}
+
+	m.logger.Info("Аренда не может быть продлена, требуется новый секрет", "leaseID", lease.ID, "owner", lease.Owner, "expiresAt", lease.ExpiresAt)
+	m.emit(LeaseEvent{Type: LeaseExpiring, Lease: lease})
+}
+
+// emit вызывает зарегистрированные обработчики.
+func (m *LeaseManager) emit(event LeaseEvent) {
+	m.mu.RLock()
+	handlers := make([]func(LeaseEvent), len(m.handlers))
+	copy(handlers, m.handlers)
+	m.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(event)
+	}
+}
+
+// RenewLease продлевает аренду через sys/leases/renew; increment — желаемый TTL.
+func (c *Client) RenewLease(ctx context.Context, leaseID string, increment time.Duration) (*api.Secret, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен перед продлением аренды", "error", err)
+	}
+
+	secret, err := c.retry(ctx, "RenewLease", "sys/leases/renew", func() (*api.Secret, error) {
+		return c.client.Sys().RenewWithContext(ctx, leaseID, int(increment.Seconds()))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew lease: %w", err)
+	}
+
+	return secret, nil
+}
+
+// LookupLease читает состояние аренды (sys/leases/lookup) и возвращает его в виде api.Secret
+// с LeaseID, LeaseDuration (оставшийся TTL) и Renewable — пригодно для Track после рестарта.
+func (c *Client) LookupLease(ctx context.Context, leaseID string) (*api.Secret, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен перед чтением аренды", "error", err)
+	}
+
+	path := "sys/leases/lookup"
+	data := map[string]interface{}{"lease_id": leaseID}
+
+	secret, err := c.retry(ctx, "LookupLease", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup lease: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("lease not found: %s: %w", leaseID, newNotFoundError("LookupLease", path))
+	}
+
+	renewable, _ := secret.Data["renewable"].(bool)
+	return &api.Secret{
+		LeaseID:       leaseID,
+		LeaseDuration: intFromData(secret.Data["ttl"]),
+		Renewable:     renewable,
+	}, nil
+}
+
+// RevokeLease отзывает аренду через sys/leases/revoke.
+func (c *Client) RevokeLease(ctx context.Context, leaseID string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен перед отзывом аренды", "error", err)
+	}
+
+	_, err := c.retry(ctx, "RevokeLease", "sys/leases/revoke", func() (*api.Secret, error) {
+		return nil, c.client.Sys().RevokeWithContext(ctx, leaseID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke lease: %w", err)
+	}
+
+	return nil
+}
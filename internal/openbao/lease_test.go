@@ -0,0 +1,218 @@
+// Тесты менеджера аренд.
+package openbao
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLeaseClient — LeaseClient с управляемыми ответами.
+type fakeLeaseClient struct {
+	mu        sync.Mutex
+	renewTTL  int
+	renewErr  error
+	renewed   []string
+	revoked   []string
+	revokeErr error
+}
+
+func (f *fakeLeaseClient) RenewLease(_ context.Context, leaseID string, _ time.Duration) (*api.Secret, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.renewed = append(f.renewed, leaseID)
+	if f.renewErr != nil {
+		return nil, f.renewErr
+	}
+	return &api.Secret{LeaseID: leaseID, LeaseDuration: f.renewTTL, Renewable: true}, nil
+}
+
+func (f *fakeLeaseClient) RevokeLease(_ context.Context, leaseID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.revokeErr != nil {
+		return f.revokeErr
+	}
+	f.revoked = append(f.revoked, leaseID)
+	return nil
+}
+
+func collectEvents(m *LeaseManager) *[]LeaseEvent {
+	var events []LeaseEvent
+	m.OnEvent(func(e LeaseEvent) { events = append(events, e) })
+	return &events
+}
+
+func TestLeaseManagerTrackIgnoresSecretsWithoutLease(t *testing.T) {
+	m := NewLeaseManager(&fakeLeaseClient{}, nil)
+
+	assert.Nil(t, m.Track("owner", "secret/data/app", &api.Secret{}))
+	assert.Nil(t, m.Track("owner", "secret/data/app", nil))
+	assert.Empty(t, m.Leases("owner"))
+}
+
+func TestLeaseManagerRenewsAfterTwoThirdsOfTTL(t *testing.T) {
+	client := &fakeLeaseClient{renewTTL: 60}
+	m := NewLeaseManager(client, nil)
+	events := collectEvents(m)
+
+	lease := m.Track("owner", "database/creds/app", &api.Secret{LeaseID: "db/1", LeaseDuration: 60, Renewable: true})
+	require.NotNil(t, lease)
+
+	// До 2/3 TTL продление не выполняется
+	m.renewDue(context.Background(), lease.IssuedAt.Add(30*time.Second))
+	assert.Empty(t, client.renewed)
+
+	m.renewDue(context.Background(), lease.IssuedAt.Add(41*time.Second))
+	assert.Equal(t, []string{"db/1"}, client.renewed)
+	require.Len(t, *events, 1)
+	assert.Equal(t, LeaseRenewed, (*events)[0].Type)
+
+	renewed, ok := m.Get("db/1")
+	require.True(t, ok)
+	assert.Equal(t, lease.IssuedAt.Add(101*time.Second), renewed.ExpiresAt)
+}
+
+func TestLeaseManagerEmitsExpiringWhenTTLCapped(t *testing.T) {
+	client := &fakeLeaseClient{renewTTL: 10}
+	m := NewLeaseManager(client, nil)
+	events := collectEvents(m)
+
+	lease := m.Track("owner", "database/creds/app", &api.Secret{LeaseID: "db/1", LeaseDuration: 60, Renewable: true})
+	m.renewDue(context.Background(), lease.IssuedAt.Add(45*time.Second))
+
+	require.Len(t, *events, 1)
+	assert.Equal(t, LeaseExpiring, (*events)[0].Type)
+}
+
+func TestLeaseManagerRenewFailureAndExpiry(t *testing.T) {
+	client := &fakeLeaseClient{renewErr: errors.New("permission denied")}
+	m := NewLeaseManager(client, nil)
+	events := collectEvents(m)
+
+	lease := m.Track("owner", "pki/issue/web", &api.Secret{LeaseID: "pki/1", LeaseDuration: 30, Renewable: true})
+
+	m.renewDue(context.Background(), lease.IssuedAt.Add(25*time.Second))
+	m.renewDue(context.Background(), lease.IssuedAt.Add(31*time.Second))
+
+	require.Len(t, *events, 2)
+	assert.Equal(t, LeaseRenewFailed, (*events)[0].Type)
+	assert.Error(t, (*events)[0].Err)
+	assert.Equal(t, 1, (*events)[0].Lease.RenewFailures)
+	assert.Equal(t, LeaseExpired, (*events)[1].Type)
+
+	_, ok := m.Get("pki/1")
+	assert.False(t, ok)
+}
+
+func TestLeaseManagerRenewFailureBackoff(t *testing.T) {
+	client := &fakeLeaseClient{renewTTL: 300, renewErr: errors.New("connection refused")}
+	m := NewLeaseManager(client, nil)
+	events := collectEvents(m)
+	ctx := context.Background()
+
+	lease := m.Track("owner", "database/creds/app", &api.Secret{LeaseID: "db/1", LeaseDuration: 300, Renewable: true})
+	at := func(seconds int) time.Time { return lease.IssuedAt.Add(time.Duration(seconds) * time.Second) }
+
+	m.renewDue(ctx, at(200))
+	require.Len(t, *events, 1)
+	assert.Equal(t, LeaseRenewFailed, (*events)[0].Type)
+	failed, ok := m.Get("db/1")
+	require.True(t, ok)
+	assert.Equal(t, 1, failed.RenewFailures)
+	assert.Equal(t, at(210), failed.RetryAt)
+
+	// До RetryAt попытки не выполняются
+	m.renewDue(ctx, at(205))
+	assert.Len(t, client.renewed, 1)
+
+	// Повторная неудача — без нового события, задержка удваивается
+	m.renewDue(ctx, at(210))
+	assert.Len(t, client.renewed, 2)
+	assert.Len(t, *events, 1)
+	failed, _ = m.Get("db/1")
+	assert.Equal(t, 2, failed.RenewFailures)
+	assert.Equal(t, at(230), failed.RetryAt)
+
+	m.renewDue(ctx, at(229))
+	assert.Len(t, client.renewed, 2)
+
+	// Успешное продление сбрасывает серию неудач
+	client.renewErr = nil
+	m.renewDue(ctx, at(230))
+	require.Len(t, *events, 2)
+	assert.Equal(t, LeaseRenewed, (*events)[1].Type)
+	renewed, _ := m.Get("db/1")
+	assert.Zero(t, renewed.RenewFailures)
+	assert.True(t, renewed.RetryAt.IsZero())
+
+	// Новая серия неудач снова сообщается
+	client.renewErr = errors.New("connection refused")
+	m.renewDue(ctx, at(430))
+	require.Len(t, *events, 3)
+	assert.Equal(t, LeaseRenewFailed, (*events)[2].Type)
+}
+
+func TestLeaseManagerRenewBackoff(t *testing.T) {
+	m := NewLeaseManager(nil, nil)
+
+	assert.Equal(t, 10*time.Second, m.renewBackoff(1))
+	assert.Equal(t, 20*time.Second, m.renewBackoff(2))
+	assert.Equal(t, 160*time.Second, m.renewBackoff(5))
+	assert.Equal(t, leaseRenewMaxBackoff, m.renewBackoff(6))
+	assert.Equal(t, leaseRenewMaxBackoff, m.renewBackoff(100))
+}
+
+func TestLeaseManagerRevokeOwner(t *testing.T) {
+	client := &fakeLeaseClient{}
+	m := NewLeaseManager(client, nil)
+
+	m.Track("a", "database/creds/app", &api.Secret{LeaseID: "db/1", LeaseDuration: 60})
+	m.Track("a", "database/creds/app", &api.Secret{LeaseID: "db/2", LeaseDuration: 120})
+	m.Track("b", "database/creds/app", &api.Secret{LeaseID: "db/3", LeaseDuration: 60})
+
+	require.NoError(t, m.RevokeOwner(context.Background(), "a"))
+
+	assert.ElementsMatch(t, []string{"db/1", "db/2"}, client.revoked)
+	assert.Empty(t, m.Leases("a"))
+	assert.Len(t, m.Leases("b"), 1)
+}
+
+func TestLeaseManagerTrackForUsesIssuingClient(t *testing.T) {
+	tenant := &fakeLeaseClient{renewTTL: 60}
+	m := NewLeaseManager(nil, nil)
+
+	lease := m.TrackFor(tenant, "owner", "database/creds/app", &api.Secret{LeaseID: "db/1", LeaseDuration: 60, Renewable: true})
+	m.renewDue(context.Background(), lease.IssuedAt.Add(41*time.Second))
+	require.NoError(t, m.Revoke(context.Background(), "db/1"))
+
+	assert.Equal(t, []string{"db/1"}, tenant.renewed)
+	assert.Equal(t, []string{"db/1"}, tenant.revoked)
+	assert.Error(t, m.Revoke(context.Background(), "db/2"))
+}
+
+func TestLookupLease(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/sys/leases/lookup", r.URL.Path)
+		writeJSON(t, w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"id":        "database/creds/app/abc",
+				"ttl":       3000,
+				"renewable": true,
+			},
+		})
+	})
+
+	secret, err := client.LookupLease(context.Background(), "database/creds/app/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "database/creds/app/abc", secret.LeaseID)
+	assert.Equal(t, 3000, secret.LeaseDuration)
+	assert.True(t, secret.Renewable)
+}
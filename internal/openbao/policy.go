@@ -0,0 +1,97 @@
+// ACL-политики OpenBao (sys/policies/acl).
+package openbao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+// aclPolicyPath — путь ACL-политики.
+func aclPolicyPath(name string) string {
+	return "sys/policies/acl/" + name
+}
+
+// ReadACLPolicy — текст ACL-политики. Отсутствующая политика — ошибка IsNotFound.
+func (c *Client) ReadACLPolicy(ctx context.Context, name string) (string, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при ReadACLPolicy", "error", err)
+	}
+
+	path := aclPolicyPath(name)
+	secret, err := c.retry(ctx, "ReadACLPolicy", path, func() (*api.Secret, error) {
+		return c.client.Logical().ReadWithContext(ctx, path)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read policy: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", fmt.Errorf("policy not found: %s: %w", name, newNotFoundError("ReadACLPolicy", path))
+	}
+
+	policy, _ := secret.Data["policy"].(string)
+	return policy, nil
+}
+
+// ListACLPolicies — имена всех ACL-политик, включая встроенные default и root.
+func (c *Client) ListACLPolicies(ctx context.Context) ([]string, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при ListACLPolicies", "error", err)
+	}
+
+	path := "sys/policies/acl"
+	secret, err := c.retry(ctx, "ListACLPolicies", path, func() (*api.Secret, error) {
+		return c.client.Logical().ListWithContext(ctx, path)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, nil
+	}
+
+	keys, _ := secret.Data["keys"].([]interface{})
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if name, ok := key.(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// WriteACLPolicy — создание или замена ACL-политики.
+func (c *Client) WriteACLPolicy(ctx context.Context, name, policy string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при WriteACLPolicy", "error", err)
+	}
+
+	path := aclPolicyPath(name)
+	c.logger.Debug("WriteACLPolicy", "path", path)
+	_, err := c.retry(ctx, "WriteACLPolicy", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, map[string]interface{}{"policy": policy})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write policy: %w", err)
+	}
+	return nil
+}
+
+// DeleteACLPolicy — удаление ACL-политики. OpenBao не сообщает об отсутствии политики,
+// но 404 от прокси или старых версий тоже считается успехом.
+func (c *Client) DeleteACLPolicy(ctx context.Context, name string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при DeleteACLPolicy", "error", err)
+	}
+
+	path := aclPolicyPath(name)
+	c.logger.Debug("DeleteACLPolicy", "path", path)
+	_, err := c.retry(ctx, "DeleteACLPolicy", path, func() (*api.Secret, error) {
+		return c.client.Logical().DeleteWithContext(ctx, path)
+	})
+	if err != nil && !IsNotFound(err) {
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Тесты ACL-политик OpenBao.
+package openbao
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACLPolicyLifecycle(t *testing.T) {
+	policies := map[string]string{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[len("/v1/sys/policies/acl/"):]
+		switch r.Method {
+		case http.MethodGet:
+			policy, ok := policies[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				writeJSON(t, w, map[string]interface{}{"errors": []string{}})
+				return
+			}
+			writeJSON(t, w, map[string]interface{}{"data": map[string]interface{}{"name": name, "policy": policy}})
+		case http.MethodPut, http.MethodPost:
+			var body struct {
+				Policy string `json:"policy"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			policies[name] = body.Policy
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			delete(policies, name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	ctx := context.Background()
+
+	_, err := client.ReadACLPolicy(ctx, "app")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+
+	require.NoError(t, client.WriteACLPolicy(ctx, "app", `path "secret/*" { capabilities = ["read"] }`))
+	policy, err := client.ReadACLPolicy(ctx, "app")
+	require.NoError(t, err)
+	assert.Equal(t, `path "secret/*" { capabilities = ["read"] }`, policy)
+
+	require.NoError(t, client.DeleteACLPolicy(ctx, "app"))
+	_, err = client.ReadACLPolicy(ctx, "app")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestDeleteACLPolicyNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(t, w, map[string]interface{}{"errors": []string{}})
+	})
+
+	assert.NoError(t, client.DeleteACLPolicy(context.Background(), "missing"))
+}
+
+func TestListACLPolicies(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v1/sys/policies/acl", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("list"))
+		writeJSON(t, w, map[string]interface{}{"data": map[string]interface{}{"keys": []string{"app", "default", "root"}}})
+	})
+
+	names, err := client.ListACLPolicies(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "default", "root"}, names)
+}
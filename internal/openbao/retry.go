@@ -0,0 +1,101 @@
+// Повтор запросов к OpenBao с экспоненциальной задержкой — только для временных ошибок.
+package openbao
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	// DefaultRetryWaitMin — задержка перед первым повтором.
+	DefaultRetryWaitMin = 500 * time.Millisecond
+	// DefaultRetryWaitMax — верхняя граница задержки между повторами.
+	DefaultRetryWaitMax = 10 * time.Second
+)
+
+// retry выполняет fn и повторяет её до MaxRetries раз, если ошибка IsRetryable. Только для
+// чтения и идемпотентной записи: ответ 502/504 или обрыв соединения не означают, что сервер не
+// выполнил запрос. Неидемпотентные операции используют retryUnprocessed.
+// Ошибки классифицируются в *Error; отмена ctx прерывает ожидание. При нескольких адресах
+// недоступность узла сначала приводит к переключению на другой узел и повтору без задержки.
+func (c *Client) retry(ctx context.Context, op, path string, fn func() (*api.Secret, error)) (*api.Secret, error) {
+	return c.do(ctx, op, path, IsRetryable, fn)
+}
+
+// retryUnprocessed — retry для неидемпотентных операций (выпуск кредов и токенов, новая версия
+// KV, создание и ротация ключа): запрос повторяется, только если сервер его точно не выполнил.
+// Иначе повтор после 502/504 выпустил бы креды повторно, а аренда первых осталась бы без владельца.
+func (c *Client) retryUnprocessed(ctx context.Context, op, path string, fn func() (*api.Secret, error)) (*api.Secret, error) {
+	return c.do(ctx, op, path, isUnprocessed, fn)
+}
+
+// do — общий цикл retry и retryUnprocessed; retryable решает, можно ли повторить ошибку.
+func (c *Client) do(ctx context.Context, op, path string, retryable func(error) bool, fn func() (*api.Secret, error)) (*api.Secret, error) {
+	var lastErr error
+
+	c.maybeCheckNodes()
+
+	for attempt := 0; ; attempt++ {
+		addr := c.client.Address()
+		secret, err := fn()
+		if err == nil {
+			return secret, nil
+		}
+
+		lastErr = newError(op, path, err)
+		if !retryable(lastErr) || attempt >= c.config.MaxRetries {
+			return nil, lastErr
+		}
+
+		if shouldFailover(lastErr) && c.failover(ctx, addr) {
+			c.logger.Debug("Повтор запроса на другом узле OpenBao",
+				"op", op,
+				"path", path,
+				"failed", addr,
+				"error", lastErr,
+			)
+			continue
+		}
+
+		wait := backoff(c.config.RetryWaitMin, c.config.RetryWaitMax, attempt)
+		c.logger.Debug("Временная ошибка OpenBao, повтор запроса",
+			"op", op,
+			"path", path,
+			"attempt", attempt+1,
+			"wait", wait,
+			"error", lastErr,
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, lastErr
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff — min·2^attempt, ограниченная max, с равномерным джиттером в верхней половине интервала.
+func backoff(minWait, maxWait time.Duration, attempt int) time.Duration {
+	if minWait <= 0 {
+		minWait = DefaultRetryWaitMin
+	}
+	if maxWait < minWait {
+		maxWait = minWait
+	}
+
+	wait := minWait
+	for i := 0; i < attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+
+	half := wait / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
@@ -0,0 +1,444 @@
+// Transit API OpenBao — rewrap, datakey, HMAC, sign/verify, пакетные операции и конфигурация ключей.
+package openbao
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+// TransitKeyConfig — изменяемые параметры ключа (transit/keys/{name}/config).
+// nil-поля не отправляются, т.е. остаются без изменений на стороне OpenBao.
+type TransitKeyConfig struct {
+	MinDecryptionVersion *int
+	MinEncryptionVersion *int
+	DeletionAllowed      *bool
+	Exportable           *bool
+	AllowPlaintextBackup *bool
+	AutoRotatePeriod     *time.Duration
+}
+
+// toMap переводит конфигурацию в тело запроса OpenBao.
+func (k *TransitKeyConfig) toMap() map[string]interface{} {
+	data := map[string]interface{}{}
+	if k.MinDecryptionVersion != nil {
+		data["min_decryption_version"] = *k.MinDecryptionVersion
+	}
+	if k.MinEncryptionVersion != nil {
+		data["min_encryption_version"] = *k.MinEncryptionVersion
+	}
+	if k.DeletionAllowed != nil {
+		data["deletion_allowed"] = *k.DeletionAllowed
+	}
+	if k.Exportable != nil {
+		data["exportable"] = *k.Exportable
+	}
+	if k.AllowPlaintextBackup != nil {
+		data["allow_plaintext_backup"] = *k.AllowPlaintextBackup
+	}
+	if k.AutoRotatePeriod != nil {
+		data["auto_rotate_period"] = k.AutoRotatePeriod.String()
+	}
+	return data
+}
+
+// TransitDataKey — результат transit/datakey: DEK в открытом виде (если запрошен) и обёрнутый ключом Transit.
+type TransitDataKey struct {
+	Plaintext  []byte
+	Ciphertext string
+	KeyVersion int
+}
+
+// TransitBatchResult — элемент batch_results. Error заполняется OpenBao для отдельных неуспешных элементов.
+type TransitBatchResult struct {
+	Ciphertext string
+	Plaintext  []byte
+	KeyVersion int
+	Error      string
+}
+
+// TransitSignOptions — необязательные параметры transit/sign.
+type TransitSignOptions struct {
+	HashAlgorithm       string // sha2-256, sha2-512 и т.д.
+	SignatureAlgorithm  string // pss или pkcs1v15 для RSA
+	Prehashed           bool
+	MarshalingAlgorithm string // asn1 или jws для ECDSA
+}
+
+// transitPath — {transitMount}/{op}/{keyName}.
+func (c *Client) transitPath(op, keyName string) string {
+	return fmt.Sprintf("%s/%s/%s", c.config.TransitMount, op, keyName)
+}
+
+// transitWrite — общий путь для POST-запросов Transit: обновление токена и проверка наличия data.
+func (c *Client) transitWrite(ctx context.Context, op, keyName string, data map[string]interface{}) (map[string]interface{}, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен перед операцией Transit", "op", op, "error", err)
+	}
+
+	path := c.transitPath(op, keyName)
+	c.logger.Debug("Transit "+op, "path", path)
+
+	secret, err := c.retry(ctx, "Transit "+op, path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("transit %s failed: %w", op, err)
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no data returned from transit %s operation", op)
+	}
+
+	return secret.Data, nil
+}
+
+// TransitRewrap — перешифровывает ciphertext последней версией ключа без раскрытия plaintext.
+func (c *Client) TransitRewrap(ctx context.Context, keyName string, ciphertext string) (string, error) {
+	data, err := c.transitWrite(ctx, "rewrap", keyName, map[string]interface{}{
+		"ciphertext": ciphertext,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	newCiphertext, ok := data["ciphertext"].(string)
+	if !ok {
+		return "", fmt.Errorf("ciphertext not found in response")
+	}
+
+	return newCiphertext, nil
+}
+
+// TransitDataKey — генерирует DEK через transit/datakey/{plaintext|wrapped}/{keyName}.
+// При withPlaintext=false OpenBao возвращает только обёрнутый ключ. bits — 128, 256 или 512 (0 — по умолчанию 256).
+func (c *Client) TransitDataKey(ctx context.Context, keyName string, withPlaintext bool, bits int) (*TransitDataKey, error) {
+	keyType := "wrapped"
+	if withPlaintext {
+		keyType = "plaintext"
+	}
+
+	body := map[string]interface{}{}
+	if bits > 0 {
+		body["bits"] = bits
+	}
+
+	data, err := c.transitWrite(ctx, "datakey/"+keyType, keyName, body)
+	if err != nil {
+		return nil, err
+	}
+
+	dk := &TransitDataKey{
+		KeyVersion: intFromData(data["key_version"]),
+	}
+
+	ciphertext, ok := data["ciphertext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("ciphertext not found in response")
+	}
+	dk.Ciphertext = ciphertext
+
+	if withPlaintext {
+		plaintextB64, ok := data["plaintext"].(string)
+		if !ok {
+			return nil, fmt.Errorf("plaintext not found in response")
+		}
+		dk.Plaintext, err = base64.StdEncoding.DecodeString(plaintextB64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode plaintext: %w", err)
+		}
+	}
+
+	return dk, nil
+}
+
+// TransitHMAC — вычисляет HMAC от input ключом keyName; algorithm пустой — sha2-256.
+func (c *Client) TransitHMAC(ctx context.Context, keyName string, input []byte, algorithm string) (string, error) {
+	body := map[string]interface{}{
+		"input": base64.StdEncoding.EncodeToString(input),
+	}
+	if algorithm != "" {
+		body["algorithm"] = algorithm
+	}
+
+	data, err := c.transitWrite(ctx, "hmac", keyName, body)
+	if err != nil {
+		return "", err
+	}
+
+	hmac, ok := data["hmac"].(string)
+	if !ok {
+		return "", fmt.Errorf("hmac not found in response")
+	}
+
+	return hmac, nil
+}
+
+// TransitVerifyHMAC — проверяет HMAC, полученный TransitHMAC.
+func (c *Client) TransitVerifyHMAC(ctx context.Context, keyName string, input []byte, hmac string) (bool, error) {
+	data, err := c.transitWrite(ctx, "verify", keyName, map[string]interface{}{
+		"input": base64.StdEncoding.EncodeToString(input),
+		"hmac":  hmac,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	valid, _ := data["valid"].(bool)
+	return valid, nil
+}
+
+// TransitSign — подписывает input асимметричным ключом (ed25519, ecdsa-*, rsa-*).
+func (c *Client) TransitSign(ctx context.Context, keyName string, input []byte, opts *TransitSignOptions) (string, error) {
+	body := map[string]interface{}{
+		"input": base64.StdEncoding.EncodeToString(input),
+	}
+	if opts != nil {
+		if opts.HashAlgorithm != "" {
+			body["hash_algorithm"] = opts.HashAlgorithm
+		}
+		if opts.SignatureAlgorithm != "" {
+			body["signature_algorithm"] = opts.SignatureAlgorithm
+		}
+		if opts.MarshalingAlgorithm != "" {
+			body["marshaling_algorithm"] = opts.MarshalingAlgorithm
+		}
+		if opts.Prehashed {
+			body["prehashed"] = true
+		}
+	}
+
+	data, err := c.transitWrite(ctx, "sign", keyName, body)
+	if err != nil {
+		return "", err
+	}
+
+	signature, ok := data["signature"].(string)
+	if !ok {
+		return "", fmt.Errorf("signature not found in response")
+	}
+
+	return signature, nil
+}
+
+// TransitVerify — проверяет подпись, выданную TransitSign, с теми же opts.
+func (c *Client) TransitVerify(ctx context.Context, keyName string, input []byte, signature string, opts *TransitSignOptions) (bool, error) {
+	body := map[string]interface{}{
+		"input":     base64.StdEncoding.EncodeToString(input),
+		"signature": signature,
+	}
+	if opts != nil {
+		if opts.HashAlgorithm != "" {
+			body["hash_algorithm"] = opts.HashAlgorithm
+		}
+		if opts.SignatureAlgorithm != "" {
+			body["signature_algorithm"] = opts.SignatureAlgorithm
+		}
+		if opts.MarshalingAlgorithm != "" {
+			body["marshaling_algorithm"] = opts.MarshalingAlgorithm
+		}
+		if opts.Prehashed {
+			body["prehashed"] = true
+		}
+	}
+
+	data, err := c.transitWrite(ctx, "verify", keyName, body)
+	if err != nil {
+		return false, err
+	}
+
+	valid, _ := data["valid"].(bool)
+	return valid, nil
+}
+
+// TransitBatchEncrypt — шифрует несколько plaintext одним запросом (batch_input).
+// Порядок результатов совпадает с порядком входа; ошибки отдельных элементов — в TransitBatchResult.Error.
+func (c *Client) TransitBatchEncrypt(ctx context.Context, keyName string, plaintexts [][]byte) ([]TransitBatchResult, error) {
+	items := make([]map[string]interface{}, 0, len(plaintexts))
+	for _, p := range plaintexts {
+		items = append(items, map[string]interface{}{
+			"plaintext": base64.StdEncoding.EncodeToString(p),
+		})
+	}
+
+	return c.transitBatch(ctx, "encrypt", keyName, items)
+}
+
+// TransitBatchDecrypt — дешифрует несколько ciphertext одним запросом.
+func (c *Client) TransitBatchDecrypt(ctx context.Context, keyName string, ciphertexts []string) ([]TransitBatchResult, error) {
+	items := make([]map[string]interface{}, 0, len(ciphertexts))
+	for _, ct := range ciphertexts {
+		items = append(items, map[string]interface{}{
+			"ciphertext": ct,
+		})
+	}
+
+	return c.transitBatch(ctx, "decrypt", keyName, items)
+}
+
+// TransitBatchRewrap — перешифровывает несколько ciphertext последней версией ключа (после ротации).
+func (c *Client) TransitBatchRewrap(ctx context.Context, keyName string, ciphertexts []string) ([]TransitBatchResult, error) {
+	items := make([]map[string]interface{}, 0, len(ciphertexts))
+	for _, ct := range ciphertexts {
+		items = append(items, map[string]interface{}{
+			"ciphertext": ct,
+		})
+	}
+
+	return c.transitBatch(ctx, "rewrap", keyName, items)
+}
+
+// transitBatch — отправляет batch_input и разбирает batch_results в порядке входа.
+func (c *Client) transitBatch(ctx context.Context, op, keyName string, items []map[string]interface{}) ([]TransitBatchResult, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	data, err := c.transitWrite(ctx, op, keyName, map[string]interface{}{
+		"batch_input": items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rawResults, ok := data["batch_results"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("batch_results not found in response")
+	}
+
+	if len(rawResults) != len(items) {
+		return nil, fmt.Errorf("batch_results length mismatch: want %d, got %d", len(items), len(rawResults))
+	}
+
+	results := make([]TransitBatchResult, len(rawResults))
+	for i, raw := range rawResults {
+		item, ok := raw.(map[string]interface{})
+		if !ok {
+			results[i].Error = "invalid batch result format"
+			continue
+		}
+
+		if errMsg, ok := item["error"].(string); ok && errMsg != "" {
+			results[i].Error = errMsg
+			continue
+		}
+
+		results[i].KeyVersion = intFromData(item["key_version"])
+		if ct, ok := item["ciphertext"].(string); ok {
+			results[i].Ciphertext = ct
+		}
+		if pt, ok := item["plaintext"].(string); ok {
+			decoded, err := base64.StdEncoding.DecodeString(pt)
+			if err != nil {
+				results[i].Error = fmt.Sprintf("failed to decode plaintext: %v", err)
+				continue
+			}
+			results[i].Plaintext = decoded
+		}
+	}
+
+	return results, nil
+}
+
+// TransitRotateKey — создаёт новую версию ключа; старые ciphertext остаются расшифровываемыми.
+func (c *Client) TransitRotateKey(ctx context.Context, keyName string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при ротации ключа", "error", err)
+	}
+
+	path := fmt.Sprintf("%s/keys/%s/rotate", c.config.TransitMount, keyName)
+	c.logger.Debug("Transit RotateKey", "path", path)
+
+	_, err := c.retryUnprocessed(ctx, "TransitRotateKey", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rotate transit key: %w", err)
+	}
+
+	c.logger.Info("Transit ключ повёрнут", "name", keyName)
+	return nil
+}
+
+// TransitUpdateKeyConfig — применяет TransitKeyConfig к transit/keys/{keyName}/config.
+func (c *Client) TransitUpdateKeyConfig(ctx context.Context, keyName string, cfg *TransitKeyConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("key config cannot be nil")
+	}
+
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при изменении конфигурации ключа", "error", err)
+	}
+
+	path := fmt.Sprintf("%s/keys/%s/config", c.config.TransitMount, keyName)
+	c.logger.Debug("Transit UpdateKeyConfig", "path", path)
+
+	_, err := c.retry(ctx, "TransitUpdateKeyConfig", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, cfg.toMap())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update transit key config: %w", err)
+	}
+
+	return nil
+}
+
+// TransitDeleteKey — удаляет ключ; OpenBao требует deletion_allowed=true в конфигурации ключа.
+func (c *Client) TransitDeleteKey(ctx context.Context, keyName string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при удалении ключа", "error", err)
+	}
+
+	path := fmt.Sprintf("%s/keys/%s", c.config.TransitMount, keyName)
+	c.logger.Debug("Transit DeleteKey", "path", path)
+
+	_, err := c.retry(ctx, "TransitDeleteKey", path, func() (*api.Secret, error) {
+		return c.client.Logical().DeleteWithContext(ctx, path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete transit key: %w", err)
+	}
+
+	c.logger.Info("Transit ключ удалён", "name", keyName)
+	return nil
+}
+
+// intFromData — приводит числовое поле ответа OpenBao (json.Number, float64, int, string) к int.
+func intFromData(v interface{}) int {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil {
+			return 0
+		}
+		return int(i)
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case string:
+		i, err := strconv.Atoi(n)
+		if err != nil {
+			return 0
+		}
+		return i
+	}
+	return 0
+}
+
+// durationFromData — разбирает поле длительности (секунды числом или строка вида "24h").
+func durationFromData(v interface{}) time.Duration {
+	if s, ok := v.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	return time.Duration(intFromData(v)) * time.Second
+}
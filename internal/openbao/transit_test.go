@@ -0,0 +1,123 @@
+// Тесты Transit API на фиктивном HTTP-сервере OpenBao.
+package openbao
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient поднимает httptest-сервер с обработчиком handler и клиент с токенной аутентификацией.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&Config{
+		Address: server.URL,
+		Token:   "test-token",
+	}, hclog.NewNullLogger())
+	require.NoError(t, err)
+
+	return client
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(t, json.NewEncoder(w).Encode(v))
+}
+
+func TestTransitBatchRewrap(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/transit/rewrap/my-key", r.URL.Path)
+
+		var body map[string][]map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Len(t, body["batch_input"], 2)
+
+		writeJSON(t, w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"batch_results": []map[string]interface{}{
+					{"ciphertext": "vault:v2:aaa", "key_version": 2},
+					{"error": "invalid ciphertext"},
+				},
+			},
+		})
+	})
+
+	results, err := client.TransitBatchRewrap(context.Background(), "my-key", []string{"vault:v1:aaa", "broken"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "vault:v2:aaa", results[0].Ciphertext)
+	assert.Equal(t, 2, results[0].KeyVersion)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, "invalid ciphertext", results[1].Error)
+}
+
+func TestTransitDataKey(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/transit/datakey/plaintext/my-key", r.URL.Path)
+		writeJSON(t, w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"plaintext":   "c2VjcmV0",
+				"ciphertext":  "vault:v3:bbb",
+				"key_version": 3,
+			},
+		})
+	})
+
+	dk, err := client.TransitDataKey(context.Background(), "my-key", true, 256)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), dk.Plaintext)
+	assert.Equal(t, "vault:v3:bbb", dk.Ciphertext)
+	assert.Equal(t, 3, dk.KeyVersion)
+}
+
+func TestTransitGetKeyInfo(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"type":                   "aes256-gcm96",
+				"latest_version":         4,
+				"min_decryption_version": 2,
+				"deletion_allowed":       true,
+				"auto_rotate_period":     86400,
+			},
+		})
+	})
+
+	info, err := client.TransitGetKeyInfo(context.Background(), "my-key")
+	require.NoError(t, err)
+	assert.Equal(t, 4, info.LatestVersion)
+	assert.Equal(t, 2, info.MinDecryptionVersion)
+	assert.True(t, info.DeletionAllowed)
+	assert.Equal(t, 24*time.Hour, info.AutoRotatePeriod)
+}
+
+func TestTransitKeyConfigToMap(t *testing.T) {
+	minDec := 3
+	deletion := true
+	period := 720 * time.Hour
+
+	cfg := &TransitKeyConfig{
+		MinDecryptionVersion: &minDec,
+		DeletionAllowed:      &deletion,
+		AutoRotatePeriod:     &period,
+	}
+
+	data := cfg.toMap()
+	assert.Equal(t, 3, data["min_decryption_version"])
+	assert.Equal(t, true, data["deletion_allowed"])
+	assert.Equal(t, "720h0m0s", data["auto_rotate_period"])
+	assert.NotContains(t, data, "exportable")
+}
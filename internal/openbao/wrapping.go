@@ -0,0 +1,170 @@
+// Response wrapping — ответ OpenBao помещается в cubbyhole одноразового токена,
+// получатель извлекает его сам через sys/wrapping/unwrap.
+package openbao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+// DefaultWrapTTL — время жизни wrapping-токена, если TTL не задан.
+const DefaultWrapTTL = 5 * time.Minute
+
+// WrappingTokenInfo — сведения sys/wrapping/lookup; само содержимое не раскрывается.
+type WrappingTokenInfo struct {
+	CreationPath string
+	CreationTime time.Time
+	CreationTTL  time.Duration
+}
+
+// ExpiresAt — момент, после которого токен недействителен.
+func (i *WrappingTokenInfo) ExpiresAt() time.Time {
+	return i.CreationTime.Add(i.CreationTTL)
+}
+
+// wrapped возвращает копию api-клиента, запрашивающую обёрнутые ответы (X-Vault-Wrap-TTL).
+// Копия создаётся на каждую попытку, чтобы повтор после переключения узла шёл на новый адрес.
+func (c *Client) wrapped(ttl time.Duration) *api.Client {
+	if ttl <= 0 {
+		ttl = DefaultWrapTTL
+	}
+	wrapTTL := fmt.Sprintf("%ds", int(ttl.Seconds()))
+
+	return c.client.WithRequestCallbacks(c.index.require, func(r *api.Request) {
+		r.WrapTTL = wrapTTL
+	})
+}
+
+// wrapInfo извлекает WrapInfo; ответ без него означает, что путь не поддерживает wrapping.
+func wrapInfo(op, path string, secret *api.Secret) (*api.SecretWrapInfo, error) {
+	if secret == nil {
+		return nil, fmt.Errorf("secret not found: %s: %w", path, newNotFoundError(op, path))
+	}
+	if secret.WrapInfo == nil || secret.WrapInfo.Token == "" {
+		return nil, fmt.Errorf("response from %s was not wrapped", path)
+	}
+	return secret.WrapInfo, nil
+}
+
+// ReadWrapped читает path и возвращает wrapping-токен вместо данных. Каждый ответ — новый
+// токен (а для database/creds — новые креды), поэтому выполненный запрос не повторяется.
+func (c *Client) ReadWrapped(ctx context.Context, path string, ttl time.Duration) (*api.SecretWrapInfo, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при ReadWrapped", "error", err)
+	}
+
+	c.logger.Debug("ReadWrapped", "path", path, "ttl", ttl)
+	secret, err := c.retryUnprocessed(ctx, "ReadWrapped", path, func() (*api.Secret, error) {
+		return c.wrapped(ttl).Logical().ReadWithContext(ctx, path)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wrapped secret: %w", err)
+	}
+
+	return wrapInfo("ReadWrapped", path, secret)
+}
+
+// WriteWrapped выполняет запись (например, генерацию secret-id или динамических кредов)
+// и возвращает wrapping-токен вместо ответа. Выполненный запрос не повторяется.
+func (c *Client) WriteWrapped(ctx context.Context, path string, data map[string]interface{}, ttl time.Duration) (*api.SecretWrapInfo, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при WriteWrapped", "error", err)
+	}
+
+	c.logger.Debug("WriteWrapped", "path", path, "ttl", ttl)
+	secret, err := c.retryUnprocessed(ctx, "WriteWrapped", path, func() (*api.Secret, error) {
+		return c.wrapped(ttl).Logical().WriteWithContext(ctx, path, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write wrapped request: %w", err)
+	}
+
+	return wrapInfo("WriteWrapped", path, secret)
+}
+
+// KVReadWrapped — ReadWrapped для KV v2 ({kvMount}/data/{path}). Получатель после unwrap
+// видит ответ KV целиком: data.data и data.metadata.
+func (c *Client) KVReadWrapped(ctx context.Context, path string, ttl time.Duration) (*api.SecretWrapInfo, error) {
+	return c.ReadWrapped(ctx, fmt.Sprintf("%s/data/%s", c.config.KVMount, path), ttl)
+}
+
+// WrapData помещает произвольные данные в cubbyhole wrapping-токена (sys/wrapping/wrap).
+func (c *Client) WrapData(ctx context.Context, data map[string]interface{}, ttl time.Duration) (*api.SecretWrapInfo, error) {
+	return c.WriteWrapped(ctx, "sys/wrapping/wrap", data, ttl)
+}
+
+// Unwrap извлекает обёрнутый ответ. Запрос не повторяется: токен одноразовый, и после
+// сетевой ошибки повтор вернул бы «token is not valid» даже при успешном первом вызове.
+func (c *Client) Unwrap(ctx context.Context, token string) (*api.Secret, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при Unwrap", "error", err)
+	}
+
+	secret, err := c.client.Logical().UnwrapWithContext(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap token: %w", newError("Unwrap", "sys/wrapping/unwrap", err))
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("failed to unwrap token: %w", newNotFoundError("Unwrap", "sys/wrapping/unwrap"))
+	}
+
+	return secret, nil
+}
+
+// WrappingLookup проверяет токен без его использования. Использованный или истёкший токен
+// даёт ErrInvalidRequest (OpenBao отвечает 400).
+func (c *Client) WrappingLookup(ctx context.Context, token string) (*WrappingTokenInfo, error) {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при WrappingLookup", "error", err)
+	}
+
+	path := "sys/wrapping/lookup"
+	data := map[string]interface{}{"token": token}
+
+	secret, err := c.retry(ctx, "WrappingLookup", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup wrapping token: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no data returned from wrapping lookup")
+	}
+
+	info := &WrappingTokenInfo{
+		CreationTTL: time.Duration(intFromData(secret.Data["creation_ttl"])) * time.Second,
+	}
+	if creationPath, ok := secret.Data["creation_path"].(string); ok {
+		info.CreationPath = creationPath
+	}
+	if creationTime, ok := secret.Data["creation_time"].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, creationTime); err == nil {
+			info.CreationTime = t
+		}
+	}
+
+	return info, nil
+}
+
+// RevokeWrappingAccessor отзывает неиспользованный wrapping-токен по accessor
+// (auth/token/revoke-accessor), например при выдаче замены.
+func (c *Client) RevokeWrappingAccessor(ctx context.Context, accessor string) error {
+	if err := c.RefreshToken(ctx); err != nil {
+		c.logger.Warn("Не удалось обновить токен при RevokeWrappingAccessor", "error", err)
+	}
+
+	path := "auth/token/revoke-accessor"
+	data := map[string]interface{}{"accessor": accessor}
+
+	_, err := c.retry(ctx, "RevokeWrappingAccessor", path, func() (*api.Secret, error) {
+		return c.client.Logical().WriteWithContext(ctx, path, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke wrapping token: %w", err)
+	}
+
+	return nil
+}
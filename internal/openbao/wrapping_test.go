@@ -0,0 +1,80 @@
+// Тесты response wrapping.
+package openbao
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVReadWrapped(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/app", r.URL.Path)
+		assert.Equal(t, "120s", r.Header.Get("X-Vault-Wrap-TTL"))
+
+		writeJSON(t, w, map[string]interface{}{
+			"wrap_info": map[string]interface{}{
+				"token":         "s.wrapped",
+				"accessor":      "acc-1",
+				"ttl":           120,
+				"creation_time": "2026-01-02T03:04:05Z",
+				"creation_path": "secret/data/app",
+			},
+		})
+	})
+
+	info, err := client.KVReadWrapped(context.Background(), "app", 2*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "s.wrapped", info.Token)
+	assert.Equal(t, "acc-1", info.Accessor)
+	assert.Equal(t, 120, info.TTL)
+}
+
+func TestReadWrappedRejectsUnwrappedResponse(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{"data": map[string]interface{}{"key": "value"}})
+	})
+
+	_, err := client.ReadWrapped(context.Background(), "secret/data/app", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "was not wrapped")
+}
+
+func TestUnwrapIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Equal(t, "/v1/sys/wrapping/unwrap", r.URL.Path)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		writeJSON(t, w, map[string]interface{}{"errors": []string{"node is not active"}})
+	})
+	client.config.RetryWaitMin = time.Millisecond
+
+	_, err := client.Unwrap(context.Background(), "s.wrapped")
+	require.Error(t, err)
+	assert.True(t, IsUnavailable(err))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWrappingLookup(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/sys/wrapping/lookup", r.URL.Path)
+		writeJSON(t, w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"creation_path": "secret/data/app",
+				"creation_time": "2026-01-02T03:04:05Z",
+				"creation_ttl":  300,
+			},
+		})
+	})
+
+	info, err := client.WrappingLookup(context.Background(), "s.wrapped")
+	require.NoError(t, err)
+	assert.Equal(t, "secret/data/app", info.CreationPath)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 9, 5, 0, time.UTC), info.ExpiresAt())
+}
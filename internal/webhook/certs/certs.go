@@ -0,0 +1,594 @@
+// Package certs — сертификат admission webhook без cert-manager: оператор сам выпускает CA и
+// сертификат сервера, хранит их в Secret (общем для всех реплик), раскладывает в каталог
+// webhook-сервера и прописывает CA в caBundle конфигураций webhook и conversion webhook CRD.
+// Сертификат перевыпускается заранее, до истечения, и при смене имени Service. При замене CA
+// caBundle содержит прежний и новый CA, пока все реплики не перейдут на новый сертификат.
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;update
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations;mutatingwebhookconfigurations,verbs=get;update
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;update
+
+const (
+	// CACertKey — ключ сертификата CA в Secret; он же записывается в caBundle
+	CACertKey = "ca.crt"
+	// CAKeyKey — ключ закрытого ключа CA: сертификат сервера перевыпускается тем же CA
+	CAKeyKey = "ca.key"
+	// PreviousCACertKey — замещённый CA: остаётся в caBundle, пока хотя бы одна реплика отдаёт
+	// сертификат, подписанный им
+	PreviousCACertKey = "ca-previous.crt"
+
+	// ReplicaAnnotationPrefix — аннотация Secret на каждую реплику (по Options.Identity) с отпечатком
+	// CA, которым подписан сертификат в её CertDir
+	ReplicaAnnotationPrefix = "replicas.webhook.kubebao.io/"
+
+	// Срок действия CA
+	caValidity = 10 * 365 * 24 * time.Hour
+	// Срок действия сертификата сервера
+	certValidity = 365 * 24 * time.Hour
+	// Сертификат перевыпускается, если до истечения осталось меньше
+	renewBefore = 30 * 24 * time.Hour
+	// Интервал проверки сертификата и caBundle по умолчанию
+	defaultCheckInterval = time.Minute
+)
+
+// Options — где хранится сертификат и куда он прописывается.
+type Options struct {
+	// SecretName — Secret с ca.crt, ca.key, tls.crt и tls.key
+	SecretName string
+	// Namespace — namespace Secret и Service оператора
+	Namespace string
+	// ServiceName — Service webhook; имена сертификата — <service>.<namespace>.svc и производные
+	ServiceName string
+	// CertDir — каталог webhook-сервера, куда записываются tls.crt и tls.key
+	CertDir string
+	// ConfigurationName — имя ValidatingWebhookConfiguration и MutatingWebhookConfiguration
+	ConfigurationName string
+	// CRDNames — CRD, чей conversion webhook обращается к Service оператора
+	CRDNames []string
+	// CheckInterval — интервал проверки в Start; по умолчанию 1 минута
+	CheckInterval time.Duration
+	// Identity — имя пода реплики в Namespace; по умолчанию имя хоста
+	Identity string
+}
+
+// Bootstrapper выпускает, хранит и раскладывает сертификат webhook. Ensure вызывается до запуска
+// менеджера (webhook-серверу нужны файлы сертификата), Start — повторяет проверку периодически:
+// caBundle, перезаписанный helm upgrade, восстанавливается, а истекающий сертификат заменяется.
+type Bootstrapper struct {
+	// Client — запись Secret и конфигураций webhook
+	Client client.Client
+	// Reader — чтение в обход кэша: до запуска менеджера кэш не работает
+	Reader  client.Reader
+	Options Options
+	Log     logr.Logger
+
+	now func() time.Time
+}
+
+// NewBootstrapper создаёт Bootstrapper.
+func NewBootstrapper(c client.Client, reader client.Reader, opts Options, log logr.Logger) *Bootstrapper {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = defaultCheckInterval
+	}
+	if opts.Identity == "" {
+		opts.Identity, _ = os.Hostname()
+	}
+	return &Bootstrapper{Client: c, Reader: reader, Options: opts, Log: log, now: time.Now}
+}
+
+// DNSNames — имена, по которым API-сервер обращается к Service webhook.
+func (b *Bootstrapper) DNSNames() []string {
+	svc, ns := b.Options.ServiceName, b.Options.Namespace
+	return []string{
+		svc,
+		svc + "." + ns,
+		svc + "." + ns + ".svc",
+		svc + "." + ns + ".svc.cluster.local",
+	}
+}
+
+// Ensure — сертификат в Secret действителен, файлы в CertDir совпадают с ним, caBundle
+// конфигураций webhook и conversion webhook CRD — его CA (и прежний CA во время ротации).
+func (b *Bootstrapper) Ensure(ctx context.Context) error {
+	secret, err := b.ensureSecret(ctx)
+	if err != nil {
+		return err
+	}
+	// caBundle раньше файлов: API-сервер должен доверять новому CA до того, как реплика начнёт
+	// отдавать подписанный им сертификат
+	caBundle := caBundleOf(secret.Data)
+	if err := b.injectAll(ctx, caBundle); err != nil {
+		return err
+	}
+	if err := b.writeFiles(secret); err != nil {
+		return err
+	}
+
+	secret, err = b.recordReplica(ctx, secret)
+	if err != nil {
+		return err
+	}
+	if next := caBundleOf(secret.Data); !bytes.Equal(next, caBundle) {
+		return b.injectAll(ctx, next)
+	}
+	return nil
+}
+
+// injectAll прописывает caBundle в конфигурации webhook и conversion webhook CRD.
+func (b *Bootstrapper) injectAll(ctx context.Context, caBundle []byte) error {
+	if err := b.injectCABundle(ctx, caBundle); err != nil {
+		return err
+	}
+	return b.injectCRDCABundle(ctx, caBundle)
+}
+
+// caBundleOf — текущий CA и, во время ротации, прежний.
+func caBundleOf(data map[string][]byte) []byte {
+	if len(data[PreviousCACertKey]) == 0 {
+		return data[CACertKey]
+	}
+	caBundle := append([]byte{}, data[CACertKey]...)
+	return append(caBundle, data[PreviousCACertKey]...)
+}
+
+// Start проверяет сертификат каждые CheckInterval до отмены ctx. Ошибка проверки не
+// останавливает менеджер: webhook продолжает работать с текущим сертификатом.
+func (b *Bootstrapper) Start(ctx context.Context) error {
+	ticker := time.NewTicker(b.Options.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := b.Ensure(ctx); err != nil {
+				b.Log.Error(err, "Ошибка проверки сертификата webhook")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection — каждая реплика раскладывает сертификат в свой каталог.
+func (b *Bootstrapper) NeedLeaderElection() bool {
+	return false
+}
+
+// ensureSecret возвращает Secret с действительным сертификатом, при необходимости создавая или
+// обновляя его. Конфликт с другой репликой разрешается перечитыванием: её сертификат подходит.
+func (b *Bootstrapper) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	key := types.NamespacedName{Namespace: b.Options.Namespace, Name: b.Options.SecretName}
+	for attempt := 0; attempt < 3; attempt++ {
+		secret := &corev1.Secret{}
+		err := b.Reader.Get(ctx, key, secret)
+		switch {
+		case apierrors.IsNotFound(err):
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Type:       corev1.SecretTypeTLS,
+			}
+			if secret.Data, err = b.issue(nil); err != nil {
+				return nil, err
+			}
+			err = b.Client.Create(ctx, secret)
+			if apierrors.IsAlreadyExists(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to create secret %s: %w", key, err)
+			}
+			b.Log.Info("Сертификат webhook выпущен", "secret", key)
+			return secret, nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to get secret %s: %w", key, err)
+		}
+
+		reason := b.renewReason(secret.Data)
+		if reason == "" {
+			return secret, nil
+		}
+		data, err := b.issue(secret.Data)
+		if err != nil {
+			return nil, err
+		}
+		secret.Data = data
+		err = b.Client.Update(ctx, secret)
+		if apierrors.IsConflict(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update secret %s: %w", key, err)
+		}
+		b.Log.Info("Сертификат webhook перевыпущен", "secret", key, "reason", reason)
+		return secret, nil
+	}
+	return nil, fmt.Errorf("secret %s is being modified concurrently", key)
+}
+
+// renewReason — почему сертификат из data не подходит; "" — подходит.
+func (b *Bootstrapper) renewReason(data map[string][]byte) string {
+	caCert, _, err := parseKeyPair(data[CACertKey], data[CAKeyKey])
+	if err != nil {
+		return "invalid CA: " + err.Error()
+	}
+	cert, _, err := parseKeyPair(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return "invalid certificate: " + err.Error()
+	}
+	if err := cert.CheckSignatureFrom(caCert); err != nil {
+		return "certificate is not signed by the CA"
+	}
+	if b.now().Add(renewBefore).After(cert.NotAfter) {
+		return "certificate expires at " + cert.NotAfter.Format(time.RFC3339)
+	}
+	for _, name := range b.DNSNames() {
+		if err := cert.VerifyHostname(name); err != nil {
+			return "certificate is not valid for " + name
+		}
+	}
+	return ""
+}
+
+// issue — новый сертификат сервера. CA из old переиспользуется, если он переживёт новый
+// сертификат: caBundle тогда не меняется и реплики со старыми файлами продолжают работать.
+// Замещённый, но ещё действующий CA сохраняется в PreviousCACertKey.
+func (b *Bootstrapper) issue(old map[string][]byte) (map[string][]byte, error) {
+	now := b.now()
+	notAfter := now.Add(certValidity)
+
+	previousCA := old[PreviousCACertKey]
+	caCert, caKey, err := parseKeyPair(old[CACertKey], old[CAKeyKey])
+	if err != nil || caCert.NotAfter.Before(notAfter) {
+		previousCA = nil
+		if err == nil && now.Before(caCert.NotAfter) {
+			previousCA = old[CACertKey]
+		}
+		caCert, caKey, err = newCA(b.Options.ServiceName+"-ca", now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: newSerial(),
+		Subject:      pkix.Name{CommonName: b.DNSNames()[2]},
+		DNSNames:     b.DNSNames(),
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	caKeyPEM, err := encodeKey(caKey)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string][]byte{
+		CACertKey:               pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}),
+		CAKeyKey:                caKeyPEM,
+		corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		corev1.TLSPrivateKeyKey: keyPEM,
+	}
+	if len(previousCA) > 0 {
+		data[PreviousCACertKey] = previousCA
+	}
+	return data, nil
+}
+
+// recordReplica отмечает в Secret, каким CA подписан сертификат в CertDir этой реплики, и
+// завершает ротацию CA, когда все живые реплики перешли на новый. Отметки удалённых подов
+// снимаются. Конфликт с другой репликой разрешается перечитыванием Secret.
+func (b *Bootstrapper) recordReplica(ctx context.Context, secret *corev1.Secret) (*corev1.Secret, error) {
+	key := client.ObjectKeyFromObject(secret)
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			secret = &corev1.Secret{}
+			if err := b.Reader.Get(ctx, key, secret); err != nil {
+				return nil, fmt.Errorf("failed to get secret %s: %w", key, err)
+			}
+		}
+
+		ca := fingerprint(secret.Data[CACertKey])
+		changed := false
+		if b.Options.Identity != "" && secret.Annotations[ReplicaAnnotationPrefix+b.Options.Identity] != ca {
+			if secret.Annotations == nil {
+				secret.Annotations = make(map[string]string)
+			}
+			secret.Annotations[ReplicaAnnotationPrefix+b.Options.Identity] = ca
+			changed = true
+		}
+
+		completed := false
+		if len(secret.Data[PreviousCACertKey]) > 0 {
+			pending, pruned, err := b.pendingReplicas(ctx, secret, ca)
+			if err != nil {
+				return nil, err
+			}
+			changed = changed || pruned
+			if len(pending) == 0 || b.caExpired(secret.Data[PreviousCACertKey]) {
+				delete(secret.Data, PreviousCACertKey)
+				changed, completed = true, true
+			} else {
+				b.Log.V(1).Info("Прежний CA остаётся в caBundle", "secret", key, "replicas", pending)
+			}
+		}
+
+		if !changed {
+			return secret, nil
+		}
+		err := b.Client.Update(ctx, secret)
+		if apierrors.IsConflict(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update secret %s: %w", key, err)
+		}
+		if completed {
+			b.Log.Info("Ротация CA webhook завершена, прежний CA удалён из caBundle", "secret", key)
+		}
+		return secret, nil
+	}
+	return nil, fmt.Errorf("secret %s is being modified concurrently", key)
+}
+
+// pendingReplicas — реплики, чей сертификат подписан не CA с отпечатком ca. Отметки подов,
+// которых больше нет, удаляются из secret (pruned — что-то удалено).
+func (b *Bootstrapper) pendingReplicas(ctx context.Context, secret *corev1.Secret, ca string) (pending []string, pruned bool, err error) {
+	for annotation, value := range secret.Annotations {
+		name, ok := strings.CutPrefix(annotation, ReplicaAnnotationPrefix)
+		if !ok || value == ca {
+			continue
+		}
+		err := b.Reader.Get(ctx, types.NamespacedName{Namespace: b.Options.Namespace, Name: name}, &corev1.Pod{})
+		switch {
+		case apierrors.IsNotFound(err):
+			delete(secret.Annotations, annotation)
+			pruned = true
+		case err != nil:
+			return nil, false, fmt.Errorf("failed to get pod %s: %w", name, err)
+		default:
+			pending = append(pending, name)
+		}
+	}
+	return pending, pruned, nil
+}
+
+// caExpired — сертификат CA истёк или не разбирается: держать его в caBundle незачем.
+func (b *Bootstrapper) caExpired(certPEM []byte) bool {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	return err != nil || !b.now().Before(cert.NotAfter)
+}
+
+// fingerprint — короткий отпечаток PEM сертификата для аннотаций реплик.
+func fingerprint(certPEM []byte) string {
+	sum := sha256.Sum256(certPEM)
+	return hex.EncodeToString(sum[:16])
+}
+
+// writeFiles записывает tls.crt и tls.key в CertDir, если они отличаются. Webhook-сервер
+// controller-runtime следит за файлами и подхватывает новый сертификат без перезапуска.
+func (b *Bootstrapper) writeFiles(secret *corev1.Secret) error {
+	if err := os.MkdirAll(b.Options.CertDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", b.Options.CertDir, err)
+	}
+	// Ключ раньше сертификата: сервер перечитывает пару при изменении tls.crt
+	for _, name := range []string{corev1.TLSPrivateKeyKey, corev1.TLSCertKey} {
+		path := filepath.Join(b.Options.CertDir, name)
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, secret.Data[name]) {
+			continue
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, secret.Data[name], 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// injectCABundle прописывает CA в webhooks конфигураций, которые обращаются к Service оператора.
+// Отсутствующая конфигурация не ошибка: chart может не включать mutating webhooks.
+func (b *Bootstrapper) injectCABundle(ctx context.Context, caBundle []byte) error {
+	key := types.NamespacedName{Name: b.Options.ConfigurationName}
+
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := b.Reader.Get(ctx, key, validating); err == nil {
+		changed := false
+		for i := range validating.Webhooks {
+			changed = b.setCABundle(&validating.Webhooks[i].ClientConfig, caBundle) || changed
+		}
+		if changed {
+			if err := b.Client.Update(ctx, validating); err != nil {
+				return fmt.Errorf("failed to update ValidatingWebhookConfiguration %s: %w", key.Name, err)
+			}
+			b.Log.Info("caBundle обновлён", "validatingWebhookConfiguration", key.Name)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get ValidatingWebhookConfiguration %s: %w", key.Name, err)
+	}
+
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{}
+	if err := b.Reader.Get(ctx, key, mutating); err == nil {
+		changed := false
+		for i := range mutating.Webhooks {
+			changed = b.setCABundle(&mutating.Webhooks[i].ClientConfig, caBundle) || changed
+		}
+		if changed {
+			if err := b.Client.Update(ctx, mutating); err != nil {
+				return fmt.Errorf("failed to update MutatingWebhookConfiguration %s: %w", key.Name, err)
+			}
+			b.Log.Info("caBundle обновлён", "mutatingWebhookConfiguration", key.Name)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get MutatingWebhookConfiguration %s: %w", key.Name, err)
+	}
+	return nil
+}
+
+// crdGVK — CustomResourceDefinition читается как unstructured: типы apiextensions не нужны
+// оператору ради одного поля.
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// injectCRDCABundle прописывает CA в conversion webhook CRD из CRDNames, если он обращается к
+// Service оператора. CRD без conversion webhook (установленные из config/crd) не меняются.
+func (b *Bootstrapper) injectCRDCABundle(ctx context.Context, caBundle []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(caBundle)
+	for _, name := range b.Options.CRDNames {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		if err := b.Reader.Get(ctx, types.NamespacedName{Name: name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get CustomResourceDefinition %s: %w", name, err)
+		}
+
+		strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+		svcName, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service", "name")
+		svcNamespace, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service", "namespace")
+		if strategy != "Webhook" || svcName != b.Options.ServiceName || svcNamespace != b.Options.Namespace {
+			continue
+		}
+		current, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+		if current == encoded {
+			continue
+		}
+
+		if err := unstructured.SetNestedField(crd.Object, encoded, "spec", "conversion", "webhook", "clientConfig", "caBundle"); err != nil {
+			return fmt.Errorf("failed to set caBundle of CustomResourceDefinition %s: %w", name, err)
+		}
+		if err := b.Client.Update(ctx, crd); err != nil {
+			return fmt.Errorf("failed to update CustomResourceDefinition %s: %w", name, err)
+		}
+		b.Log.Info("caBundle обновлён", "customResourceDefinition", name)
+	}
+	return nil
+}
+
+// setCABundle — true, если caBundle webhook Service оператора изменён.
+func (b *Bootstrapper) setCABundle(cfg *admissionregistrationv1.WebhookClientConfig, caBundle []byte) bool {
+	svc := cfg.Service
+	if svc == nil || svc.Name != b.Options.ServiceName || svc.Namespace != b.Options.Namespace {
+		return false
+	}
+	if bytes.Equal(cfg.CABundle, caBundle) {
+		return false
+	}
+	cfg.CABundle = caBundle
+	return true
+}
+
+func newCA(commonName string, now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          newSerial(),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// parseKeyPair разбирает PEM сертификата и ECDSA-ключа и проверяет, что они составляют пару.
+func parseKeyPair(certPEM, keyPEM []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, errors.New("no certificate")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, errors.New("no private key")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		return nil, nil, errors.New("private key does not match the certificate")
+	}
+	return cert, key, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+func newSerial() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return big.NewInt(time.Now().UnixNano())
+	}
+	return serial
+}
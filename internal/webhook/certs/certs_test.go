@@ -0,0 +1,341 @@
+// Тесты выпуска сертификата webhook и caBundle.
+package certs
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestBootstrapper(t *testing.T, objs ...client.Object) (*Bootstrapper, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	b := NewBootstrapper(c, c, Options{
+		SecretName:        "kubebao-operator-webhook-tls",
+		Namespace:         "kubebao-system",
+		ServiceName:       "kubebao-operator-webhook",
+		CertDir:           t.TempDir(),
+		ConfigurationName: "kubebao-operator-webhook",
+	}, logr.Discard())
+	return b, c
+}
+
+func webhookConfigurations() (*admissionregistrationv1.ValidatingWebhookConfiguration, *admissionregistrationv1.MutatingWebhookConfiguration) {
+	service := func(name string) admissionregistrationv1.WebhookClientConfig {
+		return admissionregistrationv1.WebhookClientConfig{
+			Service: &admissionregistrationv1.ServiceReference{Name: name, Namespace: "kubebao-system"},
+		}
+	}
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubebao-operator-webhook"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "vbaosecret.kubebao.io", ClientConfig: service("kubebao-operator-webhook")},
+			{Name: "other.example.com", ClientConfig: service("other")},
+		},
+	}
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubebao-operator-webhook"},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: "mbaosecret.kubebao.io", ClientConfig: service("kubebao-operator-webhook")},
+		},
+	}
+	return validating, mutating
+}
+
+func parseCert(t *testing.T, data []byte) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestEnsureIssuesCertificate(t *testing.T) {
+	validating, mutating := webhookConfigurations()
+	b, c := newTestBootstrapper(t, validating, mutating)
+	ctx := context.Background()
+
+	require.NoError(t, b.Ensure(ctx))
+
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kubebao-system", Name: "kubebao-operator-webhook-tls"}, secret))
+	assert.Equal(t, corev1.SecretTypeTLS, secret.Type)
+
+	// Сертификат подписан CA и действителен для имени Service
+	roots := x509.NewCertPool()
+	roots.AddCert(parseCert(t, secret.Data[CACertKey]))
+	cert := parseCert(t, secret.Data[corev1.TLSCertKey])
+	_, err := cert.Verify(x509.VerifyOptions{
+		DNSName:   "kubebao-operator-webhook.kubebao-system.svc",
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	require.NoError(t, err)
+
+	// Файлы webhook-сервера совпадают с Secret
+	for _, name := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		data, err := os.ReadFile(filepath.Join(b.Options.CertDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, secret.Data[name], data)
+	}
+
+	// caBundle прописан только в webhooks Service оператора
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(validating), validating))
+	assert.Equal(t, secret.Data[CACertKey], validating.Webhooks[0].ClientConfig.CABundle)
+	assert.Empty(t, validating.Webhooks[1].ClientConfig.CABundle)
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(mutating), mutating))
+	assert.Equal(t, secret.Data[CACertKey], mutating.Webhooks[0].ClientConfig.CABundle)
+}
+
+func TestEnsureReusesValidCertificate(t *testing.T) {
+	b, c := newTestBootstrapper(t)
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "kubebao-system", Name: "kubebao-operator-webhook-tls"}
+
+	require.NoError(t, b.Ensure(ctx))
+	first := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, first))
+
+	// Вторая реплика получает тот же сертификат
+	require.NoError(t, b.Ensure(ctx))
+	second := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, second))
+	assert.Equal(t, first.Data, second.Data)
+}
+
+func TestEnsureRenewsCertificate(t *testing.T) {
+	b, c := newTestBootstrapper(t)
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "kubebao-system", Name: "kubebao-operator-webhook-tls"}
+
+	require.NoError(t, b.Ensure(ctx))
+	first := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, first))
+
+	// За 30 дней до истечения сертификат перевыпускается тем же CA
+	b.now = func() time.Time { return time.Now().Add(certValidity - renewBefore + time.Hour) }
+	require.NoError(t, b.Ensure(ctx))
+	renewed := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, renewed))
+	assert.NotEqual(t, first.Data[corev1.TLSCertKey], renewed.Data[corev1.TLSCertKey])
+	assert.Equal(t, first.Data[CACertKey], renewed.Data[CACertKey])
+
+	// Смена имени Service — новый сертификат с новыми именами
+	b.Options.ServiceName = "kubebao-webhook"
+	require.NoError(t, b.Ensure(ctx))
+	require.NoError(t, c.Get(ctx, key, renewed))
+	assert.Contains(t, parseCert(t, renewed.Data[corev1.TLSCertKey]).DNSNames, "kubebao-webhook.kubebao-system.svc")
+}
+
+func TestEnsureReplacesForeignSecret(t *testing.T) {
+	// Secret без ключа CA (например, выпущенный chart) заменяется
+	b, c := newTestBootstrapper(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kubebao-system", Name: "kubebao-operator-webhook-tls"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{CACertKey: []byte("x"), corev1.TLSCertKey: []byte("x"), corev1.TLSPrivateKeyKey: []byte("x")},
+	})
+	ctx := context.Background()
+
+	require.NoError(t, b.Ensure(ctx))
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kubebao-system", Name: "kubebao-operator-webhook-tls"}, secret))
+	assert.Empty(t, b.renewReason(secret.Data))
+}
+
+func conversionCRD(name, service string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"group": "kubebao.io",
+			"conversion": map[string]interface{}{
+				"strategy": "Webhook",
+				"webhook": map[string]interface{}{
+					"conversionReviewVersions": []interface{}{"v1"},
+					"clientConfig": map[string]interface{}{
+						"service": map[string]interface{}{"name": service, "namespace": "kubebao-system", "path": "/convert"},
+					},
+				},
+			},
+		},
+	}}
+	crd.SetGroupVersionKind(crdGVK)
+	return crd
+}
+
+func TestEnsureInjectsCRDCABundle(t *testing.T) {
+	b, c := newTestBootstrapper(t,
+		conversionCRD("baosecrets.kubebao.io", "kubebao-operator-webhook"),
+		conversionCRD("baopushsecrets.kubebao.io", "other"),
+	)
+	b.Options.CRDNames = []string{"baosecrets.kubebao.io", "baopushsecrets.kubebao.io", "missing.kubebao.io"}
+	ctx := context.Background()
+
+	require.NoError(t, b.Ensure(ctx))
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "kubebao-system", Name: "kubebao-operator-webhook-tls"}, secret))
+
+	// caBundle прописан только в CRD, чей conversion webhook обращается к Service оператора
+	caBundle := func(name string) string {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGVK)
+		require.NoError(t, c.Get(ctx, types.NamespacedName{Name: name}, crd))
+		value, _, err := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+		require.NoError(t, err)
+		return value
+	}
+	assert.Equal(t, base64.StdEncoding.EncodeToString(secret.Data[CACertKey]), caBundle("baosecrets.kubebao.io"))
+	assert.Empty(t, caBundle("baopushsecrets.kubebao.io"))
+}
+
+// replica — Bootstrapper второй реплики с общим Secret и своим каталогом сертификата.
+func replica(t *testing.T, b *Bootstrapper, c client.Client, identity string) *Bootstrapper {
+	t.Helper()
+	opts := b.Options
+	opts.CertDir = t.TempDir()
+	opts.Identity = identity
+	other := NewBootstrapper(c, c, opts, logr.Discard())
+	other.now = b.now
+	return other
+}
+
+// verifiesWith — сертификат из файлов реплики проверяется по caBundle.
+func verifiesWith(t *testing.T, b *Bootstrapper, caBundle []byte) bool {
+	t.Helper()
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caBundle))
+	certPEM, err := os.ReadFile(filepath.Join(b.Options.CertDir, corev1.TLSCertKey))
+	require.NoError(t, err)
+	_, err = parseCert(t, certPEM).Verify(x509.VerifyOptions{
+		DNSName:     "kubebao-operator-webhook.kubebao-system.svc",
+		Roots:       roots,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		CurrentTime: b.now(),
+	})
+	return err == nil
+}
+
+func TestEnsureRotatesCAWithOverlap(t *testing.T) {
+	validating, _ := webhookConfigurations()
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "kubebao-system", Name: name}}
+	}
+	b, c := newTestBootstrapper(t, validating, pod("operator-a"), pod("operator-b"))
+	b.Options.Identity = "operator-a"
+	other := replica(t, b, c, "operator-b")
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "kubebao-system", Name: "kubebao-operator-webhook-tls"}
+	caBundle := func() []byte {
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(validating), validating))
+		return validating.Webhooks[0].ClientConfig.CABundle
+	}
+
+	require.NoError(t, b.Ensure(ctx))
+	require.NoError(t, other.Ensure(ctx))
+	initial := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, initial))
+	oldCA := initial.Data[CACertKey]
+	assert.Equal(t, fingerprint(oldCA), initial.Annotations[ReplicaAnnotationPrefix+"operator-a"])
+	assert.Equal(t, fingerprint(oldCA), initial.Annotations[ReplicaAnnotationPrefix+"operator-b"])
+
+	// Последний перевыпуск сертификата, который прежний CA переживает
+	now := time.Now().Add(caValidity - certValidity - 48*time.Hour)
+	b.now = func() time.Time { return now }
+	other.now = b.now
+	require.NoError(t, b.Ensure(ctx))
+	require.NoError(t, other.Ensure(ctx))
+	renewed := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, renewed))
+	require.Equal(t, oldCA, renewed.Data[CACertKey])
+	require.NotContains(t, renewed.Data, PreviousCACertKey)
+
+	// Следующий перевыпуск: CA истекает раньше нового сертификата — выпускается новый CA,
+	// прежний остаётся в caBundle
+	now = now.Add(certValidity - renewBefore + time.Hour)
+	require.NoError(t, b.Ensure(ctx))
+	rotated := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, rotated))
+	require.NotEqual(t, oldCA, rotated.Data[CACertKey])
+	assert.Equal(t, oldCA, rotated.Data[PreviousCACertKey])
+	assert.Equal(t, append(append([]byte{}, rotated.Data[CACertKey]...), oldCA...), caBundle())
+
+	// Реплика со старыми файлами и реплика с новыми проверяются по одному caBundle
+	assert.True(t, verifiesWith(t, b, caBundle()))
+	assert.True(t, verifiesWith(t, other, caBundle()))
+	assert.False(t, verifiesWith(t, other, rotated.Data[CACertKey]), "replica b still serves the old certificate")
+
+	// Повторная проверка первой реплики не удаляет прежний CA: вторая ещё не перешла
+	require.NoError(t, b.Ensure(ctx))
+	require.NoError(t, c.Get(ctx, key, rotated))
+	assert.Equal(t, oldCA, rotated.Data[PreviousCACertKey])
+
+	// Вторая реплика записала новые файлы — ротация завершена
+	require.NoError(t, other.Ensure(ctx))
+	done := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, done))
+	assert.NotContains(t, done.Data, PreviousCACertKey)
+	assert.Equal(t, rotated.Data[CACertKey], done.Data[CACertKey])
+	assert.Equal(t, done.Data[CACertKey], caBundle())
+	assert.True(t, verifiesWith(t, other, caBundle()))
+}
+
+func TestEnsureCompletesRotationWithoutDeletedReplicas(t *testing.T) {
+	b, c := newTestBootstrapper(t, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "kubebao-system", Name: "operator-a"}})
+	b.Options.Identity = "operator-a"
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "kubebao-system", Name: "kubebao-operator-webhook-tls"}
+
+	// Реплика operator-gone отметила старый CA и была удалена
+	require.NoError(t, replica(t, b, c, "operator-gone").Ensure(ctx))
+	later := time.Now().Add(caValidity - certValidity + time.Hour)
+	b.now = func() time.Time { return later }
+	require.NoError(t, b.Ensure(ctx))
+
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, key, secret))
+	assert.NotContains(t, secret.Data, PreviousCACertKey)
+	assert.NotContains(t, secret.Annotations, ReplicaAnnotationPrefix+"operator-gone")
+	assert.Equal(t, fingerprint(secret.Data[CACertKey]), secret.Annotations[ReplicaAnnotationPrefix+"operator-a"])
+}
+
+func TestIssueKeepsPreviousCA(t *testing.T) {
+	b, _ := newTestBootstrapper(t)
+	data, err := b.issue(nil)
+	require.NoError(t, err)
+	assert.NotContains(t, data, PreviousCACertKey)
+
+	// Перевыпуск тем же CA во время ротации сохраняет прежний CA
+	data[PreviousCACertKey] = []byte("previous")
+	renewed, err := b.issue(data)
+	require.NoError(t, err)
+	assert.Equal(t, data[CACertKey], renewed[CACertKey])
+	assert.Equal(t, []byte("previous"), renewed[PreviousCACertKey])
+
+	// Истёкший CA в caBundle не переносится
+	b.now = func() time.Time { return time.Now().Add(caValidity + time.Hour) }
+	replaced, err := b.issue(renewed)
+	require.NoError(t, err)
+	assert.NotEqual(t, renewed[CACertKey], replaced[CACertKey])
+	assert.NotContains(t, replaced, PreviousCACertKey)
+	assert.True(t, b.caExpired(renewed[CACertKey]))
+}
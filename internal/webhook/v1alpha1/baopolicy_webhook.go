@@ -0,0 +1,178 @@
+// Admission webhooks BaoPolicy и ClusterBaoPolicy — заполняют значения по умолчанию и отклоняют
+// политику с правилами вне грамматики ACL OpenBao, политику, имя которой в OpenBao уже занято
+// другим объектом (иначе два объекта перезаписывали бы одну политику), и BaoPolicy, нарушающий
+// BaoPolicyConstraint своего namespace.
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/controller"
+)
+
+// +kubebuilder:webhook:path=/validate-kubebao-io-v1alpha1-baopolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubebao.io,resources=baopolicies,verbs=create;update,versions=v1alpha1,name=vbaopolicy.kubebao.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-kubebao-io-v1alpha1-clusterbaopolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubebao.io,resources=clusterbaopolicies,verbs=create;update,versions=v1alpha1,name=vclusterbaopolicy.kubebao.io,admissionReviewVersions=v1
+
+// +kubebuilder:webhook:path=/mutate-kubebao-io-v1alpha1-baopolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=kubebao.io,resources=baopolicies,verbs=create;update,versions=v1alpha1,name=mbaopolicy.kubebao.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/mutate-kubebao-io-v1alpha1-clusterbaopolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=kubebao.io,resources=clusterbaopolicies,verbs=create;update,versions=v1alpha1,name=mclusterbaopolicy.kubebao.io,admissionReviewVersions=v1
+
+// BaoPolicyValidator — admission.CustomValidator для BaoPolicy и ClusterBaoPolicy.
+type BaoPolicyValidator struct {
+	// Client — чтение BaoPolicy и ClusterBaoPolicy всех namespace
+	Client client.Reader
+	// LegacyPolicyNames — имена BaoPolicy без префикса namespace, как у контроллера
+	LegacyPolicyNames bool
+}
+
+var _ admission.CustomValidator = &BaoPolicyValidator{}
+
+// BaoPolicyDefaulter — admission.CustomDefaulter для BaoPolicy и ClusterBaoPolicy.
+type BaoPolicyDefaulter struct{}
+
+var _ admission.CustomDefaulter = &BaoPolicyDefaulter{}
+
+// SetupBaoPolicyWebhookWithManager регистрирует mutating и validating webhooks BaoPolicy и
+// ClusterBaoPolicy.
+func SetupBaoPolicyWebhookWithManager(mgr ctrl.Manager, legacyPolicyNames bool) error {
+	validator := &BaoPolicyValidator{Client: mgr.GetClient(), LegacyPolicyNames: legacyPolicyNames}
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&kubebaoiov1alpha1.BaoPolicy{}).
+		WithDefaulter(&BaoPolicyDefaulter{}).
+		WithValidator(validator).
+		Complete(); err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kubebaoiov1alpha1.ClusterBaoPolicy{}).
+		WithDefaulter(&BaoPolicyDefaulter{}).
+		WithValidator(validator).
+		Complete()
+}
+
+// Default — значения по умолчанию CRD, включая approvals факторов control group.
+func (d *BaoPolicyDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	policy, ok := obj.(controller.PolicyObject)
+	if !ok {
+		return fmt.Errorf("expected a BaoPolicy or ClusterBaoPolicy but got %T", obj)
+	}
+	controller.DefaultPolicySpec(policy.GetPolicySpec())
+	return nil
+}
+
+// ValidateCreate — проверка новой политики.
+func (v *BaoPolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validatePolicy(ctx, obj)
+}
+
+// ValidateUpdate — проверка изменённой политики: смена policyName тоже не должна занять чужое имя.
+// Удаление и обновления без изменения spec (финализатор, метки) не проверяются.
+func (v *BaoPolicyValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	policy, ok := newObj.(controller.PolicyObject)
+	if !ok {
+		return nil, fmt.Errorf("expected a BaoPolicy or ClusterBaoPolicy but got %T", newObj)
+	}
+	oldPolicy, ok := oldObj.(controller.PolicyObject)
+	if !ok {
+		return nil, fmt.Errorf("expected a BaoPolicy or ClusterBaoPolicy but got %T", oldObj)
+	}
+	if skipUpdateValidation(policy, oldPolicy.GetPolicySpec(), policy.GetPolicySpec()) {
+		return nil, nil
+	}
+	return v.validatePolicy(ctx, newObj)
+}
+
+// ValidateDelete — удаление не ограничивается.
+func (v *BaoPolicyValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validatePolicy отклоняет политику с неверными правилами, занятым именем или правилами вне
+// BaoPolicyConstraint.
+func (v *BaoPolicyValidator) validatePolicy(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	policy, ok := obj.(controller.PolicyObject)
+	if !ok {
+		return nil, fmt.Errorf("expected a BaoPolicy or ClusterBaoPolicy but got %T", obj)
+	}
+
+	// Грамматика ACL (glob-пути, шаблоны identity, TTL, control groups), интервал и deletionPolicy
+	errs := controller.ValidatePolicySpec(policy.GetPolicySpec())
+
+	policyName := controller.PolicyName(policy, v.LegacyPolicyNames)
+	claimedBy, err := v.policyNameClaim(ctx, policy, policyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check policy name %s: %w", policyName, err)
+	}
+	if claimedBy != "" {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "policyName"), policyName,
+			fmt.Sprintf("policy name is already claimed by %s", claimedBy)))
+	}
+
+	// Пути, capabilities и TTL обёртки BaoPolicy ограничены BaoPolicyConstraint его namespace
+	if baoPolicy, ok := policy.(*kubebaoiov1alpha1.BaoPolicy); ok {
+		violations, err := controller.PolicyConstraintViolations(ctx, v.Client, baoPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check BaoPolicyConstraints: %w", err)
+		}
+		errs = append(errs, violations...)
+	}
+
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	return nil, apierrors.NewInvalid(kubebaoiov1alpha1.GroupVersion.WithKind(policyKind(policy)).GroupKind(), policy.GetName(), errs)
+}
+
+// policyNameClaim — объект, кроме policy, которому принадлежит имя policyName: по spec или по
+// status.appliedPolicyName (политика ещё записана в OpenBao). "" — имя свободно.
+func (v *BaoPolicyValidator) policyNameClaim(ctx context.Context, policy controller.PolicyObject, policyName string) (string, error) {
+	var candidates []controller.PolicyObject
+
+	baoPolicies := &kubebaoiov1alpha1.BaoPolicyList{}
+	if err := v.Client.List(ctx, baoPolicies); err != nil {
+		return "", err
+	}
+	for i := range baoPolicies.Items {
+		candidates = append(candidates, &baoPolicies.Items[i])
+	}
+
+	clusterPolicies := &kubebaoiov1alpha1.ClusterBaoPolicyList{}
+	if err := v.Client.List(ctx, clusterPolicies); err != nil {
+		return "", err
+	}
+	for i := range clusterPolicies.Items {
+		candidates = append(candidates, &clusterPolicies.Items[i])
+	}
+
+	for _, other := range candidates {
+		if policyKind(other) == policyKind(policy) &&
+			other.GetNamespace() == policy.GetNamespace() && other.GetName() == policy.GetName() {
+			continue
+		}
+		if controller.PolicyName(other, v.LegacyPolicyNames) == policyName ||
+			other.GetPolicyStatus().AppliedPolicyName == policyName {
+			name := other.GetName()
+			if other.GetNamespace() != "" {
+				name = other.GetNamespace() + "/" + name
+			}
+			return policyKind(other) + " " + name, nil
+		}
+	}
+	return "", nil
+}
+
+// policyKind — вид объекта политики; TypeMeta у объектов из List не заполнен.
+func policyKind(policy controller.PolicyObject) string {
+	if _, ok := policy.(*kubebaoiov1alpha1.ClusterBaoPolicy); ok {
+		return "ClusterBaoPolicy"
+	}
+	return "BaoPolicy"
+}
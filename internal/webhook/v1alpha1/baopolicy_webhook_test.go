@@ -0,0 +1,85 @@
+// Тесты webhook BaoPolicy: ограничения BaoPolicyConstraint отклоняются при apply так же, как
+// перед записью политики контроллером.
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestBaoPolicyValidatorEnforcesConstraints(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, kubebaoiov1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	tenant := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name: "team-a", Labels: map[string]string{"kubebao.io/tenant": "true"},
+	}}
+	platform := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "platform"}}
+	constraint := &kubebaoiov1alpha1.BaoPolicyConstraint{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants"},
+		Spec: kubebaoiov1alpha1.BaoPolicyConstraintSpec{
+			NamespaceSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"kubebao.io/tenant": "true"}},
+			AllowedPaths:          []string{"secret/data/{{namespace}}/*"},
+			ForbiddenCapabilities: []kubebaoiov1alpha1.Capability{kubebaoiov1alpha1.CapabilitySudo},
+		},
+	}
+	v := &BaoPolicyValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenant, platform, constraint).Build()}
+	ctx := context.Background()
+
+	policy := func(namespace, path string, capabilities ...kubebaoiov1alpha1.Capability) *kubebaoiov1alpha1.BaoPolicy {
+		return &kubebaoiov1alpha1.BaoPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "app"},
+			Spec: kubebaoiov1alpha1.BaoPolicySpec{Rules: []kubebaoiov1alpha1.PolicyRule{
+				{Path: path, Capabilities: capabilities},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy *kubebaoiov1alpha1.BaoPolicy
+		fields []string
+	}{
+		{name: "allowed path", policy: policy("team-a", "secret/data/team-a/*", kubebaoiov1alpha1.CapabilityRead)},
+		{name: "path of another namespace", policy: policy("team-a", "secret/data/team-b/*", kubebaoiov1alpha1.CapabilityRead),
+			fields: []string{"spec.rules[0].path"}},
+		{name: "forbidden capability", policy: policy("team-a", "secret/data/team-a/*", kubebaoiov1alpha1.CapabilitySudo),
+			fields: []string{"spec.rules[0].capabilities"}},
+		{name: "namespace not selected", policy: policy("platform", "secret/data/team-b/*", kubebaoiov1alpha1.CapabilitySudo)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.ValidateCreate(ctx, tt.policy)
+			if len(tt.fields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), "expected Invalid, got %v", err)
+			var fields []string
+			for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+
+	// ClusterBaoPolicy ограничениям не подлежит
+	clusterPolicy := &kubebaoiov1alpha1.ClusterBaoPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "platform"},
+		Spec:       policy("", "sys/*", kubebaoiov1alpha1.CapabilitySudo).Spec,
+	}
+	_, err := v.ValidateCreate(ctx, clusterPolicy)
+	assert.NoError(t, err)
+}
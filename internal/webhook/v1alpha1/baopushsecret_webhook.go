@@ -0,0 +1,16 @@
+// Conversion webhook BaoPushSecret: объекты v1beta1 конвертируются в v1alpha1 (версию хранения)
+// и обратно. Admission webhooks у BaoPushSecret нет.
+package v1alpha1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+// SetupBaoPushSecretWebhookWithManager регистрирует conversion webhook BaoPushSecret.
+func SetupBaoPushSecretWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kubebaoiov1alpha1.BaoPushSecret{}).
+		Complete()
+}
@@ -0,0 +1,153 @@
+// Admission webhooks BaoSecret — заполняют значения по умолчанию и отклоняют недопустимые
+// интервалы, тип Secret, шаблоны и сочетания creationPolicy, target и источников до того, как
+// объект попадёт в reconcile.
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+	"github.com/kubebao/kubebao/internal/controller"
+)
+
+// +kubebuilder:webhook:path=/validate-kubebao-io-v1alpha1-baosecret,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubebao.io,resources=baosecrets,verbs=create;update,versions=v1alpha1,name=vbaosecret.kubebao.io,admissionReviewVersions=v1
+
+// +kubebuilder:webhook:path=/mutate-kubebao-io-v1alpha1-baosecret,mutating=true,failurePolicy=fail,sideEffects=None,groups=kubebao.io,resources=baosecrets,verbs=create;update,versions=v1alpha1,name=mbaosecret.kubebao.io,admissionReviewVersions=v1
+
+// BaoSecretValidator — admission.CustomValidator для BaoSecret.
+type BaoSecretValidator struct {
+	// AllowedAddresses — адреса, допустимые в openbaoRef.address
+	AllowedAddresses []string
+}
+
+var _ admission.CustomValidator = &BaoSecretValidator{}
+
+// BaoSecretDefaulter — admission.CustomDefaulter для BaoSecret.
+type BaoSecretDefaulter struct{}
+
+var _ admission.CustomDefaulter = &BaoSecretDefaulter{}
+
+// SetupBaoSecretWebhookWithManager регистрирует mutating и validating webhooks BaoSecret;
+// openbaoRef.address вне allowedAddresses отклоняется.
+func SetupBaoSecretWebhookWithManager(mgr ctrl.Manager, allowedAddresses []string) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kubebaoiov1alpha1.BaoSecret{}).
+		WithDefaulter(&BaoSecretDefaulter{}).
+		WithValidator(&BaoSecretValidator{AllowedAddresses: allowedAddresses}).
+		Complete()
+}
+
+// Default — значения по умолчанию CRD для пустых полей, в том числе вложенных объектов
+// (wrapping, rollout, openbaoRef), которые API-сервер не заполняет.
+func (d *BaoSecretDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	baoSecret, ok := obj.(*kubebaoiov1alpha1.BaoSecret)
+	if !ok {
+		return fmt.Errorf("expected a BaoSecret but got %T", obj)
+	}
+	controller.DefaultBaoSecret(&baoSecret.Spec)
+	return nil
+}
+
+// ValidateCreate — проверка нового BaoSecret.
+func (v *BaoSecretValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	baoSecret, ok := obj.(*kubebaoiov1alpha1.BaoSecret)
+	if !ok {
+		return nil, fmt.Errorf("expected a BaoSecret but got %T", obj)
+	}
+	return validateBaoSecret(baoSecret, v.AllowedAddresses)
+}
+
+// ValidateUpdate — проверка изменённого BaoSecret; правила те же, что при создании. Удаление и
+// обновления без изменения spec (финализатор, метки) не проверяются.
+func (v *BaoSecretValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	baoSecret, ok := newObj.(*kubebaoiov1alpha1.BaoSecret)
+	if !ok {
+		return nil, fmt.Errorf("expected a BaoSecret but got %T", newObj)
+	}
+	oldBaoSecret, ok := oldObj.(*kubebaoiov1alpha1.BaoSecret)
+	if !ok {
+		return nil, fmt.Errorf("expected a BaoSecret but got %T", oldObj)
+	}
+	if skipUpdateValidation(baoSecret, oldBaoSecret.Spec, baoSecret.Spec) {
+		return nil, nil
+	}
+	return validateBaoSecret(baoSecret, v.AllowedAddresses)
+}
+
+// ValidateDelete — удаление не ограничивается.
+func (v *BaoSecretValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateBaoSecret — запрещённые сочетания дают ошибку, бесполезные — предупреждение.
+func validateBaoSecret(baoSecret *kubebaoiov1alpha1.BaoSecret, allowedAddresses []string) (admission.Warnings, error) {
+	var (
+		warnings admission.Warnings
+		errs     field.ErrorList
+	)
+	specPath := field.NewPath("spec")
+	targetPath := specPath.Child("target")
+	target := baoSecret.Spec.Target
+
+	// Источники, интервалы, тип Secret и шаблоны — те же проверки, что в reconcile
+	errs = append(errs, controller.ValidateBaoSecret(baoSecret.Namespace, &baoSecret.Spec)...)
+	errs = append(errs, controller.ValidateOpenBaoAddress(specPath.Child("openbaoRef"), baoSecret.Spec.OpenBaoRef, allowedAddresses)...)
+	if d, err := time.ParseDuration(baoSecret.Spec.RefreshInterval); err == nil && d > 0 && d < time.Minute {
+		warnings = append(warnings, fmt.Sprintf("refreshInterval %s is below the minimum, the secret is refreshed every 1m",
+			baoSecret.Spec.RefreshInterval))
+	}
+
+	if rollout := baoSecret.Spec.Rollout; rollout != nil && rollout.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(rollout.Selector); err != nil {
+			errs = append(errs, field.Invalid(specPath.Child("rollout", "selector"), rollout.Selector, err.Error()))
+		}
+	}
+
+	switch target.CreationPolicy {
+	case "", kubebaoiov1alpha1.CreationPolicyOwner:
+		if target.Namespace != "" && target.Namespace != baoSecret.Namespace {
+			warnings = append(warnings, fmt.Sprintf(
+				"target namespace %s differs from the BaoSecret namespace: no owner reference is set, the Secret is deleted by the kubebao finalizer",
+				target.Namespace))
+		}
+	case kubebaoiov1alpha1.CreationPolicyMerge:
+		// Тип существующего Secret неизменяем, Merge его не трогает
+		if target.Type != "" && target.Type != string(corev1.SecretTypeOpaque) {
+			errs = append(errs, field.Forbidden(targetPath.Child("type"),
+				"type cannot be set with creationPolicy: Merge, the existing Secret keeps its type"))
+		}
+	case kubebaoiov1alpha1.CreationPolicyNone:
+		if len(target.Labels) > 0 || len(target.Annotations) > 0 {
+			warnings = append(warnings, "target.labels and target.annotations are ignored with creationPolicy: None")
+		}
+		if baoSecret.Spec.Template != nil {
+			warnings = append(warnings, "template is rendered but not written with creationPolicy: None")
+		}
+		if baoSecret.Spec.Rollout != nil {
+			warnings = append(warnings, "rollout is ignored with creationPolicy: None")
+		}
+	case kubebaoiov1alpha1.CreationPolicyOrphan:
+	default:
+		errs = append(errs, field.NotSupported(targetPath.Child("creationPolicy"), target.CreationPolicy, []string{
+			kubebaoiov1alpha1.CreationPolicyOwner,
+			kubebaoiov1alpha1.CreationPolicyOrphan,
+			kubebaoiov1alpha1.CreationPolicyMerge,
+			kubebaoiov1alpha1.CreationPolicyNone,
+		}))
+	}
+
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(kubebaoiov1alpha1.GroupVersion.WithKind("BaoSecret").GroupKind(), baoSecret.Name, errs)
+}
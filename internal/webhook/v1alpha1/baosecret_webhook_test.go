@@ -0,0 +1,50 @@
+// Тесты webhook BaoSecret: адрес openbaoRef ограничен списком оператора.
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestBaoSecretValidateOpenBaoAddress(t *testing.T) {
+	v := &BaoSecretValidator{AllowedAddresses: []string{"https://openbao.kubebao-system:8200", "https://openbao.prod.example.com:8200"}}
+
+	tests := []struct {
+		name    string
+		ref     *kubebaoiov1alpha1.OpenBaoReference
+		wantErr bool
+	}{
+		{name: "no openbaoRef"},
+		{name: "operator address", ref: &kubebaoiov1alpha1.OpenBaoReference{Namespace: "prod"}},
+		{name: "allowed address", ref: &kubebaoiov1alpha1.OpenBaoReference{Address: "https://openbao.prod.example.com:8200"}},
+		{name: "allowed address with trailing slash", ref: &kubebaoiov1alpha1.OpenBaoReference{Address: "HTTPS://openbao.prod.example.com:8200/"}},
+		{name: "foreign address", ref: &kubebaoiov1alpha1.OpenBaoReference{Address: "https://attacker.example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baoSecret := &kubebaoiov1alpha1.BaoSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"},
+				Spec: kubebaoiov1alpha1.BaoSecretSpec{
+					SecretPath: "apps/db",
+					OpenBaoRef: tt.ref,
+					RoleName:   "apps",
+					Target:     kubebaoiov1alpha1.SecretTarget{Name: "db"},
+				},
+			}
+			_, err := v.ValidateCreate(context.Background(), baoSecret)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "spec.openbaoRef.address")
+		})
+	}
+}
@@ -0,0 +1,18 @@
+// Общие правила проверки UPDATE для webhooks kubebao.
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// skipUpdateValidation — UPDATE не проверяется, если объект удаляется или spec не изменился.
+// Иначе объект, созданный до появления проверки (или ставший недопустимым из-за нового
+// BaoPolicyConstraint), нельзя было бы даже снять с финализатора: он навсегда остался бы в
+// Terminating, а обновления только метаданных и status отклонялись бы.
+func skipUpdateValidation(newObj metav1.Object, oldSpec, newSpec interface{}) bool {
+	if newObj.GetDeletionTimestamp() != nil {
+		return true
+	}
+	return equality.Semantic.DeepEqual(oldSpec, newSpec)
+}
@@ -0,0 +1,84 @@
+// Тесты проверки UPDATE: удаление и обновления без изменения spec не отклоняются.
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubebaoiov1alpha1 "github.com/kubebao/kubebao/api/v1alpha1"
+)
+
+func TestBaoSecretValidateUpdate(t *testing.T) {
+	// Объект, созданный до появления webhook: такой refreshInterval теперь отклоняется
+	old := &kubebaoiov1alpha1.BaoSecret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps", Finalizers: []string{"kubebao.io/finalizer"}},
+		Spec: kubebaoiov1alpha1.BaoSecretSpec{
+			SecretPath:      "apps/db",
+			RefreshInterval: "1d",
+			Target:          kubebaoiov1alpha1.SecretTarget{Name: "db"},
+		},
+	}
+	v := &BaoSecretValidator{}
+	ctx := context.Background()
+
+	_, err := v.ValidateCreate(ctx, old)
+	require.Error(t, err)
+
+	// Снятие финализатора при удалении
+	deleting := old.DeepCopy()
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	deleting.Finalizers = nil
+	_, err = v.ValidateUpdate(ctx, old, deleting)
+	assert.NoError(t, err)
+
+	// Изменение только метаданных
+	labeled := old.DeepCopy()
+	labeled.Labels = map[string]string{"team": "a"}
+	_, err = v.ValidateUpdate(ctx, old, labeled)
+	assert.NoError(t, err)
+
+	// Изменение spec проверяется
+	changed := old.DeepCopy()
+	changed.Spec.SecretKey = "password"
+	_, err = v.ValidateUpdate(ctx, old, changed)
+	assert.Error(t, err)
+}
+
+func TestBaoPolicyValidateUpdate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, kubebaoiov1alpha1.AddToScheme(scheme))
+
+	// Политика без capabilities: нарушение, появившееся до webhook
+	old := &kubebaoiov1alpha1.BaoPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "apps", Finalizers: []string{"kubebao.io/finalizer"}},
+		Spec: kubebaoiov1alpha1.BaoPolicySpec{
+			Rules: []kubebaoiov1alpha1.PolicyRule{{Path: "secret/data/apps/*"}},
+		},
+	}
+	v := &BaoPolicyValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(old.DeepCopy()).Build()}
+	ctx := context.Background()
+
+	deleting := old.DeepCopy()
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	deleting.Finalizers = nil
+	_, err := v.ValidateUpdate(ctx, old, deleting)
+	assert.NoError(t, err)
+
+	labeled := old.DeepCopy()
+	labeled.Labels = map[string]string{"team": "a"}
+	_, err = v.ValidateUpdate(ctx, old, labeled)
+	assert.NoError(t, err)
+
+	changed := old.DeepCopy()
+	changed.Spec.Rules[0].Path = "secret/data/apps/db"
+	_, err = v.ValidateUpdate(ctx, old, changed)
+	assert.Error(t, err)
+}